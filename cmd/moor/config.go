@@ -0,0 +1,460 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+
+	"github.com/walles/moor/internal"
+)
+
+// One "name = value" line from the config file
+type configOption struct {
+	name  string
+	value string
+	line  int
+}
+
+// Options that only apply to some files, either by glob or by language
+type configOverride struct {
+	pattern string
+	options []configOption
+}
+
+// The contents of a config file. All options have the same names as the
+// command line flags, and are turned into command line flags before use.
+//
+// Example config file:
+//
+//	wrap = true
+//	style = "solarized dark"
+//
+//	[keys]
+//	ctrl-f = "page-down"
+//
+//	[files."*.jsonl"]
+//	lang = "json"
+//
+//	[languages.markdown]
+//	wrap = true
+type config struct {
+	path string // Empty if we have no config file
+
+	options   []configOption
+	keys      []configOption // Key names mapped to action names
+	files     []configOverride
+	languages []configOverride
+}
+
+// Options that make no sense in the config file
+var notConfigurable = map[string]bool{
+	"config":       true,
+	"print-config": true,
+	"version":      true,
+}
+
+func defaultConfigPath() (string, error) {
+	configDir, err := internal.ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configDir, "config.toml"), nil
+}
+
+// Load the config file. If path is empty, the default config file is loaded if
+// it exists.
+func loadConfig(path string) (*config, error) {
+	if path == "" {
+		defaultPath, err := defaultConfigPath()
+		if err != nil {
+			// No home directory, no config
+			return &config{}, nil //nolint:nilerr
+		}
+
+		_, err = os.Stat(defaultPath)
+		if errors.Is(err, os.ErrNotExist) {
+			return &config{}, nil
+		}
+
+		path = defaultPath
+	}
+
+	configFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer configFile.Close()
+
+	return parseConfig(path, configFile)
+}
+
+// Parses the subset of TOML we need: tables, plus string, boolean and integer
+// values.
+func parseConfig(path string, contents io.Reader) (*config, error) {
+	result := config{path: path}
+
+	// Where "name = value" lines currently end up
+	options := &result.options
+
+	scanner := bufio.NewScanner(contents)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := configLine{text: scanner.Text()}
+
+		line.skipSpace()
+		if line.atEnd() {
+			continue
+		}
+
+		if line.peek() == '[' {
+			header, err := line.parseHeader()
+			if err != nil {
+				return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+			}
+
+			switch {
+			case len(header) == 1 && header[0] == "keys":
+				options = &result.keys
+			case len(header) == 2 && header[0] == "files":
+				result.files = append(result.files, configOverride{pattern: header[1]})
+				options = &result.files[len(result.files)-1].options
+			case len(header) == 2 && header[0] == "languages":
+				result.languages = append(result.languages, configOverride{pattern: header[1]})
+				options = &result.languages[len(result.languages)-1].options
+			default:
+				return nil, fmt.Errorf("%s:%d: Unknown section [%s], valid sections are [keys], [files.\"<glob>\"] and [languages.<name>]",
+					path, lineNumber, strings.Join(header, "."))
+			}
+			continue
+		}
+
+		option, err := line.parseOption()
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, lineNumber, err)
+		}
+		option.line = lineNumber
+		*options = append(*options, option)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Turn the config file options into command line flags. Overrides matching
+// filename and language come after the general options, so that they win.
+//
+// filename is empty when reading from stdin, and language is nil if unknown.
+func (c *config) args(flagSet *flag.FlagSet, filename string, language chroma.Lexer) ([]string, error) {
+	err := c.validate(flagSet)
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	addArgs := func(options []configOption) {
+		for _, option := range options {
+			result = append(result, "--"+option.name+"="+option.value)
+		}
+	}
+
+	addArgs(c.options)
+
+	for _, override := range c.languages {
+		if languageMatches(override.pattern, language) {
+			addArgs(override.options)
+		}
+	}
+
+	for _, override := range c.files {
+		if globMatches(override.pattern, filename) {
+			addArgs(override.options)
+		}
+	}
+
+	return result, nil
+}
+
+// Check all option names, also in overrides that don't apply right now
+func (c *config) validate(flagSet *flag.FlagSet) error {
+	allOptions := append([]configOption{}, c.options...)
+	for _, override := range c.languages {
+		allOptions = append(allOptions, override.options...)
+	}
+	for _, override := range c.files {
+		allOptions = append(allOptions, override.options...)
+	}
+
+	for _, option := range allOptions {
+		if notConfigurable[option.name] || flagSet.Lookup(option.name) == nil {
+			return fmt.Errorf("%s:%d: Unknown option <%s>", c.path, option.line, option.name)
+		}
+	}
+
+	return nil
+}
+
+// Apply the [keys] section to a keymap
+func (c *config) bindKeys(keymap *internal.Keymap) error {
+	for _, binding := range c.keys {
+		err := keymap.Bind(binding.name, binding.value)
+		if err != nil {
+			return fmt.Errorf("%s:%d: %w", c.path, binding.line, err)
+		}
+	}
+	return nil
+}
+
+// Globs without a slash are matched against the file name only, so that
+// "*.md" matches "docs/README.md".
+func globMatches(glob string, filename string) bool {
+	if filename == "" {
+		return false
+	}
+
+	if !strings.Contains(glob, "/") {
+		filename = filepath.Base(filename)
+	}
+
+	matches, err := filepath.Match(glob, filename)
+	return err == nil && matches
+}
+
+func languageMatches(name string, language chroma.Lexer) bool {
+	if language == nil {
+		return false
+	}
+
+	lexerConfig := language.Config()
+	if strings.EqualFold(name, lexerConfig.Name) {
+		return true
+	}
+	for _, alias := range lexerConfig.Aliases {
+		if strings.EqualFold(name, alias) {
+			return true
+		}
+	}
+	return false
+}
+
+// Print the options in effect as a config file
+func printConfig(output io.Writer, flagSet *flag.FlagSet, config *config) {
+	if config.path == "" {
+		fmt.Fprintln(output, "# No config file found") //nolint:errcheck
+	} else {
+		fmt.Fprintln(output, "# Config file:", config.path) //nolint:errcheck
+	}
+	fmt.Fprintln(output, "# Includes options from the config file, from the environment and from the command line") //nolint:errcheck
+
+	// Visit() only visits flags that have been set, in lexicographical order
+	flagSet.Visit(func(f *flag.Flag) {
+		if notConfigurable[f.Name] {
+			return
+		}
+		fmt.Fprintf(output, "%s = %s\n", f.Name, configValueString(f.Value)) //nolint:errcheck
+	})
+
+	if len(config.keys) > 0 {
+		fmt.Fprintln(output)           //nolint:errcheck
+		fmt.Fprintln(output, "[keys]") //nolint:errcheck
+		for _, binding := range config.keys {
+			fmt.Fprintf(output, "%s = %s\n", strconv.Quote(binding.name), strconv.Quote(binding.value)) //nolint:errcheck
+		}
+	}
+}
+
+func configValueString(value flag.Value) string {
+	valueString := value.String()
+
+	if boolFlag, ok := value.(interface{ IsBoolFlag() bool }); ok && boolFlag.IsBoolFlag() {
+		return valueString
+	}
+
+	if _, err := strconv.Atoi(valueString); err == nil {
+		return valueString
+	}
+
+	return strconv.Quote(valueString)
+}
+
+// A line from the config file being parsed
+type configLine struct {
+	text string
+	pos  int
+}
+
+func (l *configLine) peek() byte {
+	return l.text[l.pos]
+}
+
+func (l *configLine) skipSpace() {
+	for l.pos < len(l.text) && (l.text[l.pos] == ' ' || l.text[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+// True if only whitespace and comments remain
+func (l *configLine) atEnd() bool {
+	l.skipSpace()
+	return l.pos >= len(l.text) || l.peek() == '#'
+}
+
+// Parse something like [files."*.md"]
+func (l *configLine) parseHeader() ([]string, error) {
+	l.pos++ // Skip the [
+
+	parts := []string{}
+	for {
+		l.skipSpace()
+		part, err := l.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		parts = append(parts, part)
+
+		l.skipSpace()
+		if l.pos >= len(l.text) {
+			return nil, fmt.Errorf("Expected ] at the end of the section header")
+		}
+
+		if l.peek() == ']' {
+			l.pos++
+			break
+		}
+
+		if l.peek() != '.' {
+			return nil, fmt.Errorf("Expected . or ] in section header, got <%c>", l.peek())
+		}
+		l.pos++
+	}
+
+	if !l.atEnd() {
+		return nil, fmt.Errorf("Unexpected text after section header: <%s>", l.text[l.pos:])
+	}
+
+	return parts, nil
+}
+
+// Parse something like `wrap = true`
+func (l *configLine) parseOption() (configOption, error) {
+	name, err := l.parseKey()
+	if err != nil {
+		return configOption{}, err
+	}
+
+	l.skipSpace()
+	if l.pos >= len(l.text) || l.peek() != '=' {
+		return configOption{}, fmt.Errorf("Expected = after <%s>", name)
+	}
+	l.pos++
+	l.skipSpace()
+
+	value, err := l.parseValue()
+	if err != nil {
+		return configOption{}, fmt.Errorf("%s: %w", name, err)
+	}
+
+	if !l.atEnd() {
+		return configOption{}, fmt.Errorf("Unexpected text after value: <%s>", l.text[l.pos:])
+	}
+
+	return configOption{name: name, value: value}, nil
+}
+
+// A bare key like ctrl-f, or a quoted one like "*.md"
+func (l *configLine) parseKey() (string, error) {
+	if l.pos < len(l.text) && (l.peek() == '"' || l.peek() == '\'') {
+		return l.parseString()
+	}
+
+	start := l.pos
+	for l.pos < len(l.text) && isBareKeyChar(l.peek()) {
+		l.pos++
+	}
+
+	if l.pos == start {
+		return "", fmt.Errorf("Expected a name at <%s>", l.text[l.pos:])
+	}
+
+	return l.text[start:l.pos], nil
+}
+
+func isBareKeyChar(char byte) bool {
+	return (char >= 'a' && char <= 'z') ||
+		(char >= 'A' && char <= 'Z') ||
+		(char >= '0' && char <= '9') ||
+		char == '-' || char == '_'
+}
+
+// Strings are returned unquoted, everything else as written
+func (l *configLine) parseValue() (string, error) {
+	if l.pos >= len(l.text) {
+		return "", fmt.Errorf("Value missing")
+	}
+
+	if l.peek() == '"' || l.peek() == '\'' {
+		return l.parseString()
+	}
+
+	start := l.pos
+	for l.pos < len(l.text) && l.peek() != ' ' && l.peek() != '\t' && l.peek() != '#' {
+		l.pos++
+	}
+	value := l.text[start:l.pos]
+
+	if value == "true" || value == "false" {
+		return value, nil
+	}
+
+	number, err := strconv.ParseInt(strings.ReplaceAll(value, "_", ""), 10, 64)
+	if err == nil {
+		return strconv.FormatInt(number, 10), nil
+	}
+
+	return "", fmt.Errorf("Expected a \"string\", a number, true or false, got <%s>", value)
+}
+
+func (l *configLine) parseString() (string, error) {
+	quote := l.peek()
+	start := l.pos
+	l.pos++
+
+	for l.pos < len(l.text) {
+		char := l.peek()
+		if char == '\\' && quote == '"' {
+			// Skip the escaped character
+			l.pos += 2
+			continue
+		}
+
+		l.pos++
+		if char != quote {
+			continue
+		}
+
+		if quote == '\'' {
+			// Literal string, no escapes
+			return l.text[start+1 : l.pos-1], nil
+		}
+
+		unquoted, err := strconv.Unquote(l.text[start:l.pos])
+		if err != nil {
+			return "", fmt.Errorf("Invalid string %s", l.text[start:l.pos])
+		}
+		return unquoted, nil
+	}
+
+	return "", fmt.Errorf("Unterminated string: %s", l.text[start:])
+}
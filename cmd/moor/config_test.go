@@ -0,0 +1,94 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+const testConfig = `
+# Comments are fine
+wrap = false
+style = "solarized dark" # Spaces in values are fine too
+shift = 8
+
+[keys]
+ctrl-f = "page-down"
+"/" = 'search-forward'
+
+[files."*.md"]
+wrap = true
+
+[languages.json]
+no-linenumbers = true
+`
+
+func testFlagSet() *flag.FlagSet {
+	flagSet := flag.NewFlagSet("", flag.ContinueOnError)
+	flagSet.Bool("wrap", false, "")
+	flagSet.String("style", "", "")
+	flagSet.Int("shift", 16, "")
+	flagSet.Bool("no-linenumbers", false, "")
+	return flagSet
+}
+
+func TestParseConfig(t *testing.T) {
+	config, err := parseConfig("config.toml", strings.NewReader(testConfig))
+	assert.NilError(t, err)
+
+	assert.Equal(t, len(config.keys), 2)
+	assert.Equal(t, config.keys[0], configOption{name: "ctrl-f", value: "page-down", line: 8})
+	assert.Equal(t, config.keys[1], configOption{name: "/", value: "search-forward", line: 9})
+
+	args, err := config.args(testFlagSet(), "", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--wrap=false", "--style=solarized dark", "--shift=8"})
+
+	args, err = config.args(testFlagSet(), "docs/README.md", nil)
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--wrap=false", "--style=solarized dark", "--shift=8", "--wrap=true"})
+
+	args, err = config.args(testFlagSet(), "x.txt", lexers.Get("json"))
+	assert.NilError(t, err)
+	assert.DeepEqual(t, args, []string{"--wrap=false", "--style=solarized dark", "--shift=8", "--no-linenumbers=true"})
+}
+
+func TestParseConfigErrors(t *testing.T) {
+	_, err := parseConfig("config.toml", strings.NewReader("wrap = yes"))
+	assert.Error(t, err, "config.toml:1: wrap: Expected a \"string\", a number, true or false, got <yes>")
+
+	_, err = parseConfig("config.toml", strings.NewReader("\n[colors]"))
+	assert.ErrorContains(t, err, "config.toml:2: Unknown section [colors]")
+
+	// Unknown options should be reported even if their section doesn't apply
+	config, err := parseConfig("config.toml", strings.NewReader("[files.\"*.md\"]\nwarp = true"))
+	assert.NilError(t, err)
+	_, err = config.args(testFlagSet(), "", nil)
+	assert.Error(t, err, "config.toml:2: Unknown option <warp>")
+}
+
+func TestPagerFromArgsWithConfig(t *testing.T) {
+	configPath := filepath.Join(t.TempDir(), "config.toml")
+	assert.NilError(t, os.WriteFile(configPath, []byte("wrap = true\nshift = 4\n[keys]\nx = \"quit\"\n"), 0o600))
+
+	pager, _, _, _, _, err := pagerFromArgs(
+		[]string{"", "--config=" + configPath, "--shift=2", "moor_test.go"},
+		func(_ twin.MouseMode, _ twin.ColorCount) (twin.Screen, error) {
+			return twin.NewFakeScreen(80, 24), nil
+		},
+		false, // stdin is redirected
+		false, // stdout is redirected
+	)
+	assert.NilError(t, err)
+
+	assert.Assert(t, pager.WrapLongLines)
+
+	// Command line options should win over config file options
+	assert.Equal(t, pager.SideScrollAmount, 2)
+}
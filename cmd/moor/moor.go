@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"runtime"
 	"runtime/debug"
 	"strconv"
@@ -159,6 +160,15 @@ func parseScrollHint(scrollHint string) (twin.StyledRune, error) {
 	return twin.StyledRune{}, fmt.Errorf("Expected exactly one (optionally highlighted) character. For example: 'ESC[2m…'")
 }
 
+func parseHeaderSize(headerSize string) (uint, error) {
+	value, err := strconv.ParseUint(headerSize, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("Header size must be 0 or higher")
+	}
+
+	return uint(value), nil
+}
+
 func parseShiftAmount(shiftAmount string) (uint, error) {
 	value, err := strconv.ParseUint(shiftAmount, 10, 32)
 	if err != nil {
@@ -187,6 +197,70 @@ func parseMouseMode(mouseMode string) (twin.MouseMode, error) {
 	return twin.MouseModeAuto, fmt.Errorf("Valid modes are auto, select and scroll")
 }
 
+func parseSearchMode(searchMode string) (internal.RegexpMode, error) {
+	switch searchMode {
+	case "auto":
+		return internal.RegexpModeAuto, nil
+	case "regexp":
+		return internal.RegexpModeOn, nil
+	case "literal":
+		return internal.RegexpModeOff, nil
+	}
+
+	return internal.RegexpModeAuto, fmt.Errorf("Valid modes are auto, regexp and literal")
+}
+
+func parseSearchCase(searchCase string) (internal.CaseMode, error) {
+	switch searchCase {
+	case "smart":
+		return internal.CaseModeSmart, nil
+	case "sensitive":
+		return internal.CaseModeSensitive, nil
+	case "insensitive":
+		return internal.CaseModeInsensitive, nil
+	}
+
+	return internal.CaseModeSmart, fmt.Errorf("Valid values are smart, sensitive and insensitive")
+}
+
+func parseRemember(remember string) (internal.RememberMode, error) {
+	switch remember {
+	case "always":
+		return internal.RememberAlways, nil
+	case "unchanged":
+		return internal.RememberIfUnchanged, nil
+	case "never":
+		return internal.RememberNever, nil
+	}
+
+	return internal.RememberIfUnchanged, fmt.Errorf("Valid values are always, unchanged and never")
+}
+
+func parseKeymap(keymapName string) (*internal.Keymap, error) {
+	return internal.NewKeymap(keymapName)
+}
+
+func firstFilename(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	return args[0]
+}
+
+// Used for picking per-language config file options. Returns nil if unknown.
+func detectLanguage(lexerOption chroma.Lexer, args []string) chroma.Lexer {
+	if lexerOption != nil {
+		return lexerOption
+	}
+
+	filename := firstFilename(args)
+	if filename == "" {
+		return nil
+	}
+
+	return lexers.Match(filepath.Base(filename))
+}
+
 func pumpToStdout(inputFilenames ...string) error {
 	if len(inputFilenames) > 0 {
 		// If we get both redirected stdin and an input filenames, should only
@@ -325,6 +399,8 @@ func pagerFromArgs(
 	printVersion := flagSet.Bool("version", false, "Prints the moor version number")
 	debug := flagSet.Bool("debug", false, "Print debug logs after exiting")
 	trace := flagSet.Bool("trace", false, "Print trace logs after exiting")
+	configPath := flagSet.String("config", "", "Config `file` to use instead of ~/.config/moor/config.toml")
+	printConfigOption := flagSet.Bool("print-config", false, "Print the effective configuration and exit")
 
 	wrap := flagSet.Bool("wrap", false, "Wrap long lines")
 	follow := flagSet.Bool("follow", false, "Follow piped input just like \"tail -f\"")
@@ -334,6 +410,10 @@ func pagerFromArgs(
 	lexer := flagSetFunc(flagSet,
 		"lang", nil,
 		"File contents, used for highlighting. Mime type or file extension (\"html\"). Default is to guess by filename.", parseLexerOption)
+	headerLines := flagSetFunc(flagSet, "header-lines", 0,
+		"Number of `lines` to keep at the top while scrolling. Change with ALT-up / ALT-down.", parseHeaderSize)
+	headerColumns := flagSetFunc(flagSet, "header-columns", 0,
+		"Number of `columns` to keep at the left edge while scrolling sideways. Change with '{' / '}'.", parseHeaderSize)
 	terminalFg := flagSet.Bool("terminal-fg", false, "Use terminal foreground color rather than style foreground for plain text")
 
 	defaultFormatter, err := parseColorsOption("auto")
@@ -353,6 +433,12 @@ func pagerFromArgs(
 		"Number of lines to leave for your shell prompt, defaults to 1")
 	statusBarStyle := flagSetFunc(flagSet, "statusbar", internal.STATUSBAR_STYLE_INVERSE,
 		"Status bar `style`: inverse, plain or bold", parseStatusBarStyle)
+	statusFormat := flagSetFunc(flagSet, "statusbar-format", nil,
+		"Status bar `template`, like \"{name}[  {first}-{last}/{total}]{>}{percent}\". See the man page for fields.", internal.ParseStatusFormat)
+	statusFormatFiltering := flagSetFunc(flagSet, "statusbar-format-filtering", nil,
+		"Status bar `template` while filtering, defaults to --statusbar-format", internal.ParseStatusFormat)
+	statusFormatFollowing := flagSetFunc(flagSet, "statusbar-format-following", nil,
+		"Status bar `template` while following, defaults to --statusbar-format", internal.ParseStatusFormat)
 	unprintableStyle := flagSetFunc(flagSet, "render-unprintable", textstyles.UnprintableStyleHighlight,
 		"How unprintable characters are rendered: highlight or whitespace", parseUnprintableStyle)
 	scrollLeftHint := flagSetFunc(flagSet, "scroll-left-hint",
@@ -361,6 +447,24 @@ func pagerFromArgs(
 	scrollRightHint := flagSetFunc(flagSet, "scroll-right-hint",
 		twin.NewStyledRune('>', twin.StyleDefault.WithAttr(twin.AttrReverse)),
 		"Shown when view can scroll right. One character with optional ANSI highlighting.", parseScrollHint)
+	linkOpener := flagSet.String("link-opener", "",
+		"Link opener `command`, the URL is added as its last argument. Defaults to the system's URL handler.")
+	scrollbar := flagSet.Bool("scrollbar", false, "Show a scrollbar with search hits and marks in the rightmost column. Click or drag it to scroll.")
+	searchMode := flagSetFunc(flagSet, "search-mode", internal.RegexpModeAuto,
+		"Search `mode`: auto, regexp or literal. Toggle with ALT-r while searching.", parseSearchMode)
+	searchCase := flagSetFunc(flagSet, "search-case", internal.CaseModeSmart,
+		"Search case `sensitivity`: smart, sensitive or insensitive. Toggle with ALT-c while searching.", parseSearchCase)
+	searchWholeWord := flagSet.Bool("search-whole-word", false, "Only match whole words when searching. Toggle with ALT-w while searching.")
+	searchFold := flagSet.Bool("search-fold", false, "Ignore accents and character widths when searching. Toggle with ALT-d while searching.")
+	defaultKeymap, err := internal.NewKeymap("default")
+	if err != nil {
+		panic(fmt.Errorf("Failed creating default keymap: %w", err))
+	}
+	keymap := flagSetFunc(flagSet, "keymap", defaultKeymap,
+		"Key bindings `preset`: default, less, vim or emacs. Customize in the [keys] section of the config file.", parseKeymap)
+	remember := flagSetFunc(flagSet, "remember", internal.RememberIfUnchanged,
+		"Restore position, marks, search and filter of previously viewed files: always, unchanged or never. "+
+			"Defaults to unchanged. Unless never, file paths, searches and filters are saved in $XDG_STATE_HOME/moor/file_history.", parseRemember)
 	shift := flagSetFunc(flagSet, "shift", 16, "Horizontal scroll `amount` >=1, defaults to 16", parseShiftAmount)
 	mouseMode := flagSetFunc(
 		flagSet,
@@ -383,12 +487,30 @@ func pagerFromArgs(
 
 	err = flagSet.Parse(remainingArgs)
 
+	// Now that we know which config file to use and which file we're going to
+	// show, prepend the config file options and parse again. Command line
+	// options and MOOR environment variable options win since they come last.
+	var moorConfig *config
+	if err == nil {
+		moorConfig, err = loadConfig(*configPath)
+	}
 	if err == nil {
-		if *noClearOnExitMargin < 0 {
-			err = fmt.Errorf("Invalid --no-clear-on-exit-margin %d, must be 0 or higher", *noClearOnExitMargin)
+		var configArgs []string
+		configArgs, err = moorConfig.args(flagSet, firstFilename(flagSet.Args()), detectLanguage(*lexer, flagSet.Args()))
+		if err == nil && len(configArgs) > 0 {
+			err = flagSet.Parse(append(configArgs, remainingArgs...))
 		}
 	}
 
+	if err == nil {
+		err = moorConfig.bindKeys(*keymap)
+	}
+
+	// Not until now, since the config file can set this too
+	if err == nil && *noClearOnExitMargin < 0 {
+		err = fmt.Errorf("Invalid --no-clear-on-exit-margin %d, must be 0 or higher", *noClearOnExitMargin)
+	}
+
 	if err != nil {
 		if err == flag.ErrHelp {
 			printUsage(flagSet, *terminalColorsCount)
@@ -416,6 +538,11 @@ func pagerFromArgs(
 		return nil, nil, chroma.Style{}, nil, logsRequested, nil
 	}
 
+	if *printConfigOption {
+		printConfig(os.Stdout, flagSet, moorConfig)
+		return nil, nil, chroma.Style{}, nil, logsRequested, nil
+	}
+
 	log.SetLevel(log.InfoLevel)
 	if *trace {
 		log.SetLevel(log.TraceLevel)
@@ -427,15 +554,6 @@ func pagerFromArgs(
 		TimestampFormat: time.StampMicro,
 	})
 
-	if len(flagSet.Args()) > 1 && !stdoutIsRedirected {
-		fmt.Fprintln(os.Stderr, "ERROR: Expected exactly one filename, or data piped from stdin")
-		fmt.Fprintln(os.Stderr)
-		printCommandline(os.Stderr)
-		fmt.Fprintln(os.Stderr, "For help, run: \x1b[1mmoor --help\x1b[m")
-
-		os.Exit(1)
-	}
-
 	for _, inputFilename := range flagSet.Args() {
 		// Need to check before newScreen() below, otherwise the screen
 		// will be cleared before we print the "No such file" error.
@@ -468,15 +586,6 @@ func pagerFromArgs(
 		panic("Invariant broken: stdout is not a terminal")
 	}
 
-	if len(flagSet.Args()) > 1 {
-		fmt.Fprintln(os.Stderr, "ERROR: Expected exactly one filename, or data piped from stdin")
-		fmt.Fprintln(os.Stderr)
-		printCommandline(os.Stderr)
-		fmt.Fprintln(os.Stderr, "For help, run: \x1b[1mmoor --help\x1b[m")
-
-		os.Exit(1)
-	}
-
 	formatter := formatters.TTY256
 	switch *terminalColorsCount {
 	case twin.ColorCount8:
@@ -488,6 +597,7 @@ func pagerFromArgs(
 	}
 
 	var readerImpl *reader.ReaderImpl
+	var moreReaders []*reader.ReaderImpl
 	shouldFormat := *reFormat
 	if stdinIsRedirected {
 		// Display input pipe contents
@@ -497,13 +607,22 @@ func pagerFromArgs(
 		}
 	} else {
 		// Display the input file contents
-		if len(flagSet.Args()) != 1 {
-			panic("Invariant broken: Expected exactly one filename")
+		if len(flagSet.Args()) < 1 {
+			panic("Invariant broken: Expected at least one filename")
 		}
-		readerImpl, err = reader.NewFromFilename(flagSet.Args()[0], formatter, reader.ReaderOptions{Lexer: *lexer, ShouldFormat: shouldFormat})
-		if err != nil {
-			return nil, nil, chroma.Style{}, nil, logsRequested, err
+
+		// Any more files, and any archive members, will be shown in their
+		// own buffers
+		var readers []*reader.ReaderImpl
+		for _, inputFilename := range flagSet.Args() {
+			fileReaders, err := reader.NewFromArchiveOrFilename(inputFilename, formatter, reader.ReaderOptions{Lexer: *lexer, ShouldFormat: shouldFormat})
+			if err != nil {
+				return nil, nil, chroma.Style{}, nil, logsRequested, err
+			}
+			readers = append(readers, fileReaders...)
 		}
+		readerImpl = readers[0]
+		moreReaders = readers[1:]
 	}
 
 	// If the user is doing "sudo something | moor" we can't show the UI until
@@ -518,6 +637,9 @@ func pagerFromArgs(
 		log.Info("Failed to set up screen for paging, pumping to stdout instead: ", err)
 
 		readerImpl.PumpToStdout()
+		for _, moreReader := range moreReaders {
+			moreReader.PumpToStdout()
+		}
 
 		return nil, nil, chroma.Style{}, nil, logsRequested, nil
 	}
@@ -532,6 +654,10 @@ func pagerFromArgs(
 	readerImpl.SetStyleForHighlighting(style)
 
 	pager := internal.NewPager(readerImpl)
+	for _, moreReader := range moreReaders {
+		moreReader.SetStyleForHighlighting(style)
+		pager.AddBuffer(moreReader)
+	}
 	pager.WrapLongLines = *wrap
 	pager.ShowLineNumbers = !*noLineNumbers
 	pager.ShowStatusBar = !*noStatusBar
@@ -539,11 +665,26 @@ func pagerFromArgs(
 	pager.DeInitFalseMargin = *noClearOnExitMargin
 	pager.QuitIfOneScreen = *quitIfOneScreen
 	pager.StatusBarStyle = *statusBarStyle
+	pager.StatusFormat = *statusFormat
+	pager.StatusFormatFiltering = *statusFormatFiltering
+	pager.StatusFormatFollowing = *statusFormatFollowing
 	pager.UnprintableStyle = *unprintableStyle
 	pager.WithTerminalFg = *terminalFg
 	pager.ScrollLeftHint = *scrollLeftHint
 	pager.ScrollRightHint = *scrollRightHint
+	pager.ShowScrollbar = *scrollbar
+	pager.LinkOpener = *linkOpener
+	pager.HeaderLines = int(*headerLines)
+	pager.HeaderColumns = int(*headerColumns)
 	pager.SideScrollAmount = int(*shift)
+	pager.Keymap = *keymap
+	pager.Remember = *remember
+	pager.SearchOptions = internal.SearchOptions{
+		Regexp:    *searchMode,
+		Case:      *searchCase,
+		WholeWord: *searchWholeWord,
+		Fold:      *searchFold,
+	}
 
 	pager.TargetLine = targetLine
 	if *follow && pager.TargetLine == nil {
@@ -607,6 +748,7 @@ func main() {
 		return
 	}
 
+	pager.Logs = &loglines
 	startPaging(pager, screen, &style, formatter)
 }
 
@@ -616,18 +758,34 @@ func main() {
 func flagSetFunc[T any](flagSet *flag.FlagSet, name string, defaultValue T, usage string, parser func(valueString string) (T, error)) *T {
 	parsed := defaultValue
 
-	flagSet.Func(name, usage, func(valueString string) error {
-		parseResult, err := parser(valueString)
-		if err != nil {
-			return err
-		}
-		parsed = parseResult
-		return nil
-	})
+	flagSet.Var(&funcValue[T]{parsed: &parsed, parser: parser}, name, usage)
 
 	return &parsed
 }
 
+// Like the flag package's func values, but remembers the string it was set
+// from so that --print-config can show it
+type funcValue[T any] struct {
+	parsed      *T
+	valueString string
+	parser      func(valueString string) (T, error)
+}
+
+func (v *funcValue[T]) String() string {
+	return v.valueString
+}
+
+func (v *funcValue[T]) Set(valueString string) error {
+	parseResult, err := v.parser(valueString)
+	if err != nil {
+		return err
+	}
+
+	*v.parsed = parseResult
+	v.valueString = valueString
+	return nil
+}
+
 func startPaging(pager *internal.Pager, screen twin.Screen, chromaStyle *chroma.Style, chromaFormatter *chroma.Formatter) {
 	defer func() {
 		// Restore screen...
@@ -129,7 +129,7 @@ func printUsage(flagSet *flag.FlagSet, colors twin.ColorCount) {
 	// FIXME: Log if any printouts fail?
 
 	fmt.Println(heading("Usage", colors))
-	fmt.Println("  moor [options] <file>")
+	fmt.Println("  moor [options] <file> [more files...]")
 	fmt.Println("  ... | moor")
 	fmt.Println("  moor < file")
 	fmt.Println()
@@ -0,0 +1,294 @@
+package internal
+
+// Something the user can make the pager do by pressing a key in viewing mode
+type action struct {
+	// Used for referring to this action in keys files, like "scroll-down"
+	name string
+
+	// Help screen section this action is listed in
+	section string
+
+	// Shown on the help screen
+	description string
+
+	do func(p *Pager)
+}
+
+const (
+	sectionMisc      = "Miscellaneous"
+	sectionMoving    = "Moving around"
+	sectionSearching = "Searching"
+	sectionFiltering = "Filtering"
+	sectionBuffers   = "Multiple files"
+	sectionSplit     = "Split screen"
+	sectionCommands  = "Commands"
+)
+
+// All actions, in help screen order
+var actions []action
+
+// Done in init() since the help action refers back to this list
+func init() {
+	actions = []action{
+		{"quit", sectionMisc, "Quit, or leave the help screen", func(p *Pager) {
+			p.Quit()
+		}},
+		{"help", sectionMisc, "Show this help", func(p *Pager) {
+			p.showHelp()
+		}},
+		{"toggle-wrap", sectionMisc, "Toggle wrapping of long lines", func(p *Pager) {
+			p.WrapLongLines = !p.WrapLongLines
+		}},
+		{"toggle-status-bar", sectionMisc, "Toggle showing the status bar at the bottom", func(p *Pager) {
+			p.ShowStatusBar = !p.ShowStatusBar
+		}},
+		{"more-header-lines", sectionMisc, "Keep one more line at the top while scrolling", func(p *Pager) {
+			p.HeaderLines++
+		}},
+		{"fewer-header-lines", sectionMisc, "Keep one line fewer at the top while scrolling", func(p *Pager) {
+			p.HeaderLines = max(p.HeaderLines-1, 0)
+		}},
+		{"more-header-columns", sectionMisc, "Keep one more column at the left while scrolling sideways", func(p *Pager) {
+			p.HeaderColumns++
+		}},
+		{"fewer-header-columns", sectionMisc, "Keep one column fewer at the left while scrolling sideways", func(p *Pager) {
+			p.HeaderColumns = max(p.HeaderColumns-1, 0)
+		}},
+		{"select-lines", sectionMisc, "Select lines to copy to the clipboard", func(p *Pager) {
+			p.startSelection()
+		}},
+		{"edit", sectionMisc, "Edit the file in your favorite editor, at the current line", func(p *Pager) {
+			handleEditingRequest(p)
+		}},
+		{"next-link", sectionMisc, "Select the next link on screen", func(p *Pager) {
+			p.focusNextHyperlink(false)
+		}},
+		{"previous-link", sectionMisc, "Select the previous link on screen", func(p *Pager) {
+			p.focusNextHyperlink(true)
+		}},
+		{"open-link", sectionMisc, "Open the selected link, or edit the file at a selected file:line reference", func(p *Pager) {
+			p.openFocusedHyperlink()
+		}},
+		{"copy-link", sectionMisc, "Copy the selected link to the clipboard", func(p *Pager) {
+			p.copyFocusedHyperlink()
+		}},
+
+		{"scroll-up", sectionMoving, "Scroll up one line", func(p *Pager) {
+			// Clipping is done in _Redraw()
+			p.scrollPosition = p.scrollPosition.PreviousLine(1)
+			p.handleScrolledUp()
+		}},
+		{"scroll-down", sectionMoving, "Scroll down one line", func(p *Pager) {
+			// Clipping is done in _Redraw()
+			p.scrollPosition = p.scrollPosition.NextLine(1)
+			p.handleScrolledDown()
+		}},
+		{"scroll-left", sectionMoving, "Scroll left, or show line numbers if already at the left edge", func(p *Pager) {
+			p.moveRight(-p.SideScrollAmount)
+		}},
+		{"scroll-right", sectionMoving, "Hide line numbers, or scroll right if already hidden", func(p *Pager) {
+			p.moveRight(p.SideScrollAmount)
+		}},
+		{"scroll-left-one", sectionMoving, "Scroll left one column", func(p *Pager) {
+			p.moveRight(-1)
+		}},
+		{"scroll-right-one", sectionMoving, "Scroll right one column", func(p *Pager) {
+			p.moveRight(1)
+		}},
+		{"page-up", sectionMoving, "Scroll up one page", func(p *Pager) {
+			p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight())
+			p.handleScrolledUp()
+		}},
+		{"page-down", sectionMoving, "Scroll down one page", func(p *Pager) {
+			p.scrollPosition = p.scrollPosition.NextLine(p.visibleHeight())
+			p.handleScrolledDown()
+		}},
+		{"half-page-up", sectionMoving, "Scroll up half a page", func(p *Pager) {
+			p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight() / 2)
+			p.handleScrolledUp()
+		}},
+		{"half-page-down", sectionMoving, "Scroll down half a page", func(p *Pager) {
+			p.scrollPosition = p.scrollPosition.NextLine(p.visibleHeight() / 2)
+			p.handleScrolledDown()
+		}},
+		{"go-to-start", sectionMoving, "Go to the start of the document", func(p *Pager) {
+			p.rememberJump()
+			p.scrollPosition = newScrollPosition("Pager scroll position")
+			p.handleScrolledUp()
+		}},
+		{"go-to-end", sectionMoving, "Go to the end of the document", func(p *Pager) {
+			p.rememberJump()
+			p.scrollToEnd()
+		}},
+		{"go-to-line", sectionMoving, "Go to a line number, press 'g' again in the prompt for the start", func(p *Pager) {
+			p.mode = newPagerModeGotoLine(p)
+			p.setTargetLine(nil)
+		}},
+		{"set-mark", sectionMoving, "Set a mark, you will be asked for a letter to label it with", func(p *Pager) {
+			p.mode = PagerModeMark{pager: p}
+			p.setTargetLine(nil)
+		}},
+		{"jump-to-mark", sectionMoving, "Jump to a mark, press ' again for where you were before the latest jump", func(p *Pager) {
+			p.mode = PagerModeJumpToMark{pager: p}
+			p.setTargetLine(nil)
+		}},
+		{"next-mark", sectionMoving, "Go to the next mark below", func(p *Pager) {
+			p.scrollToNextMark(1)
+		}},
+		{"previous-mark", sectionMoving, "Go to the previous mark above", func(p *Pager) {
+			p.scrollToPreviousMark(1)
+		}},
+		{"list-marks", sectionMoving, "List all marks, pick one to go there or press 'd' to delete it", func(p *Pager) {
+			p.showMarks()
+		}},
+		{"jump-back", sectionMoving, "Go back to where you were before the latest jump", func(p *Pager) {
+			p.jumpBack()
+		}},
+		{"jump-forward", sectionMoving, "Go forward again after going back", func(p *Pager) {
+			p.jumpForward()
+		}},
+		{"jump-history", sectionMoving, "List recent positions, pick one to go there", func(p *Pager) {
+			p.showJumpHistory()
+		}},
+		{"next-heading", sectionMoving, "Go to the next heading, like a man page section", func(p *Pager) {
+			p.scrollToNextHeading(1)
+		}},
+		{"previous-heading", sectionMoving, "Go to the previous heading", func(p *Pager) {
+			p.scrollToPreviousHeading(1)
+		}},
+		{"outline", sectionMoving, "List all headings, pick one to go there", func(p *Pager) {
+			p.showOutline()
+		}},
+		{"next-diff-file", sectionMoving, "In diffs, go to the next changed file", func(p *Pager) {
+			p.scrollToNextDiffMarker(true, 1)
+		}},
+		{"previous-diff-file", sectionMoving, "In diffs, go to the previous changed file", func(p *Pager) {
+			p.scrollToPreviousDiffMarker(true, 1)
+		}},
+		{"next-hunk", sectionMoving, "In diffs, go to the next hunk", func(p *Pager) {
+			p.scrollToNextDiffMarker(false, 1)
+		}},
+		{"previous-hunk", sectionMoving, "In diffs, go to the previous hunk", func(p *Pager) {
+			p.scrollToPreviousDiffMarker(false, 1)
+		}},
+		{"next-prompt", sectionMoving, "In terminal scrollback, go to the next shell prompt", func(p *Pager) {
+			p.scrollToNextPrompt(1)
+		}},
+		{"previous-prompt", sectionMoving, "In terminal scrollback, go to the previous shell prompt", func(p *Pager) {
+			p.scrollToPreviousPrompt(1)
+		}},
+		{"next-command-output", sectionMoving, "In terminal scrollback, go to the next command output", func(p *Pager) {
+			p.scrollToNextCommandOutput(1)
+		}},
+		{"previous-command-output", sectionMoving, "In terminal scrollback, go to the previous command output", func(p *Pager) {
+			p.scrollToPreviousCommandOutput(1)
+		}},
+
+		{"search-forward", sectionSearching, "Search forwards", func(p *Pager) {
+			p.mode = newPagerModeSearch(p, SearchDirectionForward)
+			p.setTargetLine(nil)
+			p.searchString = ""
+			p.searchPattern = nil
+		}},
+		{"search-backward", sectionSearching, "Search backwards", func(p *Pager) {
+			p.mode = newPagerModeSearch(p, SearchDirectionBackward)
+			p.setTargetLine(nil)
+			p.searchString = ""
+			p.searchPattern = nil
+		}},
+		{"search-next", sectionSearching, "Find next search hit", func(p *Pager) {
+			p.scrollToNextSearchHit()
+		}},
+		{"search-previous", sectionSearching, "Find previous search hit", func(p *Pager) {
+			p.scrollToPreviousSearchHit()
+		}},
+		{"copy-search-hit", sectionSearching, "Copy the current search hit to the clipboard", func(p *Pager) {
+			p.copySearchHit()
+		}},
+
+		{"filter", sectionFiltering, "Filter, only showing matching lines", func(p *Pager) {
+			if p.isShowingHelp {
+				// Filtering the help text is not supported. Feel free to work on
+				// that if you feel that's time well spent.
+				return
+			}
+
+			p.mode = newPagerModeFilter(p)
+			p.searchString = ""
+			p.searchPattern = nil
+			p.filterPattern = nil
+		}},
+		{"toggle-diff-context", sectionFiltering, "In diffs, hide or show unchanged context lines", func(p *Pager) {
+			p.toggleDiffChangesFilter()
+		}},
+		{"toggle-commands-filter", sectionFiltering, "In terminal scrollback, show only the commands that were run", func(p *Pager) {
+			p.toggleOnlyCommands()
+		}},
+
+		{"next-buffer", sectionBuffers, "Go to the next file", func(p *Pager) {
+			p.switchBuffer(1)
+		}},
+		{"previous-buffer", sectionBuffers, "Go to the previous file", func(p *Pager) {
+			p.switchBuffer(-1)
+		}},
+		{"search-all-buffers", sectionBuffers, "Search all files, listing the hits in a buffer of their own, press ENTER there to go to one", func(p *Pager) {
+			if p.isShowingHelp {
+				return
+			}
+
+			p.mode = newPagerModeGlobalSearch(p)
+			p.setTargetLine(nil)
+		}},
+		{"show-log", sectionBuffers, "Show what moor has logged, in a buffer of its own", func(p *Pager) {
+			if p.isShowingHelp {
+				return
+			}
+
+			p.showLog()
+		}},
+
+		{"toggle-split", sectionSplit, "Split the screen in two panes, or join them back", func(p *Pager) {
+			p.toggleSplit()
+		}},
+		{"switch-pane", sectionSplit, "Move focus to the other pane", func(p *Pager) {
+			p.switchPaneFocus()
+		}},
+		{"grow-pane", sectionSplit, "Make the focused pane taller", func(p *Pager) {
+			p.resizeFocusedPane(1)
+		}},
+		{"shrink-pane", sectionSplit, "Make the focused pane shorter", func(p *Pager) {
+			p.resizeFocusedPane(-1)
+		}},
+
+		{"command", sectionCommands, "Type a command, see below", func(p *Pager) {
+			p.mode = newPagerModeCommand(p)
+		}},
+	}
+}
+
+// Returns nil if there is no action by that name
+func findAction(name string) *action {
+	for i := range actions {
+		if actions[i].name == name {
+			return &actions[i]
+		}
+	}
+	return nil
+}
+
+func (p *Pager) showHelp() {
+	if p.isShowingHelp {
+		return
+	}
+
+	p.preHelpState = &_PreHelpState{
+		scrollPosition:      p.scrollPosition,
+		leftColumnZeroBased: p.leftColumnZeroBased,
+		targetLine:          p.TargetLine,
+	}
+	p.scrollPosition = newScrollPosition("Pager scroll position")
+	p.leftColumnZeroBased = 0
+	p.setTargetLine(nil)
+	p.helpReader = newHelpReader(p.Keymap)
+	p.isShowingHelp = true
+}
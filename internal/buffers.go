@@ -0,0 +1,212 @@
+package internal
+
+import (
+	"runtime/debug"
+	"strconv"
+	"time"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+)
+
+// One of the inputs the pager can show, typically one file from the command
+// line.
+//
+// The pager state fields are only valid while this buffer is not the current
+// one. For the current buffer, the live state is in the Pager itself.
+type buffer struct {
+	reader *reader.ReaderImpl
+
+	// Latest spinner update for this buffer's reader, empty means we're done
+	// loading
+	spinner string
+
+	scrollPosition      scrollPosition
+	leftColumnZeroBased int
+	targetLine          *linemetadata.Index
+	marks               map[rune]scrollPosition
+	jumpHistory         jumpHistory
+
+	// Set using the "lang" command, nil means guessing from the file name
+	lexer chroma.Lexer
+}
+
+// A reader for a new buffer is ready, sent when piping to a command since that
+// is done in the background
+type eventBufferOpened struct {
+	reader *reader.ReaderImpl
+}
+
+// AddBuffer adds another input to page through. The first buffer is the one
+// passed to NewPager().
+//
+// Must be called before StartPaging().
+func (p *Pager) AddBuffer(r *reader.ReaderImpl) {
+	p.buffers = append(p.buffers, &buffer{
+		reader:         r,
+		scrollPosition: newScrollPosition(bufferName(r)),
+		marks:          make(map[rune]scrollPosition),
+	})
+}
+
+// Add a buffer while paging, and switch to it
+func (p *Pager) openBuffer(r *reader.ReaderImpl) {
+	p.AddBuffer(r)
+	p.watchBuffer(p.buffers[len(p.buffers)-1])
+	p.switchToBuffer(len(p.buffers) - 1)
+}
+
+// Replace the reader of a buffer, keeping the position
+func (p *Pager) replaceBufferReader(index int, r *reader.ReaderImpl) {
+	b := p.buffers[index]
+	b.reader = r
+	p.watchBuffer(b)
+
+	if index != p.currentBufferIndex {
+		if b.targetLine == nil {
+			// Not canonicalized, since that would require this buffer's
+			// reader to be the current one
+			b.targetLine = b.scrollPosition.internalDontTouch.lineIndex
+		}
+		return
+	}
+
+	p.reader = r
+	p.filteringReader = p.newFilteringReader(r)
+
+	// The new reader starts out empty, scroll back to where we were as the
+	// lines arrive
+	if p.TargetLine == nil {
+		p.setTargetLine(p.lineIndex())
+	}
+}
+
+// The name to use when listing this reader's buffer
+func bufferName(r *reader.ReaderImpl) string {
+	if r == nil || r.Name == nil || len(*r.Name) == 0 {
+		return "stdin"
+	}
+	return *r.Name
+}
+
+func (p *Pager) currentBuffer() *buffer {
+	return p.buffers[p.currentBufferIndex]
+}
+
+// Something like "(2/3) ", or an empty string if we have only one buffer
+func (p *Pager) bufferStatusPrefix() string {
+	if len(p.buffers) < 2 || p.isShowingHelp {
+		return ""
+	}
+
+	return "(" + strconv.Itoa(p.currentBufferIndex+1) + "/" + strconv.Itoa(len(p.buffers)) + ") "
+}
+
+// Stop showing the current buffer and show the given one instead
+func (p *Pager) switchToBuffer(index int) {
+	if index == p.currentBufferIndex || index < 0 || index >= len(p.buffers) {
+		return
+	}
+
+	current := p.currentBuffer()
+	current.scrollPosition = p.scrollPosition
+	current.leftColumnZeroBased = p.leftColumnZeroBased
+	current.targetLine = p.TargetLine
+	current.marks = p.marks
+	current.jumpHistory = p.jumpHistory
+
+	p.currentBufferIndex = index
+	next := p.currentBuffer()
+
+	p.reader = next.reader
+	p.filteringReader = p.newFilteringReader(next.reader)
+	p.scrollPosition = next.scrollPosition
+	p.leftColumnZeroBased = next.leftColumnZeroBased
+	p.marks = next.marks
+	p.jumpHistory = next.jumpHistory
+	p.currentSearchHit = nil
+	p.focusedHyperlink = nil
+	p.setTargetLine(next.targetLine)
+
+	// The lines we're waiting for may have arrived while this buffer wasn't
+	// shown
+	p.scrollTowardsTargetLine()
+}
+
+// Negative deltas go to previous buffers. Wraps around at both ends.
+func (p *Pager) switchBuffer(delta int) {
+	if p.isShowingHelp {
+		return
+	}
+
+	count := len(p.buffers)
+	p.switchToBuffer(((p.currentBufferIndex+delta)%count + count) % count)
+}
+
+// Tell the main loop about things happening in this buffer's reader
+func (p *Pager) watchBuffer(b *buffer) {
+	r := b.reader
+	screen := p.screen
+
+	go func() {
+		defer func() {
+			PanicHandler("watchBuffer()/moreLinesAvailable", recover(), debug.Stack())
+		}()
+
+		for range r.MoreLinesAdded {
+			// Notify the main loop about the new lines so it can show them
+			screen.Events() <- eventMoreLinesAvailable{}
+
+			// Delay updates a bit so that we don't waste time refreshing
+			// the screen too often.
+			//
+			// Note that the delay is *after* reacting, this way single-line
+			// updates are reacted to immediately, and the first output line
+			// read will appear on screen without delay.
+			time.Sleep(200 * time.Millisecond)
+		}
+	}()
+
+	go func() {
+		defer func() {
+			PanicHandler("watchBuffer()/spinner", recover(), debug.Stack())
+		}()
+
+		// Spin the spinner as long as contents is still loading
+		spinnerFrames := [...]string{"/.\\", "-o-", "\\O/", "| |"}
+		spinnerIndex := 0
+		for !r.Done.Load() {
+			screen.Events() <- eventSpinnerUpdate{buffer: b, spinner: spinnerFrames[spinnerIndex]}
+			spinnerIndex++
+			if spinnerIndex >= len(spinnerFrames) {
+				spinnerIndex = 0
+			}
+
+			time.Sleep(200 * time.Millisecond)
+		}
+
+		// Empty our spinner, loading done!
+		screen.Events() <- eventSpinnerUpdate{buffer: b, spinner: ""}
+
+		r.Lock()
+		err := r.Err
+		r.Unlock()
+		if err != nil {
+			screen.Events() <- eventStatusMessage{
+				level: statusMessageError,
+				text:  "Reading " + bufferName(r) + " failed: " + err.Error(),
+			}
+		}
+	}()
+
+	go func() {
+		defer func() {
+			PanicHandler("watchBuffer()/maybeDone", recover(), debug.Stack())
+		}()
+
+		for range r.MaybeDone {
+			screen.Events() <- eventMaybeDone{}
+		}
+	}()
+}
@@ -0,0 +1,595 @@
+package internal
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+)
+
+// Something the user can type at the command prompt, like "open README.md"
+type command struct {
+	name    string
+	aliases []string
+
+	// Shown on the help screen, like "open FILE"
+	usage       string
+	description string
+
+	run func(p *Pager, argument string) error
+
+	// Candidates for completing the argument, nil if there is nothing to
+	// complete
+	complete func(p *Pager, argument string) []string
+}
+
+// All commands, in help screen order
+var commands []command
+
+// Done in init() since the help text refers back to this list
+func init() {
+	commands = []command{
+		{"open", []string{"e"}, "open FILE", "Open a file in a new buffer", func(p *Pager, argument string) error {
+			return p.openFile(argument)
+		}, completeFileName},
+		{"next", []string{"n"}, "next", "Go to the next file", func(p *Pager, _ string) error {
+			p.switchBuffer(1)
+			return nil
+		}, nil},
+		{"previous", []string{"p"}, "previous", "Go to the previous file", func(p *Pager, _ string) error {
+			p.switchBuffer(-1)
+			return nil
+		}, nil},
+		{"goto", []string{"g"}, "goto LINE", "Go to a line number, or to a percentage like 50%", func(p *Pager, argument string) error {
+			return p.gotoCommand(argument)
+		}, nil},
+		{"set", nil, "set OPTION[=VALUE]", "Change an option, \"set nowrap\" turns wrapping off", func(p *Pager, argument string) error {
+			return p.setOption(argument)
+		}, completeOptionName},
+		{"save", []string{"w"}, "save FILE", "Save the lines shown, respecting any filter", func(p *Pager, argument string) error {
+			return p.saveToFile(argument)
+		}, completeFileName},
+		{"pipe", []string{"|"}, "pipe COMMAND", "Show what a shell command makes of the lines shown", func(p *Pager, argument string) error {
+			return p.pipeToCommand(argument)
+		}, nil},
+		{"style", nil, "style NAME", "Change the highlighting style", func(p *Pager, argument string) error {
+			return p.setHighlightingStyle(argument)
+		}, completeStyleName},
+		{"lang", nil, "lang NAME", "Change the highlighting language of the current file", func(p *Pager, argument string) error {
+			return p.setHighlightingLanguage(argument)
+		}, completeLanguageName},
+		{"quit", []string{"q"}, "quit", "Quit", func(p *Pager, _ string) error {
+			p.Quit()
+			return nil
+		}, nil},
+	}
+
+	helpSectionNotes[sectionCommands] = commandsHelp()
+}
+
+// Lists the commands for the help screen
+func commandsHelp() string {
+	var builder strings.Builder
+	for _, command := range commands {
+		usages := []string{command.usage}
+		for _, alias := range command.aliases {
+			usages = append(usages, alias+strings.TrimPrefix(command.usage, command.name))
+		}
+		builder.WriteString("* " + strings.Join(usages, " / ") + ": " + command.description + "\n")
+	}
+
+	builder.WriteString("\nA line number alone is the same as \"goto\". Press TAB to complete command\n")
+	builder.WriteString("names, options, file names, styles and languages.\n\n")
+	builder.WriteString("Options: " + wrapWords(commandOptionNames(), len("Options: "), 78))
+	return builder.String()
+}
+
+// Returns nil if there is no command by that name or alias
+func findCommand(name string) *command {
+	for i := range commands {
+		if commands[i].name == name {
+			return &commands[i]
+		}
+		for _, alias := range commands[i].aliases {
+			if alias == name {
+				return &commands[i]
+			}
+		}
+	}
+	return nil
+}
+
+// Split a command line into the command name and its argument
+func splitCommandLine(commandLine string) (name string, argument string) {
+	commandLine = strings.TrimSpace(commandLine)
+	if strings.HasPrefix(commandLine, "|") {
+		// Like less, no space needed after the pipe
+		return "|", strings.TrimSpace(commandLine[1:])
+	}
+
+	name, argument, _ = strings.Cut(commandLine, " ")
+	return name, strings.TrimSpace(argument)
+}
+
+func (p *Pager) runCommand(commandLine string) error {
+	name, argument := splitCommandLine(commandLine)
+	if name == "" {
+		return nil
+	}
+
+	if _, err := strconv.Atoi(strings.TrimSuffix(name, "%")); err == nil {
+		// Just a line number or a percentage
+		return p.gotoCommand(name)
+	}
+
+	command := findCommand(name)
+	if command == nil {
+		return fmt.Errorf("Unknown command: %s", name)
+	}
+
+	return command.run(p, argument)
+}
+
+func (p *Pager) gotoCommand(argument string) error {
+	if percentString, isPercent := strings.CutSuffix(argument, "%"); isPercent {
+		percent, err := strconv.Atoi(percentString)
+		if err != nil || percent < 0 {
+			return fmt.Errorf("Not a percentage: %s", argument)
+		}
+		p.goToPercent(percent)
+		return nil
+	}
+
+	lineNumber, err := strconv.Atoi(argument)
+	if err != nil || lineNumber < 1 {
+		return fmt.Errorf("Not a line number: %s", argument)
+	}
+	p.goToLine(linemetadata.IndexFromOneBased(lineNumber))
+	return nil
+}
+
+// Expand a leading ~ into the user's home directory
+func expandHome(fileName string) string {
+	if fileName != "~" && !strings.HasPrefix(fileName, "~/") {
+		return fileName
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		log.Debug("Not expanding ~, home directory unknown: ", err)
+		return fileName
+	}
+	return home + fileName[1:]
+}
+
+func (p *Pager) readerOptions(lexer chroma.Lexer) reader.ReaderOptions {
+	style := p.chromaStyle
+	if style == nil {
+		// Readers won't finish without a style
+		style = &chroma.Style{}
+	}
+	return reader.ReaderOptions{Style: style, Lexer: lexer}
+}
+
+func (p *Pager) formatter() chroma.Formatter {
+	if p.chromaFormatter == nil {
+		return nil
+	}
+	return *p.chromaFormatter
+}
+
+func (p *Pager) openFile(fileName string) error {
+	if fileName == "" {
+		return fmt.Errorf("Which file? Try \"open FILE\"")
+	}
+
+	readers, err := reader.NewFromArchiveOrFilename(expandHome(fileName), p.formatter(), p.readerOptions(nil))
+	if err != nil {
+		return err
+	}
+
+	// For archives, show the first member
+	firstIndex := len(p.buffers)
+	for _, r := range readers {
+		p.AddBuffer(r)
+		p.watchBuffer(p.buffers[len(p.buffers)-1])
+	}
+	p.switchToBuffer(firstIndex)
+	return nil
+}
+
+// Plain text of all lines in the current buffer, respecting any filter
+func (p *Pager) plainText() string {
+	var builder strings.Builder
+	lines := p.Reader().GetLines(linemetadata.Index{}, math.MaxInt)
+	for _, line := range lines.Lines {
+		builder.WriteString(line.Plain())
+		builder.WriteString("\n")
+	}
+	return builder.String()
+}
+
+func (p *Pager) saveToFile(fileName string) error {
+	if fileName == "" {
+		return fmt.Errorf("Save to where? Try \"save FILE\"")
+	}
+
+	// Refuse to overwrite existing files
+	file, err := os.OpenFile(expandHome(fileName), os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o666)
+	if err != nil {
+		return err
+	}
+
+	_, err = file.WriteString(p.plainText())
+	closeErr := file.Close()
+	if err != nil {
+		return err
+	}
+	return closeErr
+}
+
+func (p *Pager) pipeToCommand(commandLine string) error {
+	if commandLine == "" {
+		return fmt.Errorf("Pipe to what? Try \"pipe COMMAND\"")
+	}
+
+	var command *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = exec.Command("cmd", "/C", commandLine)
+	} else {
+		command = exec.Command("sh", "-c", commandLine)
+	}
+
+	output, outputWriter := io.Pipe()
+	command.Stdin = strings.NewReader(p.plainText())
+	command.Stdout = outputWriter
+	command.Stderr = outputWriter
+
+	err := command.Start()
+	if err != nil {
+		return fmt.Errorf("Starting %s failed: %w", commandLine, err)
+	}
+
+	screen := p.screen
+	go func() {
+		defer func() {
+			PanicHandler("pipeToCommand()/wait", recover(), debug.Stack())
+		}()
+
+		err := command.Wait()
+		if err != nil {
+			// Also covers failing to write to the command
+			log.Error("Piping to ", commandLine, " failed: ", err)
+			screen.Events() <- eventStatusMessage{
+				level: statusMessageError,
+				text:  "Piping to " + commandLine + " failed: " + err.Error(),
+			}
+		}
+		_ = outputWriter.Close()
+	}()
+
+	// Creating the reader waits for the first output, so don't block the UI
+	// while doing that
+	formatter := p.formatter()
+	options := p.readerOptions(nil)
+	go func() {
+		defer func() {
+			PanicHandler("pipeToCommand()/read", recover(), debug.Stack())
+		}()
+
+		r, err := reader.NewFromStream("| "+commandLine, output, formatter, options)
+		if err != nil {
+			log.Error("Reading output from ", commandLine, " failed: ", err)
+			screen.Events() <- eventStatusMessage{
+				level: statusMessageError,
+				text:  "Reading output from " + commandLine + " failed: " + err.Error(),
+			}
+			return
+		}
+		screen.Events() <- eventBufferOpened{reader: r}
+	}()
+
+	return nil
+}
+
+func (p *Pager) setHighlightingStyle(name string) error {
+	style, found := styles.Registry[name]
+	if !found {
+		return fmt.Errorf("Unknown style: %s", name)
+	}
+
+	p.chromaStyle = style
+	styleUI(p.chromaStyle, p.chromaFormatter, p.StatusBarStyle, p.WithTerminalFg)
+
+	for _, b := range p.buffers {
+		b.reader.Rehighlight(*style, p.formatter())
+	}
+
+	return nil
+}
+
+func (p *Pager) setHighlightingLanguage(name string) error {
+	lexer := lexers.MatchMimeType(name)
+	if lexer == nil {
+		lexer = lexers.Get(name)
+	}
+	if lexer == nil {
+		return fmt.Errorf("Unknown language: %s", name)
+	}
+
+	if p.isShowingHelp || p.reader.FileName == nil {
+		return fmt.Errorf("Highlighting can only be changed for files")
+	}
+
+	p.currentBuffer().lexer = lexer
+	return p.reloadBuffer(p.currentBufferIndex)
+}
+
+// Read a file buffer again, to highlight it differently
+func (p *Pager) reloadBuffer(index int) error {
+	b := p.buffers[index]
+	r, err := reader.NewFromFilename(*b.reader.FileName, p.formatter(), p.readerOptions(b.lexer))
+	if err != nil {
+		return err
+	}
+
+	p.replaceBufferReader(index, r)
+	return nil
+}
+
+// Options for the "set" command. Boolean options are set using "set NAME",
+// "set noNAME" or "set NAME!" to toggle. The others are set using
+// "set NAME=VALUE".
+type commandOption struct {
+	name string
+
+	// For boolean options, nil for the others
+	flag func(p *Pager) *bool
+
+	// For options with values, nil for boolean options
+	setValue func(p *Pager, value string) error
+}
+
+var commandOptions = []commandOption{
+	{name: "wrap", flag: func(p *Pager) *bool { return &p.WrapLongLines }},
+	{name: "linenumbers", flag: func(p *Pager) *bool { return &p.ShowLineNumbers }},
+	{name: "statusbar", flag: func(p *Pager) *bool { return &p.ShowStatusBar }},
+	{name: "scrollbar", flag: func(p *Pager) *bool { return &p.ShowScrollbar }},
+	{name: "search-whole-word", flag: func(p *Pager) *bool { return &p.SearchOptions.WholeWord }},
+	{name: "search-fold", flag: func(p *Pager) *bool { return &p.SearchOptions.Fold }},
+	{name: "header-lines", setValue: func(p *Pager, value string) error {
+		return setCount(&p.HeaderLines, value, 0)
+	}},
+	{name: "header-columns", setValue: func(p *Pager, value string) error {
+		return setCount(&p.HeaderColumns, value, 0)
+	}},
+	{name: "shift", setValue: func(p *Pager, value string) error {
+		return setCount(&p.SideScrollAmount, value, 1)
+	}},
+	{name: "search-mode", setValue: func(p *Pager, value string) error {
+		for _, mode := range []RegexpMode{RegexpModeAuto, RegexpModeOn, RegexpModeOff} {
+			if mode.String() == value {
+				p.SearchOptions.Regexp = mode
+				return nil
+			}
+		}
+		return fmt.Errorf("Valid search modes are auto, regexp and literal")
+	}},
+	{name: "search-case", setValue: func(p *Pager, value string) error {
+		modes := map[string]CaseMode{
+			"smart":       CaseModeSmart,
+			"sensitive":   CaseModeSensitive,
+			"insensitive": CaseModeInsensitive,
+		}
+		mode, found := modes[value]
+		if !found {
+			return fmt.Errorf("Valid search cases are smart, sensitive and insensitive")
+		}
+		p.SearchOptions.Case = mode
+		return nil
+	}},
+}
+
+func setCount(count *int, value string, minimum int) error {
+	parsed, err := strconv.Atoi(value)
+	if err != nil || parsed < minimum {
+		return fmt.Errorf("Expected a number >= %d, got: %s", minimum, value)
+	}
+	*count = parsed
+	return nil
+}
+
+func findCommandOption(name string) *commandOption {
+	for i := range commandOptions {
+		if commandOptions[i].name == name {
+			return &commandOptions[i]
+		}
+	}
+	return nil
+}
+
+func commandOptionNames() []string {
+	names := []string{}
+	for _, option := range commandOptions {
+		names = append(names, option.name)
+	}
+	return names
+}
+
+func (p *Pager) setOption(argument string) error {
+	if argument == "" {
+		return fmt.Errorf("Options: %s", strings.Join(commandOptionNames(), ", "))
+	}
+
+	name, value, hasValue := strings.Cut(argument, "=")
+	name = strings.TrimSpace(name)
+	value = strings.TrimSpace(value)
+
+	if hasValue {
+		option := findCommandOption(name)
+		if option == nil {
+			return fmt.Errorf("Unknown option: %s", name)
+		}
+		if option.setValue == nil {
+			return fmt.Errorf("Try \"set %s\" or \"set no%s\"", name, name)
+		}
+		return option.setValue(p, value)
+	}
+
+	if toggleName, isToggle := strings.CutSuffix(name, "!"); isToggle {
+		option := findCommandOption(toggleName)
+		if option == nil || option.flag == nil {
+			return fmt.Errorf("Unknown flag: %s", toggleName)
+		}
+		flag := option.flag(p)
+		*flag = !*flag
+		return nil
+	}
+
+	flagValue := true
+	option := findCommandOption(name)
+	if option == nil && strings.HasPrefix(name, "no") {
+		flagValue = false
+		option = findCommandOption(strings.TrimPrefix(name, "no"))
+	}
+	if option == nil {
+		return fmt.Errorf("Unknown option: %s", name)
+	}
+	if option.flag == nil {
+		return fmt.Errorf("Try \"set %s=VALUE\"", option.name)
+	}
+
+	*option.flag(p) = flagValue
+	return nil
+}
+
+// Returns the part of the command line before the word being completed, and
+// the completion candidates for that word. Command names get a space added,
+// since they are always followed by an argument or by nothing.
+func completeCommandLine(p *Pager, commandLine string) (prefix string, candidates []string) {
+	name, argument, hasArgument := strings.Cut(commandLine, " ")
+	if !hasArgument {
+		for _, command := range commands {
+			if strings.HasPrefix(command.name, name) {
+				candidates = append(candidates, command.name+" ")
+			}
+		}
+		return "", candidates
+	}
+
+	command := findCommand(name)
+	if command == nil || command.complete == nil {
+		return "", nil
+	}
+
+	argument = strings.TrimLeft(argument, " ")
+	prefix = commandLine[:len(commandLine)-len(argument)]
+	return prefix, command.complete(p, argument)
+}
+
+func completeOptionName(_ *Pager, argument string) []string {
+	candidates := []string{}
+	for _, option := range commandOptions {
+		names := []string{option.name}
+		if option.flag != nil {
+			names = append(names, "no"+option.name)
+		}
+
+		for _, name := range names {
+			if strings.HasPrefix(name, argument) {
+				candidates = append(candidates, name)
+			}
+		}
+	}
+	sort.Strings(candidates)
+	return candidates
+}
+
+func completeStyleName(_ *Pager, argument string) []string {
+	candidates := []string{}
+	for _, name := range styles.Names() {
+		if strings.HasPrefix(name, argument) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+func completeLanguageName(_ *Pager, argument string) []string {
+	candidates := []string{}
+	for _, name := range lexers.Names(true) {
+		if strings.HasPrefix(strings.ToLower(name), strings.ToLower(argument)) {
+			candidates = append(candidates, name)
+		}
+	}
+	return candidates
+}
+
+// Directories get a trailing separator, so that you can continue completing
+// inside of them
+func completeFileName(_ *Pager, argument string) []string {
+	expanded := expandHome(argument)
+
+	// Don't let characters in the file name act as wildcards. Windows doesn't
+	// support escaping, but also doesn't allow these characters in file names.
+	escaped := expanded
+	if runtime.GOOS != "windows" {
+		for _, special := range []string{`\`, "*", "?", "["} {
+			escaped = strings.ReplaceAll(escaped, special, `\`+special)
+		}
+	}
+
+	matches, err := filepath.Glob(escaped + "*")
+	if err != nil {
+		log.Debug("Failed to complete file name ", argument, ": ", err)
+		return nil
+	}
+
+	typedBaseName := expanded[strings.LastIndex(expanded, string(filepath.Separator))+1:]
+	showHidden := strings.HasPrefix(typedBaseName, ".")
+	candidates := []string{}
+	for _, match := range matches {
+		if !showHidden && strings.HasPrefix(filepath.Base(match), ".") {
+			continue
+		}
+
+		if stat, err := os.Stat(match); err == nil && stat.IsDir() {
+			match += string(filepath.Separator)
+		}
+
+		// Keep the ~ if the user typed one
+		candidates = append(candidates, argument+strings.TrimPrefix(match, expanded))
+	}
+	return candidates
+}
+
+// The longest prefix shared by all strings
+func commonPrefix(texts []string) string {
+	if len(texts) == 0 {
+		return ""
+	}
+
+	prefix := texts[0]
+	for _, text := range texts[1:] {
+		for !strings.HasPrefix(text, prefix) {
+			_, lastRuneSize := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-lastRuneSize]
+		}
+	}
+	return prefix
+}
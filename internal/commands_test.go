@@ -0,0 +1,182 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func newCommandTestPager(t *testing.T) *Pager {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestCommands", "first\nsecond\nthird\nfourth"), 40, 10)
+	return pager
+}
+
+func typeCommand(pager *Pager, commandLine string) {
+	pager.mode.onRune(':')
+	for _, char := range commandLine {
+		pager.mode.onRune(char)
+	}
+	pager.mode.onKey(twin.KeyEnter)
+}
+
+func TestCommandGoto(t *testing.T) {
+	pager := newCommandTestPager(t)
+	pager.screen = twin.NewFakeScreen(40, 3)
+
+	typeCommand(pager, "3")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+
+	typeCommand(pager, "goto 1")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+
+	typeCommand(pager, "g 100%")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+
+	assert.Equal(t, statusText(pager), "")
+	assert.Equal(t, pager.mode, PagerMode(PagerModeViewing{pager: pager}))
+}
+
+func TestCommandSet(t *testing.T) {
+	pager := newCommandTestPager(t)
+
+	typeCommand(pager, "set wrap")
+	assert.Assert(t, pager.WrapLongLines)
+
+	typeCommand(pager, "set nowrap")
+	assert.Assert(t, !pager.WrapLongLines)
+
+	typeCommand(pager, "set wrap!")
+	assert.Assert(t, pager.WrapLongLines)
+
+	typeCommand(pager, "set header-lines=2")
+	assert.Equal(t, pager.HeaderLines, 2)
+
+	typeCommand(pager, "set search-mode=literal")
+	assert.Equal(t, pager.SearchOptions.Regexp, RegexpModeOff)
+	assert.Equal(t, statusText(pager), "")
+
+	typeCommand(pager, "set header-lines=-1")
+	assert.Equal(t, statusText(pager), "Expected a number >= 0, got: -1")
+	assert.Equal(t, pager.HeaderLines, 2)
+
+	typeCommand(pager, "set wrap=3")
+	assert.Equal(t, statusText(pager), `Try "set wrap" or "set nowrap"`)
+}
+
+func TestCommandErrorInStatusBar(t *testing.T) {
+	pager := newCommandTestPager(t)
+	screen := pager.screen.(*twin.FakeScreen)
+
+	typeCommand(pager, "frobnicate")
+	pager.redraw("")
+	assert.Equal(t, rowToString(screen.GetRow(9)), "Unknown command: frobnicate")
+	assert.Equal(t, screen.GetRow(9)[0].Style, errorStyle)
+
+	// The next command that works clears the error
+	typeCommand(pager, "1")
+	assert.Equal(t, statusText(pager), "")
+}
+
+func TestCommandSaveAndOpen(t *testing.T) {
+	pager := newCommandTestPager(t)
+	pager.filterPattern = toPattern("o")
+	fileName := filepath.Join(t.TempDir(), "saved.txt")
+
+	typeCommand(pager, "save "+fileName)
+	assert.Equal(t, statusText(pager), "")
+
+	contents, err := os.ReadFile(fileName)
+	assert.NilError(t, err)
+	assert.Equal(t, string(contents), "second\nfourth\n")
+
+	// Don't overwrite
+	typeCommand(pager, "save "+fileName)
+	assert.Assert(t, strings.Contains(statusText(pager), "exists"), statusText(pager))
+
+	pager.filterPattern = nil
+	typeCommand(pager, "open "+fileName)
+	assert.Equal(t, statusText(pager), "")
+	assert.Equal(t, len(pager.buffers), 2)
+	assert.Equal(t, pager.currentBufferIndex, 1)
+	assert.NilError(t, pager.reader.Wait())
+	assert.Equal(t, pager.Reader().GetLineCount(), 2)
+}
+
+func TestCommandNameCompletion(t *testing.T) {
+	pager := newCommandTestPager(t)
+
+	pager.mode.onRune(':')
+	mode := pager.mode.(*PagerModeCommand)
+	mode.onRune('s')
+
+	// Three candidates, nothing more in common
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "s")
+
+	// Cycle through the candidates
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "set ")
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "save ")
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "style ")
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "set ")
+
+	mode.onRune('n')
+	mode.onRune('o')
+	mode.onRune('w')
+	mode.onRune('\t')
+	assert.Equal(t, mode.inputBox.String(), "set nowrap")
+}
+
+func TestCompleteFileName(t *testing.T) {
+	dir := t.TempDir()
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "file*.txt"), []byte{}, 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "fileA.txt"), []byte{}, 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, ".hidden"), []byte{}, 0o600))
+	assert.NilError(t, os.Mkdir(filepath.Join(dir, "folder"), 0o700))
+
+	prefix := dir + string(filepath.Separator)
+	assert.DeepEqual(t, completeFileName(nil, prefix+"f"), []string{
+		prefix + "file*.txt",
+		prefix + "fileA.txt",
+		prefix + "folder" + string(filepath.Separator),
+	})
+
+	// The * should not be a wildcard
+	assert.DeepEqual(t, completeFileName(nil, prefix+"file*"), []string{prefix + "file*.txt"})
+
+	assert.DeepEqual(t, completeFileName(nil, prefix+"."), []string{prefix + ".hidden"})
+}
+
+func TestCommonPrefix(t *testing.T) {
+	assert.Equal(t, commonPrefix([]string{"save ", "set "}), "s")
+	assert.Equal(t, commonPrefix([]string{"åäö", "åäx"}), "åä")
+	assert.Equal(t, commonPrefix([]string{"one"}), "one")
+	assert.Equal(t, commonPrefix(nil), "")
+}
+
+func TestCommandPipeFailure(t *testing.T) {
+	pager := newCommandTestPager(t)
+	events := make(chan twin.Event, 10)
+	pager.screen = eventsFakeScreen{FakeScreen: twin.NewFakeScreen(40, 10), events: events}
+
+	typeCommand(pager, "pipe exit 3")
+
+	// The output buffer opens even though the command failed, in any order
+	var message *eventStatusMessage
+	for range 2 {
+		if event, isMessage := (<-events).(eventStatusMessage); isMessage {
+			message = &event
+		}
+	}
+	assert.Assert(t, message != nil)
+	assert.Equal(t, message.level, statusMessageError)
+	assert.Equal(t, message.text, "Piping to exit 3 failed: exit status 3")
+}
@@ -0,0 +1,158 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/walles/moor/internal/linemetadata"
+)
+
+// Actions that use a count typed before them, like "10j" or "3G". Other actions
+// ignore the count.
+var countActions = map[string]func(p *Pager, count int){
+	"scroll-up": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.PreviousLine(count)
+		p.handleScrolledUp()
+	},
+	"scroll-down": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.NextLine(count)
+		p.handleScrolledDown()
+	},
+	"scroll-left": func(p *Pager, count int) {
+		p.moveRight(-count * p.SideScrollAmount)
+	},
+	"scroll-right": func(p *Pager, count int) {
+		p.moveRight(count * p.SideScrollAmount)
+	},
+	"scroll-left-one": func(p *Pager, count int) {
+		p.moveRight(-count)
+	},
+	"scroll-right-one": func(p *Pager, count int) {
+		p.moveRight(count)
+	},
+	"page-up": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.PreviousLine(countLines(count, p.visibleHeight()))
+		p.handleScrolledUp()
+	},
+	"page-down": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.NextLine(countLines(count, p.visibleHeight()))
+		p.handleScrolledDown()
+	},
+	"half-page-up": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.PreviousLine(countLines(count, p.visibleHeight()) / 2)
+		p.handleScrolledUp()
+	},
+	"half-page-down": func(p *Pager, count int) {
+		p.scrollPosition = p.scrollPosition.NextLine(countLines(count, p.visibleHeight()) / 2)
+		p.handleScrolledDown()
+	},
+
+	// Just like in less, these go to the given line number
+	"go-to-start": goToLineCount,
+	"go-to-end":   goToLineCount,
+	"go-to-line":  goToLineCount,
+
+	"next-heading":     (*Pager).scrollToNextHeading,
+	"previous-heading": (*Pager).scrollToPreviousHeading,
+	"next-diff-file": func(p *Pager, count int) {
+		p.scrollToNextDiffMarker(true, count)
+	},
+	"previous-diff-file": func(p *Pager, count int) {
+		p.scrollToPreviousDiffMarker(true, count)
+	},
+	"next-hunk": func(p *Pager, count int) {
+		p.scrollToNextDiffMarker(false, count)
+	},
+	"previous-hunk": func(p *Pager, count int) {
+		p.scrollToPreviousDiffMarker(false, count)
+	},
+	"next-mark":               (*Pager).scrollToNextMark,
+	"previous-mark":           (*Pager).scrollToPreviousMark,
+	"next-prompt":             (*Pager).scrollToNextPrompt,
+	"previous-prompt":         (*Pager).scrollToPreviousPrompt,
+	"next-command-output":     (*Pager).scrollToNextCommandOutput,
+	"previous-command-output": (*Pager).scrollToPreviousCommandOutput,
+
+	"search-next":     (*Pager).scrollToNthNextSearchHit,
+	"search-previous": (*Pager).scrollToNthPreviousSearchHit,
+}
+
+// Longer counts are not useful for anything
+const maxCountDigits = 9
+
+// Scrolling further than this is the same as scrolling to the end, for any
+// input we can hold in memory. Low enough that adding it to a scroll position
+// can't overflow, even with 32 bit ints.
+const maxCountLines = math.MaxInt32 / 4
+
+// How many lines to scroll for count times linesPerCount lines, without
+// overflowing
+func countLines(count int, linesPerCount int) int {
+	if linesPerCount > 0 && count > maxCountLines/linesPerCount {
+		return maxCountLines
+	}
+	return min(count*linesPerCount, maxCountLines)
+}
+
+// Add one typed digit to the pending count
+func (p *Pager) addCountDigit(digit rune) {
+	if len(p.pendingCount) >= maxCountDigits {
+		return
+	}
+	p.pendingCount += string(digit)
+}
+
+// Use and clear the pending count. Returns false if there is no count.
+func (p *Pager) takeCount() (int, bool) {
+	if p.pendingCount == "" {
+		return 0, false
+	}
+
+	count, err := strconv.Atoi(p.pendingCount)
+	if err != nil {
+		panic(fmt.Errorf("count should always be a number: %s", p.pendingCount))
+	}
+	p.pendingCount = ""
+	return count, true
+}
+
+// Do an action, using and clearing the pending count
+func (p *Pager) doAction(action *action) {
+	count, hasCount := p.takeCount()
+
+	withCount := countActions[action.name]
+	if hasCount && count > 0 && withCount != nil {
+		withCount(p, count)
+		return
+	}
+
+	action.do(p)
+}
+
+func goToLineCount(p *Pager, count int) {
+	p.goToLine(linemetadata.IndexFromOneBased(count))
+}
+
+// Go to a line, or as close to it as the currently available lines allow.
+// Lines arriving later will take us the rest of the way.
+func (p *Pager) goToLine(lineIndex linemetadata.Index) {
+	p.rememberJump()
+	p.scrollPosition = NewScrollPositionFromIndex(lineIndex, "goToLine")
+	p.setTargetLine(&lineIndex)
+}
+
+// Go to a percentage of the way through the lines we have. 100% is the last
+// line.
+func (p *Pager) goToPercent(percent int) {
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 {
+		return
+	}
+
+	percent = min(percent, 100)
+	lineIndex := linemetadata.IndexFromZeroBased(min(lineCount*percent/100, lineCount-1))
+	p.rememberJump()
+	p.scrollPosition = NewScrollPositionFromIndex(lineIndex, "goToPercent")
+	p.setTargetLine(nil)
+}
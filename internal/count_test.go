@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// 100 lines, "1" to "100", 9 lines visible
+func newCountTestPager(t *testing.T) *Pager {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestCount", numberLines(100)), 20, 10)
+	return pager
+}
+
+func typeRunes(pager *Pager, runes string) {
+	for _, char := range runes {
+		pager.mode.onRune(char)
+	}
+}
+
+func TestCountScrollDown(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "10j")
+	assert.Equal(t, pager.lineIndex().Index(), 10)
+	assert.Equal(t, pager.pendingCount, "")
+
+	// No count, one line
+	typeRunes(pager, "j")
+	assert.Equal(t, pager.lineIndex().Index(), 11)
+
+	pager.mode.onRune('5')
+	pager.mode.onKey(twin.KeyUp)
+	assert.Equal(t, pager.lineIndex().Index(), 6)
+}
+
+func TestCountPageDown(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "2f")
+	assert.Equal(t, pager.lineIndex().Index(), 18)
+}
+
+func TestCountGoToLine(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "30G")
+	assert.Equal(t, pager.lineIndex().Index(), 29)
+
+	typeRunes(pager, "3<")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+}
+
+func TestCountPercent(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "50%")
+	assert.Equal(t, pager.lineIndex().Index(), 50)
+
+	typeRunes(pager, "0%")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+
+	// Past the end, clipped to the last screen
+	typeRunes(pager, "100%")
+	assert.Equal(t, pager.lineIndex().Index(), 91)
+
+	// No count, nothing happens
+	typeRunes(pager, "%")
+	assert.Equal(t, pager.lineIndex().Index(), 91)
+}
+
+func TestCountSearchNext(t *testing.T) {
+	pager := newCountTestPager(t)
+	pager.searchPattern = toPattern("5")
+
+	// Hits are on lines 5, 15, 25, 35... The one on line 5 is already on
+	// screen, so the first hit we go to is on line 15.
+	typeRunes(pager, "3n")
+	assert.Equal(t, pager.lineIndex().Index(), 34)
+
+	// One keypress, one jump
+	assert.Equal(t, len(pager.jumpHistory.positions), 1)
+	assert.Equal(t, pager.jumpHistory.positions[0].lineIndex(pager).Index(), 0)
+
+	// Past the last hit, stop there rather than wrapping around
+	typeRunes(pager, "99n")
+	assert.Equal(t, pager.lineIndex().Index(), 91)
+	assert.Equal(t, len(pager.jumpHistory.positions), 2)
+
+	typeRunes(pager, "2N")
+	assert.Equal(t, pager.lineIndex().Index(), 74)
+}
+
+func TestCountNextMark(t *testing.T) {
+	pager := newCountTestPager(t)
+	pager.marks = make(map[rune]scrollPosition)
+	for _, line := range []int{20, 30, 40} {
+		pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(line), "TestCountNextMark")
+		pager.setMark(rune('a' + line/10))
+	}
+	pager.scrollPosition = newScrollPosition("TestCountNextMark")
+
+	typeRunes(pager, "2`")
+	assert.Equal(t, pager.lineIndex().Index(), 30)
+	assert.Equal(t, len(pager.jumpHistory.positions), 1)
+
+	// Fewer marks than the count, go to the last one
+	typeRunes(pager, "5`")
+	assert.Equal(t, pager.lineIndex().Index(), 40)
+}
+
+func TestCountPageDownDoesNotOverflow(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "999999999f")
+	assert.Equal(t, pager.lineIndex().Index(), 91)
+
+	typeRunes(pager, "999999999b")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+}
+
+func TestCountLines(t *testing.T) {
+	assert.Equal(t, countLines(3, 10), 30)
+	assert.Equal(t, countLines(999_999_999, 1000), maxCountLines)
+	assert.Equal(t, countLines(maxCountLines, 1), maxCountLines)
+}
+
+func TestCountEscapeCancels(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "12")
+	assert.Equal(t, pager.pendingCount, "12")
+
+	screen := twin.NewFakeScreen(80, 10)
+	pager.screen = screen
+	pager.redraw("")
+	assert.Assert(t, strings.HasSuffix(rowToString(screen.GetRow(9)), "Count: 12"))
+
+	// ESC should forget the count rather than quit
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, pager.pendingCount, "")
+	assert.Assert(t, !pager.quit)
+
+	typeRunes(pager, "j")
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+}
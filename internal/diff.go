@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"regexp"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+)
+
+// Matches all lines of a unified diff except for unchanged context lines.
+//
+// Context lines start with a single space. Commit message lines in "git log -p"
+// output start with four spaces, those we want to keep. Context lines of code
+// indented by exactly three spaces will be kept as well, but hiding commit
+// messages would be worse.
+var diffChangesPattern = regexp.MustCompile(`^([^ ]|    \S)`)
+
+// File headers, or hunk headers, in what we're currently showing, with indices
+// into p.Reader(). While filtering, only markers matching the filter are
+// included.
+func (p *Pager) diffMarkers(fileHeaders bool) []reader.DiffMarker {
+	if p.isShowingHelp {
+		return nil
+	}
+
+	filteredIndices := p.filteringReader.filteredIndices()
+
+	markers := []reader.DiffMarker{}
+	for _, marker := range p.reader.DiffMarkers() {
+		if marker.IsFileHeader != fileHeaders {
+			continue
+		}
+
+		if filteredIndices != nil {
+			index, found := filteredIndices[marker.Index.Index()]
+			if !found {
+				continue
+			}
+			marker.Index = index
+		}
+
+		markers = append(markers, marker)
+	}
+
+	return markers
+}
+
+func (p *Pager) isDiff() bool {
+	return !p.isShowingHelp && len(p.reader.DiffMarkers()) > 0
+}
+
+// Scroll so that the count-th next file header, or hunk header, is at the top
+func (p *Pager) scrollToNextDiffMarker(fileHeaders bool, count int) {
+	if !p.isDiff() {
+		p.showStatusMessage(statusMessageInfo, "Not a diff")
+		return
+	}
+
+	if p.scrollToNextOf(p.diffMarkerLines(fileHeaders), count, "scrollToNextDiffMarker") {
+		return
+	}
+
+	if fileHeaders {
+		p.showStatusMessage(statusMessageInfo, "No more files below")
+	} else {
+		p.showStatusMessage(statusMessageInfo, "No more hunks below")
+	}
+}
+
+// Scroll so that the count-th previous file header, or hunk header, is at the
+// top
+func (p *Pager) scrollToPreviousDiffMarker(fileHeaders bool, count int) {
+	if !p.isDiff() {
+		p.showStatusMessage(statusMessageInfo, "Not a diff")
+		return
+	}
+
+	if p.scrollToPreviousOf(p.diffMarkerLines(fileHeaders), count, "scrollToPreviousDiffMarker") {
+		return
+	}
+
+	if fileHeaders {
+		p.showStatusMessage(statusMessageInfo, "No more files above")
+	} else {
+		p.showStatusMessage(statusMessageInfo, "No more hunks above")
+	}
+}
+
+func (p *Pager) diffMarkerLines(fileHeaders bool) []linemetadata.Index {
+	lines := []linemetadata.Index{}
+	for _, marker := range p.diffMarkers(fileHeaders) {
+		lines = append(lines, marker.Index)
+	}
+	return lines
+}
+
+// The name of the file being changed at the top of the screen, or an empty
+// string if this isn't a diff
+func (p *Pager) currentDiffFileName() string {
+	if !p.isDiff() || p.lineIndex() == nil {
+		return ""
+	}
+
+	line := p.Reader().GetLine(*p.lineIndex())
+	if line == nil {
+		return ""
+	}
+	topIndex := line.Number.AsZeroBased()
+
+	fileName := ""
+	for _, marker := range p.reader.DiffMarkers() {
+		if marker.Index.Index() > topIndex {
+			break
+		}
+		fileName = marker.FileName
+	}
+
+	return fileName
+}
+
+// Hide unchanged context lines, or show them again
+func (p *Pager) toggleDiffChangesFilter() {
+	if !p.isDiff() {
+		p.showStatusMessage(statusMessageInfo, "Not a diff")
+		return
+	}
+
+	p.changeFilterKeepingPosition(func() {
+		p.onlyChanges = !p.onlyChanges
+	})
+}
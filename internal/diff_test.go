@@ -0,0 +1,121 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// Two files, the first one with two hunks
+func newDiffTestPager(t *testing.T) *Pager {
+	reader := reader.NewFromTextForTesting("git diff", strings.Join([]string{
+		"diff --git a/one.txt b/one.txt",
+		"--- a/one.txt",
+		"+++ b/one.txt",
+		"@@ -1,3 +1,3 @@",
+		" context",
+		"-old",
+		"+new",
+		"@@ -10,3 +10,3 @@",
+		" context",
+		"-old",
+		"+new",
+		"diff --git a/two.txt b/two.txt",
+		"--- a/two.txt",
+		"+++ b/two.txt",
+		"@@ -1,2 +1,2 @@",
+		"-old",
+		"+new",
+		" context",
+	}, "\n"))
+	pager, _ := newTestPager(t, reader, 80, 5)
+	return pager
+}
+
+func TestDiffNavigation(t *testing.T) {
+	pager := newDiffTestPager(t)
+
+	typeRunes(pager, ".")
+	assert.Equal(t, pager.lineIndex().Index(), 3)
+	typeRunes(pager, ".")
+	assert.Equal(t, pager.lineIndex().Index(), 7)
+
+	typeRunes(pager, "J")
+	assert.Equal(t, pager.lineIndex().Index(), 11)
+	typeRunes(pager, "K")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	typeRunes(pager, "K")
+	assert.Equal(t, statusText(pager), "No more files above")
+
+	typeRunes(pager, "3.")
+	assert.Equal(t, pager.lineIndex().Index(), 14)
+	typeRunes(pager, ",")
+	assert.Equal(t, pager.lineIndex().Index(), 7)
+}
+
+func TestDiffFileNameInStatusBar(t *testing.T) {
+	pager := newDiffTestPager(t)
+	typeRunes(pager, ".")
+
+	pager.redraw("")
+	assert.Assert(t, strings.HasPrefix(
+		rowToString(pager.screen.(*twin.FakeScreen).GetRow(4)),
+		"git diff: 18 lines  38%  one.txt  Press"))
+
+	typeRunes(pager, "J")
+	pager.redraw("")
+	assert.Assert(t, strings.Contains(rowToString(pager.screen.(*twin.FakeScreen).GetRow(4)), "  two.txt  "))
+}
+
+func TestDiffContextFilter(t *testing.T) {
+	pager := newDiffTestPager(t)
+	typeRunes(pager, "J")
+
+	typeRunes(pager, "C")
+	assert.Equal(t, pager.Reader().GetLineCount(), 15)
+
+	// Still showing the second file
+	assert.Equal(t, pager.Reader().GetLine(*pager.lineIndex()).Plain(), "diff --git a/two.txt b/two.txt")
+
+	// Hunk navigation while filtering
+	typeRunes(pager, ",")
+	assert.Equal(t, pager.Reader().GetLine(*pager.lineIndex()).Plain(), "@@ -10,3 +10,3 @@")
+
+	typeRunes(pager, "C")
+	assert.Equal(t, pager.Reader().GetLineCount(), 18)
+	assert.Equal(t, pager.lineIndex().Index(), 7)
+}
+
+func TestDiffContextFilterKeepsUserFilter(t *testing.T) {
+	pager := newDiffTestPager(t)
+	searchPattern := toPattern("new")
+	filterPattern := toPattern("o")
+	pager.searchString = "new"
+	pager.searchPattern = searchPattern
+	pager.filterPattern = filterPattern
+	assert.Equal(t, pager.Reader().GetLineCount(), 12)
+
+	// Both filters apply, and the search is still there
+	typeRunes(pager, "C")
+	assert.Equal(t, pager.Reader().GetLineCount(), 9)
+	assert.Equal(t, pager.searchString, "new")
+	assert.Equal(t, pager.searchPattern, searchPattern)
+
+	// Toggling off leaves the user's filter alone
+	typeRunes(pager, "C")
+	assert.Equal(t, pager.Reader().GetLineCount(), 12)
+	assert.Equal(t, pager.searchString, "new")
+	assert.Equal(t, pager.searchPattern, searchPattern)
+	assert.Equal(t, pager.filterPattern, filterPattern)
+}
+
+func TestNotADiff(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "J")
+	assert.Equal(t, statusText(pager), "Not a diff")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+}
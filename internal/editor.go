@@ -5,12 +5,16 @@ import (
 	"math"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"runtime"
+	"strconv"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/walles/moor/internal/linemetadata"
 	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/internal/textstyles"
+	"github.com/walles/moor/twin"
 )
 
 // Dump the reader lines into a read-only temp file and return the absolute file
@@ -74,7 +78,7 @@ func pickAnEditor() (string, string, error) {
 	editorEnv := "VISUAL"
 	editor := strings.TrimSpace(os.Getenv(editorEnv))
 	if editor == "" {
-		editorEnv := "EDITOR"
+		editorEnv = "EDITOR"
 		editor = strings.TrimSpace(os.Getenv(editorEnv))
 	}
 
@@ -107,11 +111,11 @@ func pickAnEditor() (string, string, error) {
 	return "", "", fmt.Errorf("No editor found, tried: $VISUAL, $EDITOR, %s", strings.Join(candidates, ", "))
 }
 
-func handleEditingRequest(p *Pager) {
+// Find an editor we can run. The error is for showing to the user.
+func findEditor() (string, error) {
 	editor, editorEnv, err := pickAnEditor()
 	if err != nil {
-		log.Warn("Failed to find an editor: ", err)
-		return
+		return "", err
 	}
 
 	// Tyre kicking check that we can find the editor either in the PATH or as
@@ -119,18 +123,68 @@ func handleEditingRequest(p *Pager) {
 	firstWord := strings.Fields(editor)[0]
 	editorPath, err := exec.LookPath(firstWord)
 	if err != nil {
-		// FIXME: Show a message in the status bar instead? Nothing wrong with
-		// moor here.
-		log.Warn("Failed to find editor "+firstWord+" from $"+editorEnv+": ", err)
-		return
+		return "", fmt.Errorf("Editor %s from $%s not found: %w", firstWord, editorEnv, err)
 	}
 
 	// Check that the editor is executable
 	err = errUnlessExecutable(editorPath)
 	if err != nil {
-		// FIXME: Show a message in the status bar instead? Nothing wrong with
-		// moor here.
-		log.Warn("Editor from {} not executable: {}", editorEnv, err)
+		return "", fmt.Errorf("Editor from $%s not usable: %w", editorEnv, err)
+	}
+
+	return editor, nil
+}
+
+// The editor command line for opening a file at some line and column. Zero
+// line and column means no position, and zero column means only the line.
+//
+// Editors disagree on how to say where to go, these are the ones we know about.
+func editorCommandLine(editor string, file string, line int, column int) []string {
+	commandWithArgs := strings.Fields(editor)
+	if line <= 0 {
+		return append(commandWithArgs, file)
+	}
+
+	name := strings.ToLower(filepath.Base(commandWithArgs[0]))
+	name = strings.TrimSuffix(name, ".exe")
+
+	position := strconv.Itoa(line)
+	switch name {
+	case "code", "code-insiders", "codium", "cursor", "windsurf":
+		// VS Code and friends: "code -g file:line:column"
+		if column > 0 {
+			position += ":" + strconv.Itoa(column)
+		}
+		return append(commandWithArgs, "-g", file+":"+position)
+
+	case "subl", "sublime_text", "zed", "hx", "helix":
+		// "subl file:line:column"
+		if column > 0 {
+			position += ":" + strconv.Itoa(column)
+		}
+		return append(commandWithArgs, file+":"+position)
+
+	case "emacs", "emacsclient", "micro", "kak":
+		// "emacs +line:column file"
+		if column > 0 {
+			position += ":" + strconv.Itoa(column)
+		}
+
+	case "nano":
+		// "nano +line,column file"
+		if column > 0 {
+			position += "," + strconv.Itoa(column)
+		}
+	}
+
+	// vi, vim, nvim, nano, emacs and most others accept "+line file"
+	return append(commandWithArgs, "+"+position, file)
+}
+
+func handleEditingRequest(p *Pager) {
+	editor, err := findEditor()
+	if err != nil {
+		p.showWarning(err.Error())
 		return
 	}
 
@@ -155,40 +209,170 @@ func handleEditingRequest(p *Pager) {
 		// Create a temp file based on reader contents
 		fileToEdit, err = dumpToTempFile(p.reader)
 		if err != nil {
-			log.Warn("Failed to create temp file to edit: ", err)
+			p.showError("Failed to create temp file to edit: " + err.Error())
 			return
 		}
 	}
 
-	p.AfterExit = func() error {
-		// NOTE: If you do any changes here, make sure they work with both "nano"
-		// and "code -w" (VSCode).
-		commandWithArgs := strings.Fields(editor)
-		commandWithArgs = append(commandWithArgs, fileToEdit)
-
-		log.Info("'v' pressed, launching editor: ", commandWithArgs)
-		command := exec.Command(commandWithArgs[0], commandWithArgs[1:]...)
-
-		if runtime.GOOS == "windows" {
-			// Don't touch command.Stdin on Windows:
-			// https://github.com/walles/moor/issues/281#issuecomment-2953384726
-		} else {
-			// Since os.Stdin might come from a pipe, we can't trust that. Instead,
-			// we tell the editor to read from os.Stdout, which points to the
-			// terminal as well.
-			//
-			// Tested on macOS and Linux, works like a charm.
-			command.Stdin = os.Stdout // <- YES, WE SHOULD ASSIGN STDOUT TO STDIN
+	launchEditor(p, editor, fileToEdit, p.lineNumberToEdit(), 0)
+}
+
+// Which line to open the editor at. That's the current search hit if it's on
+// screen, otherwise the top line. Zero means no particular line.
+func (p *Pager) lineNumberToEdit() int {
+	if p.isShowingHelp {
+		// Line numbers are for the help text, not for the file
+		return 0
+	}
+
+	lineIndex := p.lineIndex()
+	hit := p.currentSearchHit
+	if hit != nil && p.isLineVisible(hit.lineIndex) {
+		lineIndex = &hit.lineIndex
+	}
+	if lineIndex == nil {
+		return 0
+	}
+
+	line := p.Reader().GetLine(*lineIndex)
+	if line == nil {
+		return 0
+	}
+	return line.Number.AsOneBased()
+}
+
+// Edit the file a "file.go:123" style reference points to, at that line
+func handleFileReferenceEditingRequest(p *Pager, reference textstyles.FileReference) {
+	fileToEdit := p.resolveFileReference(reference.Path)
+	if fileToEdit == "" {
+		p.showWarning("File not found: " + reference.Path)
+		return
+	}
+
+	editor, err := findEditor()
+	if err != nil {
+		p.showWarning(err.Error())
+		return
+	}
+
+	launchEditor(p, editor, fileToEdit, reference.Line, reference.Column)
+}
+
+// Find the file a file reference points to. Relative paths are tried relative
+// to the current directory first, and then relative to the directory of the
+// file we're viewing. Returns an empty string if the file can't be found.
+func (p *Pager) resolveFileReference(path string) string {
+	candidates := []string{path}
+	if !filepath.IsAbs(path) && p.reader.FileName != nil {
+		candidates = append(candidates, filepath.Join(filepath.Dir(*p.reader.FileName), path))
+	}
+
+	for _, candidate := range candidates {
+		stat, err := os.Stat(candidate)
+		if err != nil || stat.IsDir() {
+			log.Debug("File reference ", path, " is not ", candidate, ": ", err)
+			continue
+		}
+
+		return candidate
+	}
+
+	return ""
+}
+
+// Open the file in the editor, and come back to the pager when the editor
+// exits. Zero line and column means no position, see editorCommandLine().
+func launchEditor(p *Pager, editor string, fileToEdit string, line int, column int) {
+	// NOTE: If you do any changes here, make sure they work with both "nano"
+	// and "code -w" (VSCode).
+	commandWithArgs := editorCommandLine(editor, fileToEdit, line, column)
+
+	screen, canSuspend := p.terminalScreen().(twin.SuspendableScreen)
+	if runtime.GOOS == "windows" || !canSuspend {
+		// On Windows, the screen can't stop reading key presses until it gets
+		// one more, so we can't suspend it. Some screens can't suspend at all.
+		// Quit and edit instead.
+		p.AfterExit = func() error {
+			return runEditor(commandWithArgs)
 		}
+		p.Quit()
+		return
+	}
 
-		command.Stdout = os.Stdout
-		command.Stderr = os.Stderr
+	statBefore, statErr := os.Stat(fileToEdit)
 
-		err := command.Run()
-		if err == nil {
-			log.Info("Editor exited successfully: ", commandWithArgs)
+	screen.Suspend()
+	err := runEditor(commandWithArgs)
+	resumeErr := screen.Resume()
+	if resumeErr != nil {
+		log.Error("Failed to resume paging after editing: ", resumeErr)
+		p.Quit()
+		return
+	}
+	if err != nil {
+		p.showError("Editor failed: " + err.Error())
+	}
+
+	statAfter, err := os.Stat(fileToEdit)
+	if statErr != nil || err != nil {
+		return
+	}
+	if statAfter.ModTime().Equal(statBefore.ModTime()) && statAfter.Size() == statBefore.Size() {
+		return
+	}
+
+	p.reloadEditedFile(fileToEdit)
+}
+
+func runEditor(commandWithArgs []string) error {
+	log.Info("Launching editor: ", commandWithArgs)
+	command := exec.Command(commandWithArgs[0], commandWithArgs[1:]...)
+
+	if runtime.GOOS == "windows" {
+		// Don't touch command.Stdin on Windows:
+		// https://github.com/walles/moor/issues/281#issuecomment-2953384726
+	} else {
+		// Since os.Stdin might come from a pipe, we can't trust that. Instead,
+		// we tell the editor to read from os.Stdout, which points to the
+		// terminal as well.
+		//
+		// Tested on macOS and Linux, works like a charm.
+		command.Stdin = os.Stdout // <- YES, WE SHOULD ASSIGN STDOUT TO STDIN
+	}
+
+	command.Stdout = os.Stdout
+	command.Stderr = os.Stderr
+
+	err := command.Run()
+	if err == nil {
+		log.Info("Editor exited successfully: ", commandWithArgs)
+	}
+	return err
+}
+
+// Read all buffers showing the edited file again, keeping their positions
+func (p *Pager) reloadEditedFile(editedFile string) {
+	for index, b := range p.buffers {
+		if b.reader.FileName == nil || !isSameFile(*b.reader.FileName, editedFile) {
+			continue
 		}
-		return err
+
+		log.Info("Reloading edited file: ", *b.reader.FileName)
+		err := p.reloadBuffer(index)
+		if err != nil {
+			p.showError("Reloading failed: " + err.Error())
+		}
+	}
+}
+
+func isSameFile(a string, b string) bool {
+	statA, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	statB, err := os.Stat(b)
+	if err != nil {
+		return false
 	}
-	p.Quit()
+	return os.SameFile(statA, statB)
 }
@@ -0,0 +1,111 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestEditorCommandLine(t *testing.T) {
+	assert.DeepEqual(t, editorCommandLine("vim", "x.go", 0, 0), []string{"vim", "x.go"})
+	assert.DeepEqual(t, editorCommandLine("vim", "x.go", 12, 5), []string{"vim", "+12", "x.go"})
+	assert.DeepEqual(t, editorCommandLine("/usr/bin/nano", "x.go", 12, 5), []string{"/usr/bin/nano", "+12,5", "x.go"})
+	assert.DeepEqual(t, editorCommandLine("emacs -nw", "x.go", 12, 5), []string{"emacs", "-nw", "+12:5", "x.go"})
+	assert.DeepEqual(t, editorCommandLine("code -w", "x.go", 12, 0), []string{"code", "-w", "-g", "x.go:12"})
+	assert.DeepEqual(t, editorCommandLine("Code.exe", "x.go", 12, 5), []string{"Code.exe", "-g", "x.go:12:5"})
+	assert.DeepEqual(t, editorCommandLine("subl", "x.go", 12, 0), []string{"subl", "x.go:12"})
+}
+
+func TestResolveFileReference(t *testing.T) {
+	dir := t.TempDir()
+	viewed := filepath.Join(dir, "build.log")
+	assert.NilError(t, os.WriteFile(viewed, []byte("main.go:1: oops\n"), 0o600))
+	assert.NilError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o600))
+
+	reader, err := reader.NewFromFilename(viewed, formatters.TTY16m, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	pager := NewPager(reader)
+
+	assert.Equal(t, pager.resolveFileReference("main.go"), filepath.Join(dir, "main.go"))
+	assert.Equal(t, pager.resolveFileReference("nonexistent.go"), "")
+}
+
+func TestLineNumberToEdit(t *testing.T) {
+	pager := newCountTestPager(t)
+	assert.Equal(t, pager.lineNumberToEdit(), 1)
+
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(40), "test")
+	assert.Equal(t, pager.lineNumberToEdit(), 41)
+
+	// Search hits on screen win over the top line
+	pager.searchPattern = toPattern("44")
+	pager.currentSearchHit = &searchHit{lineIndex: linemetadata.IndexFromZeroBased(43), runeRange: [2]int{0, 2}}
+	assert.Equal(t, pager.lineNumberToEdit(), 44)
+
+	// Not on screen any more
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(80), "test")
+	assert.Equal(t, pager.lineNumberToEdit(), 81)
+
+	// While filtering, we want the line number in the file
+	pager.currentSearchHit = nil
+	pager.filterPattern = toPattern("5")
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(1), "test")
+	assert.Equal(t, pager.lineNumberToEdit(), 15)
+}
+
+func TestEditAndReload(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("We quit rather than suspend on Windows")
+	}
+
+	dir := t.TempDir()
+	fileName := filepath.Join(dir, "edit-me.txt")
+	assert.NilError(t, os.WriteFile(fileName, []byte("one\n"), 0o600))
+
+	// Pretend to be an editor, log the arguments and change the file
+	editor := filepath.Join(dir, "editor.sh")
+	argsFile := filepath.Join(dir, "args.txt")
+	assert.NilError(t, os.WriteFile(editor, []byte("#!/bin/sh\necho \"$@\" > "+argsFile+"\necho two >> \"$2\"\n"), 0o700))
+
+	r, err := reader.NewFromFilename(fileName, formatters.TTY16m, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	pager, _ := newTestPager(t, r, 40, 10)
+
+	launchEditor(pager, editor, fileName, 1, 0)
+	assert.Equal(t, statusText(pager), "")
+	assert.Assert(t, pager.statusMessage == nil)
+	assert.Assert(t, !pager.quit)
+
+	args, err := os.ReadFile(argsFile)
+	assert.NilError(t, err)
+	assert.Equal(t, string(args), "+1 "+fileName+"\n")
+
+	// The file should have been reloaded
+	assert.Assert(t, pager.reader != r)
+	assert.NilError(t, pager.reader.Wait())
+	assert.Equal(t, pager.Reader().GetLineCount(), 2)
+}
+
+// A screen that can't give the terminal to anyone else
+type unsuspendableScreen struct {
+	twin.Screen
+}
+
+func TestEditWithoutSuspending(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("TestEditor", "one"))
+	pager.screen = unsuspendableScreen{Screen: twin.NewFakeScreen(40, 10)}
+
+	launchEditor(pager, "true", "file.txt", 1, 0)
+
+	// The editor should be launched after quitting instead
+	assert.Assert(t, pager.quit)
+	assert.Assert(t, pager.AfterExit != nil)
+}
@@ -0,0 +1,325 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+)
+
+// Don't let the file history grow forever
+const maxFileHistoryEntries = 500
+
+// When to restore the state of previously viewed files
+type RememberMode int
+
+const (
+	RememberNever RememberMode = iota
+	RememberAlways
+
+	// Only restore the state if the file has the same size and modification
+	// time as when we last saw it
+	RememberIfUnchanged
+)
+
+// What we remember about a file between runs. Stored as one JSON object per
+// line in the file history, most recent last.
+type fileHistoryEntry struct {
+	Path    string    `json:"path"`
+	Size    int64     `json:"size"`
+	ModTime time.Time `json:"mtime"`
+
+	// Zero based index of the top line on screen
+	LineIndex int `json:"line"`
+
+	// Zero based line indices by mark character
+	Marks map[string]int `json:"marks,omitempty"`
+
+	SearchString  string `json:"search,omitempty"`
+	SearchPattern string `json:"searchPattern,omitempty"`
+	FilterPattern string `json:"filter,omitempty"`
+
+	// How SearchString was turned into SearchPattern. nil in entries saved
+	// before we started storing this.
+	SearchOptions *SearchOptions `json:"searchOptions,omitempty"`
+}
+
+// Empty if we have nowhere to store the file history
+func fileHistoryPath() string {
+	stateHome, err := xdgStateHome()
+	if err != nil {
+		log.Info("No state directory found, file positions won't be remembered: ", err)
+		return ""
+	}
+
+	return filepath.Join(stateHome, "moor", "file_history")
+}
+
+// Returns nil on failure
+func readFileHistory(historyPath string) []fileHistoryEntry {
+	contents, err := os.ReadFile(historyPath)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Info("Failed to read file history ", historyPath, ": ", err)
+		}
+		return nil
+	}
+
+	entries := make([]fileHistoryEntry, 0)
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	for scanner.Scan() {
+		var entry fileHistoryEntry
+		err := json.Unmarshal(scanner.Bytes(), &entry)
+		if err != nil {
+			log.Info("Skipping broken file history line in ", historyPath, ": ", err)
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries
+}
+
+func writeFileHistory(historyPath string, entries []fileHistoryEntry) {
+	var contents bytes.Buffer
+	for _, entry := range entries {
+		line, err := json.Marshal(entry)
+		if err != nil {
+			log.Info("Failed to encode file history entry for ", entry.Path, ": ", err)
+			continue
+		}
+		contents.Write(line)
+		contents.WriteByte('\n')
+	}
+
+	err := os.MkdirAll(filepath.Dir(historyPath), 0o700)
+	if err != nil {
+		log.Info("Failed to create file history directory for ", historyPath, ": ", err)
+		return
+	}
+
+	err = os.WriteFile(historyPath, contents.Bytes(), 0o600)
+	if err != nil {
+		log.Info("Failed to write file history ", historyPath, ": ", err)
+	}
+}
+
+// Identifies the file shown by a reader. Returns false for non-files, like
+// stdin.
+func readerFileInfo(r *reader.ReaderImpl) (string, os.FileInfo, bool) {
+	if r == nil || r.FileName == nil {
+		return "", nil, false
+	}
+
+	absPath, err := filepath.Abs(*r.FileName)
+	if err != nil {
+		log.Debug("Failed to get absolute path for ", *r.FileName, ": ", err)
+		return "", nil, false
+	}
+
+	stat, err := os.Stat(absPath)
+	if err != nil {
+		log.Debug("Failed to stat ", absPath, ": ", err)
+		return "", nil, false
+	}
+
+	return absPath, stat, true
+}
+
+// Restore the state of all buffers from the file history. Call before the
+// main loop starts.
+func (p *Pager) restoreFileStates() {
+	if p.Remember == RememberNever {
+		return
+	}
+
+	historyPath := fileHistoryPath()
+	if historyPath == "" {
+		return
+	}
+	entries := readFileHistory(historyPath)
+
+	for bufferIndex, b := range p.buffers {
+		absPath, stat, isFile := readerFileInfo(b.reader)
+		if !isFile {
+			continue
+		}
+
+		var entry *fileHistoryEntry
+		for i := range entries {
+			if entries[i].Path == absPath {
+				entry = &entries[i]
+			}
+		}
+		if entry == nil {
+			continue
+		}
+
+		if p.Remember == RememberIfUnchanged && (entry.Size != stat.Size() || !entry.ModTime.Equal(stat.ModTime())) {
+			log.Debug("Not restoring state for changed file ", absPath)
+			continue
+		}
+
+		log.Debug("Restoring state for ", absPath)
+		p.restoreFileState(bufferIndex, *entry)
+	}
+}
+
+func (p *Pager) restoreFileState(bufferIndex int, entry fileHistoryEntry) {
+	marks := make(map[rune]scrollPosition)
+	for mark, lineIndex := range entry.Marks {
+		if len([]rune(mark)) != 1 || lineIndex < 0 {
+			continue
+		}
+		marks[[]rune(mark)[0]] = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(lineIndex), "Restored mark")
+	}
+
+	var targetLine *linemetadata.Index
+	if entry.LineIndex > 0 {
+		index := linemetadata.IndexFromZeroBased(entry.LineIndex)
+		targetLine = &index
+	}
+
+	if bufferIndex != p.currentBufferIndex {
+		b := p.buffers[bufferIndex]
+		b.marks = marks
+		b.targetLine = targetLine
+		return
+	}
+
+	p.marks = marks
+
+	// The search and filter are shared between all buffers, so we only restore
+	// them for the current one
+	p.searchString = entry.SearchString
+	p.searchPattern = compileOrNil(entry.SearchPattern)
+	p.filterPattern = compileOrNil(entry.FilterPattern)
+	if entry.SearchOptions != nil && (p.searchPattern != nil || p.filterPattern != nil) {
+		// Editing the restored search should work like it did last time
+		p.SearchOptions = *entry.SearchOptions
+	}
+
+	if p.TargetLine != nil {
+		// Scroll position requested on the command line, that wins
+		return
+	}
+	p.setTargetLine(targetLine)
+}
+
+func compileOrNil(pattern string) *regexp.Regexp {
+	if pattern == "" {
+		return nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		log.Debug("Not restoring invalid pattern ", pattern, ": ", err)
+		return nil
+	}
+	return compiled
+}
+
+// Save the state of all buffers to the file history. Call after the main loop
+// is done.
+func (p *Pager) saveFileStates() {
+	if p.Remember == RememberNever {
+		return
+	}
+
+	newEntries := make([]fileHistoryEntry, 0, len(p.buffers))
+	for bufferIndex, b := range p.buffers {
+		absPath, stat, isFile := readerFileInfo(b.reader)
+		if !isFile {
+			continue
+		}
+
+		entry := fileHistoryEntry{
+			Path:    absPath,
+			Size:    stat.Size(),
+			ModTime: stat.ModTime(),
+			Marks:   make(map[string]int),
+		}
+
+		marks := b.marks
+		if bufferIndex == p.currentBufferIndex {
+			marks = p.marks
+
+			if p.isShowingHelp {
+				// Not canonicalized, since that would be done against the help
+				// text
+				lineIndex := p.preHelpState.scrollPosition.internalDontTouch.lineIndex
+				if lineIndex != nil {
+					entry.LineIndex = lineIndex.Index()
+				}
+			} else if p.lineIndex() != nil {
+				entry.LineIndex = p.lineIndex().Index()
+			}
+
+			entry.SearchString = p.searchString
+			searchOptions := p.SearchOptions
+			entry.SearchOptions = &searchOptions
+			if p.searchPattern != nil {
+				entry.SearchPattern = p.searchPattern.String()
+			}
+			if p.filterPattern != nil {
+				entry.FilterPattern = p.filterPattern.String()
+			}
+		} else if b.targetLine != nil && *b.targetLine != linemetadata.IndexMax() {
+			// Never scrolled to, maybe restored from history but never shown
+			entry.LineIndex = b.targetLine.Index()
+		} else if b.scrollPosition.internalDontTouch.lineIndex != nil {
+			// Not canonicalized, since that would require this buffer's
+			// reader to be the current one
+			entry.LineIndex = b.scrollPosition.internalDontTouch.lineIndex.Index()
+		}
+
+		for mark, position := range marks {
+			lineIndex := position.internalDontTouch.lineIndex
+			if lineIndex == nil {
+				continue
+			}
+			entry.Marks[string(mark)] = lineIndex.Index()
+		}
+
+		newEntries = append(newEntries, entry)
+	}
+
+	if len(newEntries) == 0 {
+		return
+	}
+
+	historyPath := fileHistoryPath()
+	if historyPath == "" {
+		return
+	}
+
+	// Other moor instances may have saved entries since we started, don't drop
+	// those
+	entries := make([]fileHistoryEntry, 0)
+	for _, existing := range readFileHistory(historyPath) {
+		replaced := false
+		for _, newEntry := range newEntries {
+			if newEntry.Path == existing.Path {
+				replaced = true
+				break
+			}
+		}
+		if !replaced {
+			entries = append(entries, existing)
+		}
+	}
+	entries = append(entries, newEntries...)
+
+	if len(entries) > maxFileHistoryEntries {
+		entries = entries[len(entries)-maxFileHistoryEntries:]
+	}
+
+	writeFileHistory(historyPath, entries)
+}
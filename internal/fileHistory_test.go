@@ -0,0 +1,78 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"gotest.tools/v3/assert"
+)
+
+func createFileHistoryPager(t *testing.T, filename string, remember RememberMode) *Pager {
+	fileReader, err := reader.NewFromFilename(filename, formatters.TTY16m, reader.ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+
+	pager, _ := newTestPager(t, fileReader, 20, 5)
+	pager.marks = make(map[rune]scrollPosition)
+	pager.Remember = remember
+
+	return pager
+}
+
+func TestFileHistoryRoundTrip(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	filename := filepath.Join(t.TempDir(), "numbers.txt")
+	assert.NilError(t, os.WriteFile(filename, []byte(strings.Repeat("line\n", 50)+"needle\n"), 0o600))
+
+	pager := createFileHistoryPager(t, filename, RememberAlways)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(20), "test")
+	pager.marks['a'] = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(7), "test")
+	pager.SearchOptions = SearchOptions{Regexp: RegexpModeOn, Case: CaseModeSensitive, WholeWord: true, Fold: true}
+	pager.searchString = "need.e"
+	pager.searchPattern = pager.SearchOptions.toPattern("need.e")
+	pager.saveFileStates()
+
+	restored := createFileHistoryPager(t, filename, RememberAlways)
+	restored.restoreFileStates()
+	assert.Equal(t, restored.TargetLine.Index(), 20)
+	assert.Equal(t, restored.marks['a'].internalDontTouch.lineIndex.Index(), 7)
+	assert.Equal(t, restored.searchString, "need.e")
+	assert.Equal(t, restored.searchPattern.String(), pager.searchPattern.String())
+	assert.Equal(t, restored.SearchOptions, pager.SearchOptions)
+	assert.Assert(t, restored.filterPattern == nil)
+
+	// When not remembering, nothing should be restored
+	notRestored := createFileHistoryPager(t, filename, RememberNever)
+	notRestored.restoreFileStates()
+	assert.Assert(t, notRestored.TargetLine == nil)
+}
+
+func TestFileHistoryIfUnchanged(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+
+	filename := filepath.Join(t.TempDir(), "numbers.txt")
+	assert.NilError(t, os.WriteFile(filename, []byte(strings.Repeat("line\n", 50)), 0o600))
+
+	pager := createFileHistoryPager(t, filename, RememberIfUnchanged)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(20), "test")
+	pager.saveFileStates()
+
+	unchanged := createFileHistoryPager(t, filename, RememberIfUnchanged)
+	unchanged.restoreFileStates()
+	assert.Equal(t, unchanged.TargetLine.Index(), 20)
+
+	// Change the file
+	assert.NilError(t, os.WriteFile(filename, []byte(strings.Repeat("line\n", 51)), 0o600))
+	assert.NilError(t, os.Chtimes(filename, time.Now(), time.Now().Add(time.Minute)))
+
+	changed := createFileHistoryPager(t, filename, RememberIfUnchanged)
+	changed.restoreFileStates()
+	assert.Assert(t, changed.TargetLine == nil)
+}
@@ -21,8 +21,18 @@ type FilteringReader struct {
 	// original pattern, including if it is set to nil.
 	FilterPattern **regexp.Regexp
 
-	// Protects filteredLinesCache, unfilteredLineCountWhenCaching, and
-	// filterPatternWhenCaching.
+	// If this points to true, only show lines where shell commands were
+	// typed, as recorded by OSC 133 prompt markers. May be nil.
+	OnlyCommands *bool
+
+	// If this points to true, hide unchanged diff context lines. May be nil.
+	OnlyChanges *bool
+
+	// The first this many lines are shown as sticky header lines, so they are
+	// left out while filtering. May be nil.
+	HeaderLines *int
+
+	// Protects filteredLinesCache and the *WhenCaching fields.
 	lock sync.Mutex
 
 	// nil means no filtering has happened yet
@@ -36,6 +46,15 @@ type FilteringReader struct {
 	// This is the pattern that was used when we cached the lines. If it
 	// doesn't match the current pattern, then our cache needs to be rebuilt.
 	filterPatternWhenCaching *regexp.Regexp
+
+	// This is what *OnlyCommands was when we cached the lines
+	onlyCommandsWhenCaching bool
+
+	// This is what *OnlyChanges was when we cached the lines
+	onlyChangesWhenCaching bool
+
+	// This is what headerLines() was when we cached the lines
+	headerLinesWhenCaching int
 }
 
 // Please hold the lock when calling this method.
@@ -48,16 +67,32 @@ func (f *FilteringReader) rebuildCache() {
 	// Mark cache base conditions
 	f.unfilteredLineCountWhenCaching = f.BackingReader.GetLineCount()
 	f.filterPatternWhenCaching = filterPattern
+	f.onlyCommandsWhenCaching = f.onlyCommands()
+	f.onlyChangesWhenCaching = f.onlyChanges()
+	f.headerLinesWhenCaching = f.headerLines()
 
 	// Repopulate the cache
 	allBaseLines := f.BackingReader.GetLines(linemetadata.Index{}, math.MaxInt)
 	resultIndex := 0
 	for _, line := range allBaseLines.Lines {
+		if line.Index.Index() < f.headerLinesWhenCaching {
+			// Already on screen as a header line
+			continue
+		}
+
 		if filterPattern != nil && len(filterPattern.String()) > 0 && !filterPattern.MatchString(line.Line.Plain(&line.Index)) {
 			// We have a pattern but it doesn't match
 			continue
 		}
 
+		if f.onlyCommandsWhenCaching && !line.Line.IsCommandLine() {
+			continue
+		}
+
+		if f.onlyChangesWhenCaching && !diffChangesPattern.MatchString(line.Line.Plain(&line.Index)) {
+			continue
+		}
+
 		cache = append(cache, &reader.NumberedLine{
 			Line:   line.Line,
 			Index:  linemetadata.IndexFromZeroBased(resultIndex),
@@ -99,6 +134,21 @@ func (f *FilteringReader) getAllLines() []*reader.NumberedLine {
 		return *f.filteredLinesCache
 	}
 
+	if f.onlyCommands() != f.onlyCommandsWhenCaching {
+		f.rebuildCache()
+		return *f.filteredLinesCache
+	}
+
+	if f.onlyChanges() != f.onlyChangesWhenCaching {
+		f.rebuildCache()
+		return *f.filteredLinesCache
+	}
+
+	if f.headerLines() != f.headerLinesWhenCaching {
+		f.rebuildCache()
+		return *f.filteredLinesCache
+	}
+
 	return *f.filteredLinesCache
 }
 
@@ -106,7 +156,8 @@ func (f *FilteringReader) shouldPassThrough() bool {
 	f.lock.Lock()
 	defer f.lock.Unlock()
 
-	if *f.FilterPattern == nil || len((*f.FilterPattern).String()) == 0 {
+	noPattern := *f.FilterPattern == nil || len((*f.FilterPattern).String()) == 0
+	if noPattern && !f.onlyCommands() && !f.onlyChanges() {
 		// Cache is not needed
 		f.filteredLinesCache = nil
 
@@ -117,6 +168,70 @@ func (f *FilteringReader) shouldPassThrough() bool {
 	return false
 }
 
+// Maps unfiltered line indices to filtered line indices, for the lines that
+// pass the filter. Returns nil if we aren't filtering.
+func (f *FilteringReader) filteredIndices() map[int]linemetadata.Index {
+	if f.shouldPassThrough() {
+		return nil
+	}
+
+	filteredIndices := make(map[int]linemetadata.Index)
+	for _, line := range f.getAllLines() {
+		filteredIndices[line.Number.AsZeroBased()] = line.Index
+	}
+	return filteredIndices
+}
+
+// A filtering reader for r, following the pager's filter settings
+func (p *Pager) newFilteringReader(r reader.Reader) *FilteringReader {
+	return &FilteringReader{
+		BackingReader: r,
+		FilterPattern: &p.filterPattern,
+		OnlyCommands:  &p.onlyCommands,
+		OnlyChanges:   &p.onlyChanges,
+		HeaderLines:   &p.HeaderLines,
+	}
+}
+
+// A filtering reader for r using the current filter, even if the filter
+// changes later on. Safe to use from other goroutines.
+func (p *Pager) filteredSnapshot(r reader.Reader) *FilteringReader {
+	filterPattern := p.filterPattern
+	onlyCommands := p.onlyCommands
+	onlyChanges := p.onlyChanges
+	headerLines := p.HeaderLines
+	return &FilteringReader{
+		BackingReader: r,
+		FilterPattern: &filterPattern,
+		OnlyCommands:  &onlyCommands,
+		OnlyChanges:   &onlyChanges,
+		HeaderLines:   &headerLines,
+	}
+}
+
+func (f *FilteringReader) onlyCommands() bool {
+	return f.OnlyCommands != nil && *f.OnlyCommands
+}
+
+func (f *FilteringReader) onlyChanges() bool {
+	return f.OnlyChanges != nil && *f.OnlyChanges
+}
+
+// How many lines at the top are header lines. Same rules as in
+// Pager.headerLineCount(), except that this doesn't know the screen height.
+func (f *FilteringReader) headerLines() int {
+	if f.HeaderLines == nil || *f.HeaderLines <= 0 {
+		return 0
+	}
+
+	if f.BackingReader.GetLineCount() <= *f.HeaderLines {
+		// Everything fits in the header, so there is no header
+		return 0
+	}
+
+	return *f.HeaderLines
+}
+
 func (f *FilteringReader) GetLineCount() int {
 	if f.shouldPassThrough() {
 		return f.BackingReader.GetLineCount()
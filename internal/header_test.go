@@ -0,0 +1,104 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func newHeaderTestPager(t *testing.T, text string, width int, height int) *Pager {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestHeader", text), width, height)
+	pager.ShowStatusBar = false
+	return pager
+}
+
+func screenLinesAsStrings(pager *Pager) []string {
+	screenLines, _ := pager.renderScreenLines()
+	result := make([]string, 0, len(screenLines))
+	for _, line := range screenLines {
+		result = append(result, rowToString(line))
+	}
+	return result
+}
+
+func TestHeaderLines(t *testing.T) {
+	pager := newHeaderTestPager(t, "NAME AGE\nalice 1\nbob 2\ncecil 3\ndavid 4", 20, 3)
+	pager.HeaderLines = 1
+
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"NAME AGE", "alice 1", "bob 2"})
+
+	// Scrolling should keep the header in place
+	pager.scrollPosition = pager.scrollPosition.NextLine(2)
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"NAME AGE", "cecil 3", "david 4"})
+
+	// The header should not be scrolled into
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(0), "test")
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+}
+
+func TestHeaderLinesWhileFiltering(t *testing.T) {
+	pager := newHeaderTestPager(t, "NAME AGE\nalice 1\nbob 2\ncecil 3\ndavid 4", 20, 3)
+	pager.HeaderLines = 1
+	pager.filterPattern = toPattern("c")
+
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"NAME AGE", "alice 1", "cecil 3"})
+
+	// Header lines matching the filter should not show up twice
+	pager.filterPattern = toPattern("e")
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"NAME AGE", "alice 1", "cecil 3"})
+}
+
+func TestHeaderLinesWithWrapping(t *testing.T) {
+	pager := newHeaderTestPager(t, "HEADER LINE\nfirst line\nsecond line", 6, 4)
+	pager.HeaderLines = 1
+	pager.WrapLongLines = true
+
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"HEADER", "LINE", "first", "line"})
+	assert.Equal(t, pager.headerScreenLineCount(), 2)
+	assert.Equal(t, pager.visibleHeight(), 2)
+
+	// Scrolling should keep the whole wrapped header in place
+	pager.scrollPosition = pager.scrollPosition.NextLine(2)
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"HEADER", "LINE", "second", "line"})
+}
+
+func TestHeaderLinesNeverFillTheScreen(t *testing.T) {
+	pager := newHeaderTestPager(t, strings.Repeat("x\n", 10), 20, 3)
+	pager.HeaderLines = 5
+	assert.Equal(t, pager.visibleHeaderLineCount(), 2)
+	assert.Equal(t, pager.visibleHeight(), 1)
+}
+
+func TestHeaderColumns(t *testing.T) {
+	pager := newHeaderTestPager(t, "12:00 abcdefghijklmnop\n12:01 ABCDEFGHIJKLMNOP", 12, 2)
+	pager.HeaderColumns = 6
+	pager.ScrollLeftHint = twin.NewStyledRune('<', twin.StyleDefault)
+	pager.ScrollRightHint = twin.NewStyledRune('>', twin.StyleDefault)
+
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"12:00 abcde>", "12:01 ABCDE>"})
+
+	pager.leftColumnZeroBased = 4
+	assert.DeepEqual(t, screenLinesAsStrings(pager), []string{"12:00 <fghi>", "12:01 <FGHI>"})
+}
+
+func TestSplitHeaderColumns(t *testing.T) {
+	contents := []twin.StyledRune{
+		twin.NewStyledRune('a', twin.StyleDefault),
+		twin.NewStyledRune('午', twin.StyleDefault),
+		twin.NewStyledRune('b', twin.StyleDefault),
+	}
+
+	// The wide rune crosses the header edge and is replaced by a space
+	header, rest := splitHeaderColumns(contents, 2)
+	assert.Equal(t, rowToString(header), "a")
+	assert.Equal(t, len(header), 2)
+	assert.Equal(t, rowToString(rest), "b")
+
+	header, rest = splitHeaderColumns(contents, 3)
+	assert.Equal(t, rowToString(header), "a午")
+	assert.Equal(t, rowToString(rest), "b")
+}
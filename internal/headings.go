@@ -0,0 +1,96 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/internal/util"
+)
+
+// Headings in what we're currently showing, with indices into p.Reader(). While
+// filtering, only headings matching the filter are included.
+func (p *Pager) headings() []reader.Heading {
+	if p.isShowingHelp {
+		return p.helpReader.Headings()
+	}
+
+	headings := p.reader.Headings()
+	filteredIndices := p.filteringReader.filteredIndices()
+	if filteredIndices == nil {
+		return headings
+	}
+
+	filtered := []reader.Heading{}
+	for _, heading := range headings {
+		index, found := filteredIndices[heading.Index.Index()]
+		if !found {
+			continue
+		}
+
+		heading.Index = index
+		filtered = append(filtered, heading)
+	}
+
+	return filtered
+}
+
+func (p *Pager) headingLines() []linemetadata.Index {
+	lines := []linemetadata.Index{}
+	for _, heading := range p.headings() {
+		lines = append(lines, heading.Index)
+	}
+	return lines
+}
+
+// Scroll so that the count-th heading below the top of the screen is at the top
+func (p *Pager) scrollToNextHeading(count int) {
+	if !p.scrollToNextOf(p.headingLines(), count, "scrollToNextHeading") {
+		p.showStatusMessage(statusMessageInfo, "No more headings below")
+	}
+}
+
+// Scroll so that the count-th heading above the top of the screen is at the top
+func (p *Pager) scrollToPreviousHeading(count int) {
+	if !p.scrollToPreviousOf(p.headingLines(), count, "scrollToPreviousHeading") {
+		p.showStatusMessage(statusMessageInfo, "No more headings above")
+	}
+}
+
+func (p *Pager) scrollToHeading(heading reader.Heading) {
+	p.rememberJump()
+	p.scrollPosition = NewScrollPositionFromIndex(heading.Index, "scrollToHeading")
+	p.setTargetLine(nil)
+}
+
+// List all headings, picking one scrolls to it
+func (p *Pager) showOutline() {
+	headings := p.headings()
+	if len(headings) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No headings found")
+		return
+	}
+
+	items := make([]string, 0, len(headings))
+	selected := 0
+	current := p.lineIndex()
+	for i, heading := range headings {
+		items = append(items, strings.Repeat("  ", heading.Level)+heading.Text)
+
+		// Start out on the section we're in
+		if current != nil && !heading.Index.IsAfter(*current) {
+			selected = i
+		}
+	}
+
+	title := util.FormatInt(len(headings)) + " headings"
+	if len(headings) == 1 {
+		title = "1 heading"
+	}
+
+	list := newPagerModeList(p, title, items, func(index int) {
+		p.scrollToHeading(headings[index])
+	})
+	list.selected = selected
+	p.mode = list
+}
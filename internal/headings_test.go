@@ -0,0 +1,73 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// Man page with section headings on lines 0, 10 and 20, 30 lines in total
+func newHeadingsTestPager(t *testing.T) *Pager {
+	lines := []string{}
+	for _, heading := range []string{"NAME", "SYNOPSIS", "DESCRIPTION"} {
+		bold := ""
+		for _, char := range heading {
+			bold += string(char) + "\b" + string(char)
+		}
+		lines = append(lines, bold)
+
+		for i := 1; i < 10; i++ {
+			lines = append(lines, "       "+strings.ToLower(heading))
+		}
+	}
+
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("man page", strings.Join(lines, "\n")), 40, 6)
+	return pager
+}
+
+func TestHeadingNavigation(t *testing.T) {
+	pager := newHeadingsTestPager(t)
+
+	typeRunes(pager, ")")
+	assert.Equal(t, pager.lineIndex().Index(), 10)
+	typeRunes(pager, ")")
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+
+	typeRunes(pager, ")")
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+	assert.Equal(t, statusText(pager), "No more headings below")
+
+	typeRunes(pager, "2(")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+}
+
+func TestHeadingNavigationWhileFiltering(t *testing.T) {
+	pager := newHeadingsTestPager(t)
+	pager.filterPattern = toPattern("i")
+
+	// NAME doesn't match, so we start out at SYNOPSIS
+	typeRunes(pager, ")")
+	assert.Equal(t, pager.lineIndex().Index(), 10)
+	assert.Equal(t, pager.Reader().GetLine(*pager.lineIndex()).Plain(), "DESCRIPTION")
+}
+
+func TestOutline(t *testing.T) {
+	pager := newHeadingsTestPager(t)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(12), "test")
+
+	typeRunes(pager, "o")
+	list, ok := pager.mode.(*PagerModeList)
+	assert.Assert(t, ok)
+	assert.DeepEqual(t, list.items, []string{"NAME", "SYNOPSIS", "DESCRIPTION"})
+
+	// We're in the SYNOPSIS section
+	assert.Equal(t, list.selected, 1)
+
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+}
@@ -0,0 +1,166 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/internal/reader"
+)
+
+const helpIntro = `
+Welcome to Moor, the nice pager!
+`
+
+// Shown after the key bindings of each section
+var helpSectionNotes = map[string]string{
+	sectionMisc: `While selecting lines, move using the arrow keys, 'j' / 'k' or PageUp /
+PageDown. Press RETURN or 'y' to copy the selected lines, or ESC to cancel.
+Dragging the mouse over some lines also selects and copies them. Copying needs
+a terminal supporting OSC 52, inside tmux also "set -g allow-passthrough on".`,
+
+	sectionMoving: `Type a number before moving to do it that many times, like "10j" to scroll down
+ten lines, or "5n" to skip to the fifth search hit from here. Numbers before
+the go-to commands go to that line number, like "3G". Type a number followed by
+'%' to go that far into the file, like "50%".`,
+
+	sectionSearching: `* While typing, RETURN stops searching, and ESC skips back to where the search
+  started
+* While typing, use the arrow keys, CTRL-a / CTRL-e, CTRL-w and CTRL-u to edit
+* Up / down arrows while typing bring back previous searches
+* Unless wrapping long lines, finding next / previous also steps through all
+  hits on long lines, scrolling sideways as needed
+* Search is case sensitive if it contains any UPPER CASE CHARACTERS
+* Search is interpreted as a regexp if it is a valid one
+* While typing, ALT-r switches between auto, regexp and literal matching
+* While typing, ALT-c switches between smart case, case sensitive and ignore case
+* While typing, ALT-w toggles matching whole words only
+* While typing, ALT-d toggles ignoring accents and character widths, so that
+  "resume" finds "résumé"
+* The active modes are shown in the prompt, defaults can be set using the
+  --search-mode, --search-case, --search-whole-word and --search-fold command
+  line options`,
+
+	sectionSplit: `Each pane has its own position and search highlight, and can show a different
+file. Clicking a pane with the mouse also moves focus to it.`,
+
+	sectionFiltering: `While filtering, PageUp and PageDown work as usual. The other keys edit the
+filter expression just like when searching, see above.
+
+Press 'ESC' or RETURN to exit filtering mode.`,
+}
+
+const helpOutro = `
+Key bindings
+------------
+The keys listed above can be changed. Pick a preset using --keymap, or put
+lines like ctrl-f = "page-down" in the [keys] section of
+~/.config/moor/config.toml. Bind a key to "none" to unbind it.
+
+Actions: ` + "%ACTIONS%" + `
+
+Reporting bugs
+--------------
+File issues at https://github.com/walles/moor/issues, or post
+questions to johan.walles@gmail.com.
+
+Installing Moor as your default pager
+-------------------------------------
+Put the following line in your ~/.bashrc, ~/.bash_profile or ~/.zshrc:
+  export PAGER=moor
+
+Source Code
+-----------
+Available at https://github.com/walles/moor/.
+`
+
+// Generate the help text from the keymap, so that it always matches what the
+// keys actually do
+func helpText(keymap *Keymap) string {
+	var builder strings.Builder
+	builder.WriteString(helpIntro)
+
+	section := ""
+	for _, action := range actions {
+		if action.section != section {
+			if section != "" && helpSectionNotes[section] != "" {
+				builder.WriteString("\n" + helpSectionNotes[section] + "\n")
+			}
+
+			section = action.section
+			builder.WriteString("\n" + section + "\n")
+			builder.WriteString(strings.Repeat("-", len(section)) + "\n")
+		}
+
+		keys := keymap.describe(action.name)
+		if keys == "" {
+			continue
+		}
+		builder.WriteString("* " + keys + ": " + action.description + "\n")
+	}
+	if helpSectionNotes[section] != "" {
+		builder.WriteString("\n" + helpSectionNotes[section] + "\n")
+	}
+
+	actionNames := []string{}
+	for _, action := range actions {
+		actionNames = append(actionNames, action.name)
+	}
+	builder.WriteString(strings.Replace(helpOutro, "%ACTIONS%", wrapWords(actionNames, len("Actions: "), 78), 1))
+
+	return builder.String()
+}
+
+// Join words with ", ", breaking lines before they get longer than width. The
+// first line is assumed to already have firstIndent characters on it.
+func wrapWords(words []string, firstIndent int, width int) string {
+	var builder strings.Builder
+	column := firstIndent
+	for i, word := range words {
+		if i < len(words)-1 {
+			word += ","
+		}
+
+		if i > 0 {
+			if column+1+len(word) > width {
+				builder.WriteString("\n")
+				column = 0
+			} else {
+				builder.WriteString(" ")
+				column++
+			}
+		}
+
+		builder.WriteString(word)
+		column += len(word)
+	}
+	return builder.String()
+}
+
+func newHelpReader(keymap *Keymap) *reader.ReaderImpl {
+	return reader.NewFromTextForTesting("Help", helpText(keymap))
+}
+
+// Short hints about the most important keys, for the status bar
+func footerHints(keymap *Keymap, isShowingHelp bool) string {
+	hints := []string{}
+	addHint := func(actionName string, what string) {
+		keys := keymap.describe(actionName)
+		if keys != "" {
+			hints = append(hints, keys+" "+what)
+		}
+	}
+
+	if isShowingHelp {
+		addHint("quit", "to exit help")
+		addHint("search-forward", "to search")
+	} else {
+		addHint("quit", "to exit")
+		addHint("search-forward", "to search")
+		addHint("filter", "to filter")
+		addHint("help", "for help")
+	}
+
+	if len(hints) == 0 {
+		return ""
+	}
+	return "Press " + strings.Join(hints, ", ")
+}
@@ -0,0 +1,240 @@
+package internal
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/internal/textstyles"
+	"github.com/walles/moor/twin"
+)
+
+// A link on an input line. Either a hyperlink, from OSC 8 or from a plain text
+// URL, or a "file.go:123" style file reference.
+type hyperlink struct {
+	lineIndex linemetadata.Index
+
+	// Rune indices into the plain text of the line, end exclusive
+	runeRange [2]int
+
+	// Empty for file references
+	url string
+
+	// Only set for file references
+	file textstyles.FileReference
+}
+
+func (link hyperlink) isFileReference() bool {
+	return link.url == ""
+}
+
+// What to copy to the clipboard for this link
+func (link hyperlink) String() string {
+	if !link.isFileReference() {
+		return link.url
+	}
+
+	s := link.file.Path + ":" + strconv.Itoa(link.file.Line)
+	if link.file.Column > 0 {
+		s += ":" + strconv.Itoa(link.file.Column)
+	}
+	return s
+}
+
+// All links on a line, in line order
+func lineHyperlinks(line *reader.NumberedLine) []hyperlink {
+	links := []hyperlink{}
+
+	// OSC 8 hyperlinks. Neighboring runes with the same URL make up one link.
+	runes := line.HighlightedTokens(plainTextStyle, nil, nil).StyledRunes
+	for i := 0; i < len(runes); {
+		url := runes[i].Style.HyperlinkURL()
+		if url == nil {
+			i++
+			continue
+		}
+
+		end := i + 1
+		for end < len(runes) {
+			nextURL := runes[end].Style.HyperlinkURL()
+			if nextURL == nil || *nextURL != *url {
+				break
+			}
+			end++
+		}
+
+		links = append(links, hyperlink{lineIndex: line.Index, runeRange: [2]int{i, end}, url: *url})
+		i = end
+	}
+
+	// File references, unless they are part of some hyperlink
+	for _, reference := range textstyles.FileReferences(line.Plain()) {
+		overlapsURL := false
+		for _, link := range links {
+			if reference.RuneRange[0] < link.runeRange[1] && link.runeRange[0] < reference.RuneRange[1] {
+				overlapsURL = true
+				break
+			}
+		}
+		if overlapsURL {
+			continue
+		}
+
+		links = append(links, hyperlink{lineIndex: line.Index, runeRange: reference.RuneRange, file: reference})
+	}
+
+	sort.SliceStable(links, func(i, j int) bool {
+		return links[i].runeRange[0] < links[j].runeRange[0]
+	})
+
+	return links
+}
+
+// All links on a line. In the search results, each line starts with a
+// "file.txt:12" reference to a hit, and that is always a link.
+func (p *Pager) hyperlinksOnLine(line *reader.NumberedLine) []hyperlink {
+	links := lineHyperlinks(line)
+
+	hit := p.globalSearchHitOnLine(line)
+	if hit == nil {
+		return links
+	}
+
+	hitLink := hyperlink{lineIndex: line.Index, runeRange: hit.reference.RuneRange, file: hit.reference}
+	withHitLink := []hyperlink{hitLink}
+	for _, link := range links {
+		if link.runeRange[0] < hitLink.runeRange[1] {
+			// Part of the reference, found by looking at the text
+			continue
+		}
+		withHitLink = append(withHitLink, link)
+	}
+	return withHitLink
+}
+
+// All links on screen, top to bottom
+func (p *Pager) visibleHyperlinks() []hyperlink {
+	renderedLines, _ := p.renderLines()
+
+	links := []hyperlink{}
+	var previousIndex *linemetadata.Index
+	for _, renderedLine := range renderedLines {
+		if previousIndex != nil && *previousIndex == renderedLine.inputLineIndex {
+			// Wrapped line, we already have its links
+			continue
+		}
+		previousIndex = &renderedLine.inputLineIndex
+
+		line := p.Reader().GetLine(renderedLine.inputLineIndex)
+		if line == nil {
+			continue
+		}
+		links = append(links, p.hyperlinksOnLine(line)...)
+	}
+
+	return links
+}
+
+// The focused link, or nil if there is none or if it's not on screen
+func (p *Pager) visibleFocusedHyperlink() *hyperlink {
+	if p.focusedHyperlink == nil {
+		return nil
+	}
+
+	for _, link := range p.visibleHyperlinks() {
+		if link == *p.focusedHyperlink {
+			return &link
+		}
+	}
+
+	return nil
+}
+
+// Move link focus to the next (or previous if backwards) link on screen. If no
+// link on screen has focus, the first (or last) one gets it.
+func (p *Pager) focusNextHyperlink(backwards bool) {
+	links := p.visibleHyperlinks()
+	if len(links) == 0 {
+		p.focusedHyperlink = nil
+		p.showStatusMessage(statusMessageInfo, "No links on screen")
+		return
+	}
+
+	next := 0
+	if backwards {
+		next = len(links) - 1
+	}
+	for i, link := range links {
+		if p.focusedHyperlink == nil || link != *p.focusedHyperlink {
+			continue
+		}
+
+		if backwards {
+			next = (i - 1 + len(links)) % len(links)
+		} else {
+			next = (i + 1) % len(links)
+		}
+		break
+	}
+
+	p.focusedHyperlink = &links[next]
+	p.scrollHorizontallyToRunes(links[next].lineIndex, links[next].runeRange)
+}
+
+// Open the focused link using the LinkOpener command, or the system's URL
+// handler. File references are opened in the user's editor, except in the
+// search results where we go to the hit instead.
+func (p *Pager) openFocusedHyperlink() {
+	if p.isShowingGlobalSearchHits() {
+		p.openGlobalSearchHitOnScreen()
+		return
+	}
+
+	link := p.visibleFocusedHyperlink()
+	if link == nil {
+		p.showStatusMessage(statusMessageInfo, "No link selected")
+		return
+	}
+
+	if link.isFileReference() {
+		handleFileReferenceEditingRequest(p, link.file)
+		return
+	}
+
+	openURL(p.LinkOpener, link.url)
+}
+
+func (p *Pager) copyFocusedHyperlink() {
+	link := p.visibleFocusedHyperlink()
+	if link == nil {
+		p.showStatusMessage(statusMessageInfo, "No link selected")
+		return
+	}
+
+	p.setClipboard(link.String())
+}
+
+// Make the focused link stand out if it's on this line
+func (p *Pager) highlightFocusedHyperlink(line *reader.NumberedLine, runes []twin.StyledRune) {
+	link := p.focusedHyperlink
+	if link == nil || link.lineIndex != line.Index {
+		return
+	}
+
+	stillThere := false
+	for _, lineLink := range p.hyperlinksOnLine(line) {
+		if lineLink == *link {
+			stillThere = true
+			break
+		}
+	}
+	if !stillThere {
+		// Filtering or buffer switching has given us some other line
+		return
+	}
+
+	for i := link.runeRange[0]; i < link.runeRange[1] && i < len(runes); i++ {
+		runes[i].Style = runes[i].Style.WithAttr(twin.AttrReverse)
+	}
+}
@@ -0,0 +1,103 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func newHyperlinksTestPager(t *testing.T, text string) (*Pager, *twin.FakeScreen) {
+	return newTestPager(t, reader.NewFromTextForTesting("TestHyperlinks", text), 60, 4)
+}
+
+func TestLineHyperlinks(t *testing.T) {
+	line := reader.NewLine("Go to \x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\ now")
+	links := lineHyperlinks(&reader.NumberedLine{Line: &line})
+
+	assert.Equal(t, len(links), 1)
+	assert.Equal(t, links[0].url, "https://example.com")
+	assert.Equal(t, links[0].runeRange, [2]int{6, 13})
+
+	line = reader.NewLine("Go to (https://moor.dev/x_(y)).")
+	links = lineHyperlinks(&reader.NumberedLine{Line: &line})
+
+	assert.Equal(t, len(links), 1)
+	assert.Equal(t, links[0].url, "https://moor.dev/x_(y)")
+	assert.Equal(t, links[0].runeRange, [2]int{7, 29})
+}
+
+func TestLineHyperlinksFileReferences(t *testing.T) {
+	line := reader.NewLine("main.go:12:5: undefined: x, see https://example.com/x.go:3")
+	links := lineHyperlinks(&reader.NumberedLine{Line: &line})
+
+	assert.Equal(t, len(links), 2)
+	assert.Assert(t, links[0].isFileReference())
+	assert.Equal(t, links[0].String(), "main.go:12:5")
+	assert.Equal(t, links[0].runeRange, [2]int{0, 12})
+	assert.Equal(t, links[1].String(), "https://example.com/x.go:3")
+}
+
+func TestFocusHyperlinks(t *testing.T) {
+	pager, screen := newHyperlinksTestPager(t,
+		"See https://one.example.com\nnothing here\n\x1b]8;;https://two.example.com\x1b\\two\x1b]8;;\x1b\\")
+
+	typeRunes(pager, "Y")
+	assert.Equal(t, statusText(pager), "No link selected")
+
+	typeRunes(pager, "L")
+	typeRunes(pager, "Y")
+	assert.Equal(t, screen.GetClipboard(), "https://one.example.com")
+
+	typeRunes(pager, "L")
+	typeRunes(pager, "Y")
+	assert.Equal(t, screen.GetClipboard(), "https://two.example.com")
+
+	// The focused link should be highlighted
+	pager.redraw("")
+	assert.Equal(t, screen.GetRow(2)[0].Style.String(), `reverse "https://two.example.com" Default color on Default color`)
+	assert.Equal(t, screen.GetRow(0)[4].Style.String(), `"https://one.example.com" Default color on Default color`)
+
+	// Wrap around
+	typeRunes(pager, "L")
+	typeRunes(pager, "Y")
+	assert.Equal(t, screen.GetClipboard(), "https://one.example.com")
+
+	typeRunes(pager, "H")
+	typeRunes(pager, "Y")
+	assert.Equal(t, screen.GetClipboard(), "https://two.example.com")
+}
+
+func TestFocusHyperlinksNoLinks(t *testing.T) {
+	pager, _ := newHyperlinksTestPager(t, "no links\nhere")
+
+	typeRunes(pager, "L")
+	assert.Equal(t, statusText(pager), "No links on screen")
+	assert.Assert(t, pager.focusedHyperlink == nil)
+}
+
+func TestFocusHyperlinkScrollsSideways(t *testing.T) {
+	pager, _ := newHyperlinksTestPager(t,
+		"This line is long enough that the link is off screen: https://example.com")
+
+	typeRunes(pager, "L")
+	assert.Assert(t, pager.leftColumnZeroBased > 0)
+}
+
+// Drawing the pane without focus used to drop the focused link
+func TestFocusHyperlinksInSplitMode(t *testing.T) {
+	pager, _ := newHyperlinksTestPager(t, "See https://one.example.com\nand https://two.example.com")
+	screen := twin.NewFakeScreen(60, 10)
+	pager.screen = screen
+	pager.toggleSplit()
+	pager.redraw("")
+
+	typeRunes(pager, "L")
+	pager.redraw("")
+	typeRunes(pager, "L")
+	pager.redraw("")
+	typeRunes(pager, "Y")
+	assert.Equal(t, statusText(pager), "")
+	assert.Equal(t, screen.GetClipboard(), "https://two.example.com")
+}
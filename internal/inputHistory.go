@@ -0,0 +1,113 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Don't let the history files grow forever
+const maxInputHistoryEntries = 500
+
+// Previously entered prompt lines, most recent last.
+//
+// If fileName is set, the history will be persisted to that file on every
+// add().
+type inputHistory struct {
+	entries []string
+
+	// Empty means this history won't be persisted
+	fileName string
+}
+
+// Load the named history from the moor state directory. Will never return nil,
+// if loading fails you'll get an empty history.
+func loadInputHistory(name string) *inputHistory {
+	stateHome, err := xdgStateHome()
+	if err != nil {
+		log.Info("No state directory found, ", name, " won't be persisted: ", err)
+		return &inputHistory{}
+	}
+
+	history := inputHistory{
+		fileName: filepath.Join(stateHome, "moor", name),
+	}
+	history.entries = history.readEntries()
+	return &history
+}
+
+// Read history entries from disk. Returns nil on failure.
+func (h *inputHistory) readEntries() []string {
+	if h.fileName == "" {
+		return nil
+	}
+
+	contents, err := os.ReadFile(h.fileName)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			log.Info("Failed to read history file ", h.fileName, ": ", err)
+		}
+		return nil
+	}
+
+	entries := make([]string, 0)
+	for _, line := range strings.Split(string(contents), "\n") {
+		if line == "" {
+			continue
+		}
+		entries = append(entries, line)
+	}
+
+	return entries
+}
+
+// Add an entry to the end of the history. If the entry was already in the
+// history, it will be moved to the end.
+func (h *inputHistory) add(entry string) {
+	if entry == "" {
+		return
+	}
+
+	if h.fileName != "" {
+		// Other moor instances may have added entries since we loaded the file,
+		// don't drop those.
+		onDisk := h.readEntries()
+		if onDisk != nil {
+			h.entries = onDisk
+		}
+	}
+
+	withoutEntry := make([]string, 0, len(h.entries)+1)
+	for _, existing := range h.entries {
+		if existing == entry {
+			continue
+		}
+		withoutEntry = append(withoutEntry, existing)
+	}
+	h.entries = append(withoutEntry, entry)
+
+	if len(h.entries) > maxInputHistoryEntries {
+		h.entries = h.entries[len(h.entries)-maxInputHistoryEntries:]
+	}
+
+	h.save()
+}
+
+func (h *inputHistory) save() {
+	if h.fileName == "" {
+		return
+	}
+
+	err := os.MkdirAll(filepath.Dir(h.fileName), 0o700)
+	if err != nil {
+		log.Info("Failed to create history directory for ", h.fileName, ": ", err)
+		return
+	}
+
+	err = os.WriteFile(h.fileName, []byte(strings.Join(h.entries, "\n")+"\n"), 0o600)
+	if err != nil {
+		log.Info("Failed to write history file ", h.fileName, ": ", err)
+	}
+}
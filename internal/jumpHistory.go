@@ -0,0 +1,185 @@
+package internal
+
+import (
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/util"
+)
+
+// Don't let the jump history grow forever
+const maxJumpHistoryLength = 100
+
+// Where we were before each "big" jump, like going to the end, searching or
+// jumping to a mark. Think vim's jump list.
+//
+// The zero value is an empty history.
+type jumpHistory struct {
+	// Oldest first
+	positions []scrollPosition
+
+	// Where in positions we are when going back and forth. Equal to
+	// len(positions) when we haven't gone back.
+	current int
+}
+
+// Call this before making a "big" jump, so that we can go back here later.
+func (p *Pager) rememberJump() {
+	p.rememberJumpFrom(p.scrollPosition)
+}
+
+// Like rememberJump(), for when we have already left the position
+func (p *Pager) rememberJumpFrom(position scrollPosition) {
+	history := &p.jumpHistory
+
+	// Jumping somewhere new forgets about where we went back from
+	history.positions = history.positions[:history.current]
+
+	lineIndex := position.lineIndex(p)
+	if lineIndex == nil {
+		// No lines, nothing to remember
+		history.current = len(history.positions)
+		return
+	}
+
+	if len(history.positions) > 0 {
+		last := history.positions[len(history.positions)-1]
+		lastIndex := last.lineIndex(p)
+		if lastIndex != nil && *lastIndex == *lineIndex {
+			// Already remembered
+			history.current = len(history.positions)
+			return
+		}
+	}
+
+	history.positions = append(history.positions, NewScrollPositionFromIndex(*lineIndex, "rememberJump"))
+	if len(history.positions) > maxJumpHistoryLength {
+		history.positions = history.positions[len(history.positions)-maxJumpHistoryLength:]
+	}
+	history.current = len(history.positions)
+}
+
+// Index into the jump history of the closest position in the given direction
+// that isn't where we are now. Nil if there is no such position.
+func (p *Pager) findJump(backwards bool) *int {
+	history := &p.jumpHistory
+	current := p.lineIndex()
+
+	step := 1
+	if backwards {
+		step = -1
+	}
+
+	for i := history.current + step; i >= 0 && i < len(history.positions); i += step {
+		lineIndex := history.positions[i].lineIndex(p)
+		if lineIndex == nil || current == nil || *lineIndex != *current {
+			return &i
+		}
+	}
+
+	return nil
+}
+
+func (p *Pager) goToJump(index int) {
+	p.jumpHistory.current = index
+	p.scrollPosition = p.jumpHistory.positions[index]
+	p.setTargetLine(nil)
+}
+
+// Go back to where we were before the latest jump
+func (p *Pager) jumpBack() {
+	history := &p.jumpHistory
+	if history.current == len(history.positions) {
+		// Remember where we are, so that we can come forward here again
+		p.rememberJump()
+		history.current = max(len(history.positions)-1, 0)
+	}
+
+	index := p.findJump(true)
+	if index == nil {
+		p.showStatusMessage(statusMessageInfo, "No earlier positions to go back to")
+		return
+	}
+
+	p.goToJump(*index)
+}
+
+// Undo a jumpBack()
+func (p *Pager) jumpForward() {
+	index := p.findJump(false)
+	if index == nil {
+		p.showStatusMessage(statusMessageInfo, "No later positions to go forward to")
+		return
+	}
+
+	p.goToJump(*index)
+}
+
+// Like pressing the ' key twice in less. Go to where we were before the latest
+// jump, doing it again comes back here.
+func (p *Pager) jumpToPreviousPosition() {
+	history := &p.jumpHistory
+	current := p.lineIndex()
+	for i := len(history.positions) - 1; i >= 0; i-- {
+		destination := history.positions[i]
+		lineIndex := destination.lineIndex(p)
+		if lineIndex != nil && current != nil && *lineIndex == *current {
+			continue
+		}
+
+		history.current = len(history.positions)
+		p.rememberJump()
+		p.scrollPosition = destination
+		p.setTargetLine(nil)
+		return
+	}
+
+	p.showStatusMessage(statusMessageInfo, "No previous position to go back to")
+}
+
+// List recent positions, newest first. Picking one goes there.
+func (p *Pager) showJumpHistory() {
+	history := &p.jumpHistory
+	if len(history.positions) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No jumps made yet")
+		return
+	}
+
+	indices := []int{}
+	items := []string{}
+	selected := 0
+	for i := len(history.positions) - 1; i >= 0; i-- {
+		lineIndex := history.positions[i].lineIndex(p)
+		if lineIndex == nil {
+			continue
+		}
+
+		if i == history.current {
+			selected = len(items)
+		}
+		indices = append(indices, i)
+		items = append(items, p.jumpDescription(*lineIndex))
+	}
+
+	title := util.FormatInt(len(items)) + " recent positions"
+	if len(items) == 1 {
+		title = "1 recent position"
+	}
+
+	list := newPagerModeList(p, title, items, func(index int) {
+		destination := p.jumpHistory.positions[indices[index]]
+		p.rememberJump()
+		p.scrollPosition = destination
+		p.setTargetLine(nil)
+	})
+	list.selected = selected
+	p.mode = list
+}
+
+// Something like "123: The text on line 123"
+func (p *Pager) jumpDescription(lineIndex linemetadata.Index) string {
+	line := p.Reader().GetLine(lineIndex)
+	if line == nil {
+		return lineIndex.Format() + ":"
+	}
+
+	return line.Number.Format() + ": " + line.Plain()
+}
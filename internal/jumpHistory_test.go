@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+const ctrlO = "\x0f"
+const ctrlT = "\x14"
+
+func TestJumpBackAndForward(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "50G")
+	assert.Equal(t, pager.lineIndex().Index(), 49)
+	typeRunes(pager, "20G")
+	assert.Equal(t, pager.lineIndex().Index(), 19)
+
+	// Small moves aren't jumps
+	typeRunes(pager, "j")
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 49)
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	assert.Equal(t, statusText(pager), "No earlier positions to go back to")
+
+	typeRunes(pager, ctrlT)
+	assert.Equal(t, pager.lineIndex().Index(), 49)
+	typeRunes(pager, ctrlT)
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+	typeRunes(pager, ctrlT)
+	assert.Equal(t, statusText(pager), "No later positions to go forward to")
+}
+
+func TestJumpAfterGoingBackDropsForwardPositions(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "50G")
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+
+	typeRunes(pager, "30G")
+	typeRunes(pager, ctrlT)
+	assert.Equal(t, statusText(pager), "No later positions to go forward to")
+
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+}
+
+func TestJumpToPreviousPositionLikeLess(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "''")
+	assert.Equal(t, statusText(pager), "No previous position to go back to")
+
+	typeRunes(pager, "50G")
+	typeRunes(pager, "''")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	typeRunes(pager, "''")
+	assert.Equal(t, pager.lineIndex().Index(), 49)
+	typeRunes(pager, "''")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+}
+
+func TestJumpToMarkIsRemembered(t *testing.T) {
+	pager := newCountTestPager(t)
+	pager.marks = make(map[rune]scrollPosition)
+
+	typeRunes(pager, "40Gma")
+	typeRunes(pager, "<")
+	typeRunes(pager, "20G")
+	typeRunes(pager, "'a")
+	assert.Equal(t, pager.lineIndex().Index(), 39)
+
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 19)
+}
+
+func TestJumpHistoryList(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "\"")
+	assert.Equal(t, statusText(pager), "No jumps made yet")
+
+	typeRunes(pager, "50G")
+	typeRunes(pager, "70G")
+
+	typeRunes(pager, "\"")
+	list, ok := pager.mode.(*PagerModeList)
+	assert.Assert(t, ok)
+	assert.Equal(t, list.title, "2 recent positions")
+	assert.DeepEqual(t, list.items, []string{"50: 50", "1: 1"})
+
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+
+	// Going there was a jump too
+	typeRunes(pager, ctrlO)
+	assert.Equal(t, pager.lineIndex().Index(), 69)
+}
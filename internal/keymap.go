@@ -0,0 +1,342 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/walles/moor/twin"
+)
+
+// A key press, either a special key or a rune
+type key struct {
+	keyCode twin.KeyCode
+	char    rune // 0 if this is a special key
+}
+
+type keyBinding struct {
+	key    key
+	action *action
+}
+
+// Keymap maps key presses in viewing mode to actions.
+//
+// Create one using NewKeymap(), then optionally change it using Bind().
+type Keymap struct {
+	// In binding order, for the help screen
+	bindings []keyBinding
+}
+
+// Names of the keymaps accepted by NewKeymap()
+var KeymapPresets = []string{"default", "less", "vim", "emacs"}
+
+var defaultBindings = [][2]string{
+	{"esc", "quit"},
+	{"q", "quit"},
+	{"h", "help"},
+	{"w", "toggle-wrap"},
+	{"=", "toggle-status-bar"},
+	{"alt-down", "more-header-lines"},
+	{"alt-up", "fewer-header-lines"},
+	{"}", "more-header-columns"},
+	{"{", "fewer-header-columns"},
+	{"V", "select-lines"},
+	{"v", "edit"},
+	{"L", "next-link"},
+	{"H", "previous-link"},
+	{"O", "open-link"},
+	{"Y", "copy-link"},
+
+	{"up", "scroll-up"},
+	{"k", "scroll-up"},
+	{"y", "scroll-up"},
+	// Ref: https://github.com/walles/moor/issues/107#issuecomment-1328354080
+	{"ctrl-p", "scroll-up"},
+
+	{"down", "scroll-down"},
+	{"enter", "scroll-down"},
+	{"j", "scroll-down"},
+	{"e", "scroll-down"},
+	// Ref: https://github.com/walles/moor/issues/107#issuecomment-1328354080
+	{"ctrl-n", "scroll-down"},
+
+	{"left", "scroll-left"},
+	{"right", "scroll-right"},
+	{"alt-left", "scroll-left-one"},
+	{"alt-right", "scroll-right-one"},
+
+	{"pgup", "page-up"},
+	{"b", "page-up"},
+	{"pgdown", "page-down"},
+	{"f", "page-down"},
+	{"space", "page-down"},
+
+	// Ref: https://github.com/walles/moor/issues/90
+	{"u", "half-page-up"},
+	{"ctrl-u", "half-page-up"},
+	{"d", "half-page-down"},
+	{"ctrl-d", "half-page-down"},
+
+	{"home", "go-to-start"},
+	{"<", "go-to-start"},
+	{"end", "go-to-end"},
+	{">", "go-to-end"},
+	{"G", "go-to-end"},
+	{"g", "go-to-line"},
+	{"m", "set-mark"},
+	{"'", "jump-to-mark"},
+	{"`", "next-mark"},
+	{"~", "previous-mark"},
+	{"M", "list-marks"},
+	{"ctrl-o", "jump-back"},
+	// CTRL-I would be like in vim, but that's the same key as TAB
+	{"ctrl-t", "jump-forward"},
+	{"\"", "jump-history"},
+	{")", "next-heading"},
+	{"(", "previous-heading"},
+	{"o", "outline"},
+	{"J", "next-diff-file"},
+	{"K", "previous-diff-file"},
+	{".", "next-hunk"},
+	{",", "previous-hunk"},
+	{"x", "next-prompt"},
+	{"X", "previous-prompt"},
+	{"r", "next-command-output"},
+	{"R", "previous-command-output"},
+
+	{"/", "search-forward"},
+	{"?", "search-backward"},
+	{"n", "search-next"},
+	{"p", "search-previous"},
+	{"N", "search-previous"},
+	{"c", "copy-search-hit"},
+
+	{"&", "filter"},
+	{"C", "toggle-diff-context"},
+	{"!", "toggle-commands-filter"},
+
+	{"]", "next-buffer"},
+	{"[", "previous-buffer"},
+	{"*", "search-all-buffers"},
+	{"I", "show-log"},
+
+	{"s", "toggle-split"},
+	{"tab", "switch-pane"},
+	{"+", "grow-pane"},
+	{"-", "shrink-pane"},
+
+	{":", "command"},
+}
+
+// Extra bindings on top of the default ones. If a preset binds a key that is
+// also in the default bindings, the preset wins.
+var presetBindings = map[string][][2]string{
+	"default": {},
+	"less": {
+		{"ctrl-f", "page-down"},
+		{"ctrl-v", "page-down"},
+		{"z", "page-down"},
+		{"ctrl-b", "page-up"},
+		{"ctrl-e", "scroll-down"},
+		{"ctrl-j", "scroll-down"},
+		{"ctrl-y", "scroll-up"},
+		{"ctrl-k", "scroll-up"},
+		{"Q", "quit"},
+	},
+	"vim": {
+		{"ctrl-f", "page-down"},
+		{"ctrl-b", "page-up"},
+		{"ctrl-e", "scroll-down"},
+		{"ctrl-y", "scroll-up"},
+		{"l", "scroll-right"},
+
+		// Terminals send the same thing for CTRL-i and TAB, so this takes TAB
+		// away from switch-pane. Going forward is what TAB does in vim.
+		{"ctrl-i", "jump-forward"},
+
+		// Vim window commands start with CTRL-w
+		{"ctrl-w", "switch-pane"},
+	},
+	"emacs": {
+		{"ctrl-v", "page-down"},
+		{"ctrl-s", "search-forward"},
+		{"ctrl-r", "search-backward"},
+		{"ctrl-g", "quit"},
+	},
+}
+
+// Special key names, in the order they are listed on the help screen
+var keyNames = []struct {
+	name    string
+	keyCode twin.KeyCode
+	display string
+}{
+	{"esc", twin.KeyEscape, "ESC"},
+	{"enter", twin.KeyEnter, "RETURN"},
+	{"backspace", twin.KeyBackspace, "BACKSPACE"},
+	{"delete", twin.KeyDelete, "DELETE"},
+	{"up", twin.KeyUp, "up arrow"},
+	{"down", twin.KeyDown, "down arrow"},
+	{"right", twin.KeyRight, "right arrow"},
+	{"left", twin.KeyLeft, "left arrow"},
+	{"alt-up", twin.KeyAltUp, "ALT-up arrow"},
+	{"alt-down", twin.KeyAltDown, "ALT-down arrow"},
+	{"alt-right", twin.KeyAltRight, "ALT-right arrow"},
+	{"alt-left", twin.KeyAltLeft, "ALT-left arrow"},
+	{"home", twin.KeyHome, "Home"},
+	{"end", twin.KeyEnd, "End"},
+	{"pgup", twin.KeyPgUp, "PageUp"},
+	{"pgdown", twin.KeyPgDown, "PageDown"},
+	{"alt-c", twin.KeyAltC, "ALT-c"},
+	{"alt-r", twin.KeyAltR, "ALT-r"},
+	{"alt-w", twin.KeyAltW, "ALT-w"},
+	{"alt-d", twin.KeyAltD, "ALT-d"},
+}
+
+// NewKeymap creates a keymap from one of the KeymapPresets
+func NewKeymap(preset string) (*Keymap, error) {
+	extraBindings, found := presetBindings[preset]
+	if !found {
+		return nil, fmt.Errorf("Valid keymaps are %s", strings.Join(KeymapPresets, ", "))
+	}
+
+	keymap := Keymap{}
+	for _, binding := range append(defaultBindings, extraBindings...) {
+		err := keymap.Bind(binding[0], binding[1])
+		if err != nil {
+			panic(fmt.Errorf("Broken %s keymap: %w", preset, err))
+		}
+	}
+
+	return &keymap, nil
+}
+
+// Parse a key name like "q", "space", "ctrl-f" or "pgdown"
+func parseKey(name string) (key, error) {
+	if len([]rune(name)) == 1 {
+		return key{char: []rune(name)[0]}, nil
+	}
+
+	lowerName := strings.ToLower(name)
+	switch lowerName {
+	case "space":
+		return key{char: ' '}, nil
+	case "tab":
+		return key{char: '\t'}, nil
+	case "escape":
+		return key{keyCode: twin.KeyEscape}, nil
+	case "return":
+		return key{keyCode: twin.KeyEnter}, nil
+	case "pageup":
+		return key{keyCode: twin.KeyPgUp}, nil
+	case "pagedown":
+		return key{keyCode: twin.KeyPgDown}, nil
+	}
+
+	for _, keyName := range keyNames {
+		if keyName.name == lowerName {
+			return key{keyCode: keyName.keyCode}, nil
+		}
+	}
+
+	if strings.HasPrefix(lowerName, "ctrl-") && len(lowerName) == len("ctrl-x") {
+		letter := lowerName[len("ctrl-")]
+		if letter >= 'a' && letter <= 'z' {
+			return key{char: rune(letter-'a') + 1}, nil
+		}
+	}
+
+	return key{}, fmt.Errorf("Unknown key <%s>", name)
+}
+
+// How to show this key on the help screen
+func (k key) String() string {
+	if k.char == 0 {
+		for _, keyName := range keyNames {
+			if keyName.keyCode == k.keyCode {
+				return keyName.display
+			}
+		}
+		return fmt.Sprintf("key %d", k.keyCode)
+	}
+
+	if k.char == ' ' {
+		return "SPACE"
+	}
+
+	if k.char == '\t' {
+		return "TAB"
+	}
+
+	if k.char == '\'' {
+		return "' (single quote)"
+	}
+
+	if k.char < ' ' {
+		return "CTRL-" + string(k.char+'a'-1)
+	}
+
+	return "'" + string(k.char) + "'"
+}
+
+// Bind makes a key trigger an action. Use "none" as the action name to unbind
+// the key.
+func (k *Keymap) Bind(keyName string, actionName string) error {
+	parsedKey, err := parseKey(keyName)
+	if err != nil {
+		return err
+	}
+
+	var boundAction *action
+	if actionName != "none" {
+		boundAction = findAction(actionName)
+		if boundAction == nil {
+			return fmt.Errorf("Unknown action <%s>", actionName)
+		}
+	}
+
+	// Drop any old binding for this key
+	bindings := make([]keyBinding, 0, len(k.bindings)+1)
+	for _, binding := range k.bindings {
+		if binding.key != parsedKey {
+			bindings = append(bindings, binding)
+		}
+	}
+
+	if boundAction != nil {
+		bindings = append(bindings, keyBinding{key: parsedKey, action: boundAction})
+	}
+
+	k.bindings = bindings
+	return nil
+}
+
+// Returns nil if nothing is bound to this key
+func (k *Keymap) lookup(pressed key) *action {
+	for _, binding := range k.bindings {
+		if binding.key == pressed {
+			return binding.action
+		}
+	}
+	return nil
+}
+
+// All keys bound to the named action, in binding order
+func (k *Keymap) keysFor(actionName string) []key {
+	keys := []key{}
+	for _, binding := range k.bindings {
+		if binding.action.name == actionName {
+			keys = append(keys, binding.key)
+		}
+	}
+	return keys
+}
+
+// Describe how to trigger an action, like "'q' / ESC". Returns an empty string
+// if the action isn't bound.
+func (k *Keymap) describe(actionName string) string {
+	keyStrings := []string{}
+	for _, boundKey := range k.keysFor(actionName) {
+		keyStrings = append(keyStrings, boundKey.String())
+	}
+	return strings.Join(keyStrings, " / ")
+}
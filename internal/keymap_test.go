@@ -0,0 +1,91 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestParseKey(t *testing.T) {
+	parsed, err := parseKey("q")
+	assert.NilError(t, err)
+	assert.Equal(t, parsed, key{char: 'q'})
+
+	parsed, err = parseKey("ctrl-f")
+	assert.NilError(t, err)
+	assert.Equal(t, parsed, key{char: '\x06'})
+	assert.Equal(t, parsed.String(), "CTRL-f")
+
+	parsed, err = parseKey("PageDown")
+	assert.NilError(t, err)
+	assert.Equal(t, parsed, key{keyCode: twin.KeyPgDown})
+
+	parsed, err = parseKey("space")
+	assert.NilError(t, err)
+	assert.Equal(t, parsed.String(), "SPACE")
+
+	_, err = parseKey("ctrl-1")
+	assert.Error(t, err, "Unknown key <ctrl-1>")
+}
+
+func TestKeymapPresets(t *testing.T) {
+	for _, preset := range KeymapPresets {
+		keymap, err := NewKeymap(preset)
+		assert.NilError(t, err)
+		assert.Equal(t, keymap.lookup(key{char: 'q'}).name, "quit")
+	}
+
+	less, err := NewKeymap("less")
+	assert.NilError(t, err)
+	assert.Equal(t, less.lookup(key{char: '\x06'}).name, "page-down")
+
+	// CTRL-i and TAB are the same key
+	vim, err := NewKeymap("vim")
+	assert.NilError(t, err)
+	assert.Equal(t, vim.lookup(key{char: '\t'}).name, "jump-forward")
+	assert.Equal(t, vim.lookup(key{char: '\x17'}).name, "switch-pane")
+
+	_, err = NewKeymap("nano")
+	assert.Error(t, err, "Valid keymaps are default, less, vim, emacs")
+}
+
+func TestKeymapBind(t *testing.T) {
+	keymap, err := NewKeymap("default")
+	assert.NilError(t, err)
+
+	assert.NilError(t, keymap.Bind("w", "page-up"))
+	assert.Equal(t, keymap.lookup(key{char: 'w'}).name, "page-up")
+	assert.Equal(t, keymap.describe("toggle-wrap"), "")
+
+	assert.NilError(t, keymap.Bind("q", "none"))
+	assert.Assert(t, keymap.lookup(key{char: 'q'}) == nil)
+	assert.Equal(t, keymap.describe("quit"), "ESC")
+
+	assert.Error(t, keymap.Bind("q", "fly"), "Unknown action <fly>")
+}
+
+func TestHelpFollowsKeymap(t *testing.T) {
+	keymap, err := NewKeymap("default")
+	assert.NilError(t, err)
+	assert.NilError(t, keymap.Bind("ctrl-f", "toggle-wrap"))
+
+	help := helpText(keymap)
+	assert.Assert(t, strings.Contains(help, "* 'w' / CTRL-f: Toggle wrapping of long lines\n"), help)
+	assert.Equal(t, footerHints(keymap, false), "Press ESC / 'q' to exit, '/' to search, '&' to filter, 'h' for help")
+}
+
+func TestViewingModeUsesKeymap(t *testing.T) {
+	pager := NewPager(reader.NewFromTextForTesting("test", "a\nb\nc"))
+	assert.NilError(t, pager.Keymap.Bind("x", "toggle-wrap"))
+
+	pager.mode.onRune('x')
+	assert.Assert(t, pager.WrapLongLines)
+
+	pager.mode.onRune('h')
+	assert.Assert(t, pager.isShowingHelp)
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Assert(t, !pager.isShowingHelp)
+}
@@ -0,0 +1,319 @@
+package internal
+
+import (
+	"unicode"
+
+	"github.com/walles/moor/twin"
+)
+
+// A one line text editor, shared by all our prompts (search, filter, goto...).
+//
+// Supports moving the cursor, deleting words and browsing the history using the
+// up and down arrow keys.
+type lineEditor struct {
+	text   []rune
+	cursor int // Insertion point, 0 means before the first rune
+
+	// Can be nil, which means no up / down history browsing
+	history *inputHistory
+
+	// Where in the history we are. Equal to len(history.entries) when we're
+	// not browsing the history.
+	historyIndex int
+
+	// What the user typed before they started browsing the history
+	draft []rune
+
+	// If set, edits are only accepted if this function returns true for the
+	// resulting text
+	accept func(text string) bool
+
+	// Called after every change to the text
+	onChange func(text string)
+}
+
+func newLineEditor(history *inputHistory, onChange func(text string)) *lineEditor {
+	historyIndex := 0
+	if history != nil {
+		historyIndex = len(history.entries)
+	}
+
+	return &lineEditor{
+		history:      history,
+		historyIndex: historyIndex,
+		onChange:     onChange,
+	}
+}
+
+func (e *lineEditor) String() string {
+	return string(e.text)
+}
+
+func (e *lineEditor) cursorIsAtEnd() bool {
+	return e.cursor == len(e.text)
+}
+
+// Replace the text and put the cursor at the end of it
+func (e *lineEditor) setText(text string) {
+	runes := []rune(text)
+	e.update(runes, len(runes))
+}
+
+// Apply an edit, unless it's rejected by the accept function
+func (e *lineEditor) update(text []rune, cursor int) {
+	if e.accept != nil && !e.accept(string(text)) {
+		return
+	}
+
+	changed := string(text) != string(e.text)
+	e.text = text
+	e.cursor = cursor
+
+	if changed && e.onChange != nil {
+		e.onChange(string(e.text))
+	}
+}
+
+// Add the current text to the history and stop browsing it
+func (e *lineEditor) commitToHistory() {
+	if e.history == nil {
+		return
+	}
+
+	e.history.add(e.String())
+	e.historyIndex = len(e.history.entries)
+	e.draft = nil
+}
+
+// Returns true if the key was handled by the editor
+func (e *lineEditor) onKey(key twin.KeyCode) bool {
+	switch key {
+	case twin.KeyLeft:
+		e.moveCursor(e.cursor - 1)
+
+	case twin.KeyRight:
+		e.moveCursor(e.cursor + 1)
+
+	case twin.KeyAltLeft:
+		e.moveCursor(e.previousWordStart())
+
+	case twin.KeyAltRight:
+		e.moveCursor(e.nextWordEnd())
+
+	case twin.KeyHome:
+		e.moveCursor(0)
+
+	case twin.KeyEnd:
+		e.moveCursor(len(e.text))
+
+	case twin.KeyBackspace:
+		e.deleteBackwards()
+
+	case twin.KeyDelete:
+		e.deleteForwards()
+
+	case twin.KeyUp:
+		return e.historyPrevious()
+
+	case twin.KeyDown:
+		return e.historyNext()
+
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Returns true if the rune was handled by the editor
+func (e *lineEditor) onRune(char rune) bool {
+	switch char {
+	case '\x01': // CTRL-a
+		e.moveCursor(0)
+
+	case '\x05': // CTRL-e
+		e.moveCursor(len(e.text))
+
+	case '\x02': // CTRL-b
+		e.moveCursor(e.cursor - 1)
+
+	case '\x06': // CTRL-f
+		e.moveCursor(e.cursor + 1)
+
+	case '\x08': // CTRL-h / Backspace
+		e.deleteBackwards()
+
+	case '\x04': // CTRL-d
+		e.deleteForwards()
+
+	case '\x15': // CTRL-u, delete everything before the cursor
+		e.update(append([]rune{}, e.text[e.cursor:]...), 0)
+
+	case '\x0b': // CTRL-k, delete everything after the cursor
+		e.update(append([]rune{}, e.text[:e.cursor]...), e.cursor)
+
+	case '\x17': // CTRL-w, delete the word before the cursor
+		wordStart := e.previousWordStart()
+		newText := append([]rune{}, e.text[:wordStart]...)
+		newText = append(newText, e.text[e.cursor:]...)
+		e.update(newText, wordStart)
+
+	case '\x10': // CTRL-p
+		return e.historyPrevious()
+
+	case '\x0e': // CTRL-n
+		return e.historyNext()
+
+	default:
+		if !unicode.IsPrint(char) {
+			return false
+		}
+
+		newText := append([]rune{}, e.text[:e.cursor]...)
+		newText = append(newText, char)
+		newText = append(newText, e.text[e.cursor:]...)
+		e.update(newText, e.cursor+1)
+	}
+
+	return true
+}
+
+func (e *lineEditor) moveCursor(cursor int) {
+	if cursor < 0 {
+		cursor = 0
+	}
+	if cursor > len(e.text) {
+		cursor = len(e.text)
+	}
+	e.cursor = cursor
+}
+
+func (e *lineEditor) deleteBackwards() {
+	if e.cursor == 0 {
+		return
+	}
+
+	newText := append([]rune{}, e.text[:e.cursor-1]...)
+	newText = append(newText, e.text[e.cursor:]...)
+	e.update(newText, e.cursor-1)
+}
+
+func (e *lineEditor) deleteForwards() {
+	if e.cursor >= len(e.text) {
+		return
+	}
+
+	newText := append([]rune{}, e.text[:e.cursor]...)
+	newText = append(newText, e.text[e.cursor+1:]...)
+	e.update(newText, e.cursor)
+}
+
+// Skip whitespace backwards, then skip non-whitespace backwards
+func (e *lineEditor) previousWordStart() int {
+	i := e.cursor
+	for i > 0 && unicode.IsSpace(e.text[i-1]) {
+		i--
+	}
+	for i > 0 && !unicode.IsSpace(e.text[i-1]) {
+		i--
+	}
+	return i
+}
+
+// Skip whitespace forwards, then skip non-whitespace forwards
+func (e *lineEditor) nextWordEnd() int {
+	i := e.cursor
+	for i < len(e.text) && unicode.IsSpace(e.text[i]) {
+		i++
+	}
+	for i < len(e.text) && !unicode.IsSpace(e.text[i]) {
+		i++
+	}
+	return i
+}
+
+func (e *lineEditor) historyPrevious() bool {
+	if e.history == nil {
+		return false
+	}
+
+	if e.historyIndex <= 0 {
+		// Already at the oldest entry
+		return true
+	}
+
+	if e.historyIndex >= len(e.history.entries) {
+		e.draft = append([]rune{}, e.text...)
+		e.historyIndex = len(e.history.entries)
+	}
+
+	e.historyIndex--
+	e.setText(e.history.entries[e.historyIndex])
+	return true
+}
+
+func (e *lineEditor) historyNext() bool {
+	if e.history == nil {
+		return false
+	}
+
+	if e.historyIndex >= len(e.history.entries) {
+		// Not browsing the history, nothing newer available
+		return true
+	}
+
+	e.historyIndex++
+	if e.historyIndex == len(e.history.entries) {
+		e.setText(string(e.draft))
+		return true
+	}
+
+	e.setText(e.history.entries[e.historyIndex])
+	return true
+}
+
+// Draw the prompt followed by the text being edited on the given screen row,
+// with the cursor shown in reverse video.
+func (e *lineEditor) draw(screen twin.Screen, row int, prompt string) {
+	width, _ := screen.Size()
+
+	pos := 0
+	for _, token := range prompt {
+		pos += screen.SetCell(pos, row, twin.NewStyledRune(token, twin.StyleDefault))
+	}
+
+	// If the text doesn't fit, scroll it left until the cursor is visible. The
+	// -1 leaves room for the cursor at the end of the line.
+	available := width - pos - 1
+	firstVisible := 0
+	for firstVisible < e.cursor && cellWidth(e.text[firstVisible:e.cursor]) > available {
+		firstVisible++
+	}
+
+	cursorStyle := twin.StyleDefault.WithAttr(twin.AttrReverse)
+	for i := firstVisible; i < len(e.text); i++ {
+		style := twin.StyleDefault
+		if i == e.cursor {
+			style = cursorStyle
+		}
+		pos += screen.SetCell(pos, row, twin.NewStyledRune(e.text[i], style))
+	}
+
+	if e.cursorIsAtEnd() {
+		pos += screen.SetCell(pos, row, twin.NewStyledRune(' ', cursorStyle))
+	}
+
+	// Clear the rest of the line
+	for pos < width {
+		pos += screen.SetCell(pos, row, twin.NewStyledRune(' ', twin.StyleDefault))
+	}
+}
+
+// How many screen cells will these runes use?
+func cellWidth(runes []rune) int {
+	width := 0
+	for _, char := range runes {
+		width += twin.NewStyledRune(char, twin.StyleDefault).Width()
+	}
+	return width
+}
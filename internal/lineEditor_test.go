@@ -0,0 +1,130 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func typeString(e *lineEditor, s string) {
+	for _, char := range s {
+		e.onRune(char)
+	}
+}
+
+func TestLineEditorInsertInMiddle(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	typeString(e, "ac")
+	e.onKey(twin.KeyLeft)
+	typeString(e, "b")
+
+	assert.Equal(t, e.String(), "abc")
+	assert.Equal(t, e.cursor, 2)
+}
+
+func TestLineEditorDeleteWord(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	typeString(e, "hello big world")
+
+	e.onKey(twin.KeyAltLeft)
+	e.onRune('\x17') // CTRL-w
+	assert.Equal(t, e.String(), "hello world")
+	assert.Equal(t, e.cursor, len("hello "))
+
+	e.onRune('\x05') // CTRL-e
+	e.onRune('\x17') // CTRL-w
+	assert.Equal(t, e.String(), "hello ")
+}
+
+func TestLineEditorKillToStart(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	typeString(e, "abcdef")
+	e.onRune('\x01') // CTRL-a
+	e.onKey(twin.KeyRight)
+	e.onKey(twin.KeyRight)
+	e.onRune('\x15') // CTRL-u
+
+	assert.Equal(t, e.String(), "cdef")
+	assert.Equal(t, e.cursor, 0)
+}
+
+func TestLineEditorBackspaceAndDelete(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	typeString(e, "abcd")
+	e.onKey(twin.KeyLeft)
+	e.onKey(twin.KeyLeft)
+
+	e.onKey(twin.KeyBackspace)
+	assert.Equal(t, e.String(), "acd")
+
+	e.onKey(twin.KeyDelete)
+	assert.Equal(t, e.String(), "ad")
+	assert.Equal(t, e.cursor, 1)
+}
+
+func TestLineEditorOnChange(t *testing.T) {
+	changes := []string{}
+	e := newLineEditor(nil, func(text string) {
+		changes = append(changes, text)
+	})
+	typeString(e, "ab")
+	e.onKey(twin.KeyLeft) // Not a change
+
+	assert.DeepEqual(t, changes, []string{"a", "ab"})
+}
+
+func TestLineEditorAccept(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	e.accept = func(text string) bool {
+		return len(text) <= 2
+	}
+	typeString(e, "abc")
+
+	assert.Equal(t, e.String(), "ab")
+}
+
+func TestLineEditorHistory(t *testing.T) {
+	history := &inputHistory{entries: []string{"first", "second"}}
+	e := newLineEditor(history, nil)
+	typeString(e, "draft")
+
+	e.onKey(twin.KeyUp)
+	assert.Equal(t, e.String(), "second")
+	e.onKey(twin.KeyUp)
+	assert.Equal(t, e.String(), "first")
+	e.onKey(twin.KeyUp)
+	assert.Equal(t, e.String(), "first")
+
+	e.onKey(twin.KeyDown)
+	assert.Equal(t, e.String(), "second")
+	e.onKey(twin.KeyDown)
+	assert.Equal(t, e.String(), "draft")
+
+	e.commitToHistory()
+	assert.DeepEqual(t, history.entries, []string{"first", "second", "draft"})
+}
+
+func TestLineEditorNoHistory(t *testing.T) {
+	e := newLineEditor(nil, nil)
+	assert.Assert(t, !e.onKey(twin.KeyUp))
+}
+
+func TestInputHistoryPersistence(t *testing.T) {
+	stateHome := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", stateHome)
+
+	history := loadInputHistory("test_history")
+	history.add("one")
+	history.add("two")
+	history.add("one")
+
+	contents, err := os.ReadFile(filepath.Join(stateHome, "moor", "test_history"))
+	assert.NilError(t, err)
+	assert.Equal(t, string(contents), "two\none\n")
+
+	reloaded := loadInputHistory("test_history")
+	assert.DeepEqual(t, reloaded.entries, []string{"two", "one"})
+}
@@ -41,6 +41,12 @@ func assertWrap(t *testing.T, input string, widthInScreenCells int, wrappedLines
 		expected = append(expected, tokenize(wrappedLine))
 	}
 
+	// Wrapping doesn't change where URLs point, but a wrapped URL tokenized on
+	// its own would be auto-linked to only its own part. Don't compare link
+	// targets, TestWordWrapUrlKeepsLink checks those.
+	actual = withoutHyperlinks(actual)
+	expected = withoutHyperlinks(expected)
+
 	if reflect.DeepEqual(actual, expected) {
 		return
 	}
@@ -49,6 +55,18 @@ func assertWrap(t *testing.T, input string, widthInScreenCells int, wrappedLines
 		input, widthInScreenCells, rowsToString(expected), rowsToString(actual))
 }
 
+func withoutHyperlinks(lines [][]twin.StyledRune) [][]twin.StyledRune {
+	result := [][]twin.StyledRune{}
+	for _, line := range lines {
+		resultLine := []twin.StyledRune{}
+		for _, cell := range line {
+			resultLine = append(resultLine, twin.NewStyledRune(cell.Rune, cell.Style.WithHyperlink(nil)))
+		}
+		result = append(result, resultLine)
+	}
+	return result
+}
+
 func TestEnoughRoomNoWrapping(t *testing.T) {
 	assertWrap(t, "This is a test", 20, "This is a test")
 }
@@ -106,6 +124,13 @@ func TestWordWrapUrl(t *testing.T) {
 	assertWrap(t, "http://apa/bepa/", 3, "htt", "p:/", "/ap", "a/", "bep", "a/")
 }
 
+func TestWordWrapUrlKeepsLink(t *testing.T) {
+	wrapped := wrapLine(11, tokenize("http://apa/bepa/"))
+	assert.Equal(t, len(wrapped), 2)
+	assert.Equal(t, *wrapped[0][0].Style.HyperlinkURL(), "http://apa/bepa/")
+	assert.Equal(t, *wrapped[1][0].Style.HyperlinkURL(), "http://apa/bepa/")
+}
+
 func TestWordWrapMarkdownLink(t *testing.T) {
 	assertWrap(t, "[something](http://apa/bepa)", 13, "[something]", "(http://apa/", "bepa)")
 	assertWrap(t, "[something](http://apa/bepa)", 12, "[something]", "(http://apa/", "bepa)")
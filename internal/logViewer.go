@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+)
+
+// Show what has been logged so far in a buffer of its own, scrolled to the
+// latest line. Showing the log again refreshes the same buffer.
+func (p *Pager) showLog() {
+	if p.Logs == nil || len(p.Logs.String()) == 0 {
+		p.showStatusMessage(statusMessageInfo, "Nothing has been logged")
+		return
+	}
+
+	logReader, err := reader.NewFromStream("Log", strings.NewReader(p.Logs.String()), p.formatter(), p.readerOptions(nil))
+	if err != nil {
+		p.showError("Showing the log failed: " + err.Error())
+		return
+	}
+
+	logBufferIndex := -1
+	for index, b := range p.buffers {
+		if p.logReader != nil && b.reader == p.logReader {
+			logBufferIndex = index
+			break
+		}
+	}
+	p.logReader = logReader
+
+	if logBufferIndex < 0 {
+		p.openBuffer(logReader)
+	} else {
+		p.replaceBufferReader(logBufferIndex, logReader)
+		p.switchToBuffer(logBufferIndex)
+	}
+
+	// The lines are read in the background, follow them to the end
+	lastLine := linemetadata.IndexMax()
+	p.setTargetLine(&lastLine)
+}
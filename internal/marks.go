@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"slices"
+	"sort"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/util"
+	"github.com/walles/moor/twin"
+)
+
+// A mark and the line it is on
+type markedLine struct {
+	mark      rune
+	lineIndex linemetadata.Index
+}
+
+// Label the current position with a mark
+func (p *Pager) setMark(mark rune) {
+	// Canonicalize before storing, so that we can later find the marked line
+	// without rendering anything. We show marks while rendering.
+	position := p.scrollPosition
+	position.lineIndex(p)
+	p.marks[mark] = position
+}
+
+// Which line a mark is on, or nil if there were no lines when it was set
+func markLineIndex(position scrollPosition) *linemetadata.Index {
+	return position.internalDontTouch.lineIndex
+}
+
+// All marks, in document order. Marks on the same line are in alphabetical
+// order.
+func (p *Pager) markedLines() []markedLine {
+	marked := make([]markedLine, 0, len(p.marks))
+	for mark, position := range p.marks {
+		lineIndex := markLineIndex(position)
+		if lineIndex == nil {
+			continue
+		}
+		marked = append(marked, markedLine{mark: mark, lineIndex: *lineIndex})
+	}
+
+	sort.Slice(marked, func(i, j int) bool {
+		if marked[i].lineIndex != marked[j].lineIndex {
+			return marked[i].lineIndex.IsBefore(marked[j].lineIndex)
+		}
+		return marked[i].mark < marked[j].mark
+	})
+
+	return marked
+}
+
+// The first mark on the given line, or 0 if there is none
+func (p *Pager) markOnLine(lineIndex linemetadata.Index) rune {
+	var found rune
+	for mark, position := range p.marks {
+		markIndex := markLineIndex(position)
+		if markIndex == nil || *markIndex != lineIndex {
+			continue
+		}
+		if found == 0 || mark < found {
+			found = mark
+		}
+	}
+	return found
+}
+
+// Show the mark, if any, in the leftmost column of the line number gutter
+func (p *Pager) decorateMarkedLine(lineIndex linemetadata.Index, numberPrefixLength int, cells []twin.StyledRune) {
+	if numberPrefixLength == 0 || len(cells) == 0 || p.isShowingHelp {
+		return
+	}
+
+	mark := p.markOnLine(lineIndex)
+	if mark == 0 {
+		return
+	}
+
+	cells[0] = twin.NewStyledRune(mark, lineNumbersStyle.WithAttr(twin.AttrReverse))
+}
+
+func (p *Pager) markLineIndices() []linemetadata.Index {
+	indices := []linemetadata.Index{}
+	for _, marked := range p.markedLines() {
+		if len(indices) > 0 && indices[len(indices)-1] == marked.lineIndex {
+			continue
+		}
+		indices = append(indices, marked.lineIndex)
+	}
+	return indices
+}
+
+func (p *Pager) scrollToNextMark(count int) {
+	if len(p.marks) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No marks set, press 'm' to set one!")
+		return
+	}
+
+	if !p.scrollToNextOf(p.markLineIndices(), count, "scrollToNextMark") {
+		p.showStatusMessage(statusMessageInfo, "No more marks below")
+	}
+}
+
+func (p *Pager) scrollToPreviousMark(count int) {
+	if len(p.marks) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No marks set, press 'm' to set one!")
+		return
+	}
+
+	if !p.scrollToPreviousOf(p.markLineIndices(), count, "scrollToPreviousMark") {
+		p.showStatusMessage(statusMessageInfo, "No more marks above")
+	}
+}
+
+func marksTitle(count int) string {
+	if count == 1 {
+		return "1 mark"
+	}
+	return util.FormatInt(count) + " marks"
+}
+
+// List all marks in document order, picking one jumps to it
+func (p *Pager) showMarks() {
+	marked := p.markedLines()
+	if len(marked) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No marks set, press 'm' to set one!")
+		return
+	}
+
+	items := make([]string, 0, len(marked))
+	for _, m := range marked {
+		items = append(items, string(m.mark)+"  "+p.jumpDescription(m.lineIndex))
+	}
+
+	list := newPagerModeList(p, marksTitle(len(marked)), items, func(index int) {
+		p.rememberJump()
+		p.scrollPosition = p.marks[marked[index].mark]
+		p.setTargetLine(nil)
+	})
+	list.onDelete = func(index int) {
+		delete(p.marks, marked[index].mark)
+		marked = slices.Delete(marked, index, index+1)
+		list.title = marksTitle(len(marked))
+	}
+	p.mode = list
+}
@@ -0,0 +1,94 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func newMarksTestPager(t *testing.T) *Pager {
+	pager := newCountTestPager(t)
+	pager.marks = make(map[rune]scrollPosition)
+	return pager
+}
+
+func TestMarksInGutter(t *testing.T) {
+	pager := newMarksTestPager(t)
+	pager.ShowLineNumbers = true
+
+	typeRunes(pager, "2Gma")
+	typeRunes(pager, "<")
+	pager.redraw("")
+
+	screen := pager.screen.(*twin.FakeScreen)
+	assert.Equal(t, rowToString(screen.GetRow(0)), "  1 1")
+	assert.Equal(t, rowToString(screen.GetRow(1)), "a 2 2")
+	assert.Equal(t, screen.GetRow(1)[0].Style, lineNumbersStyle.WithAttr(twin.AttrReverse))
+}
+
+func TestNextAndPreviousMark(t *testing.T) {
+	pager := newMarksTestPager(t)
+
+	typeRunes(pager, "`")
+	assert.Equal(t, statusText(pager), "No marks set, press 'm' to set one!")
+
+	// Set out of document order
+	typeRunes(pager, "60Gma")
+	typeRunes(pager, "20Gmb")
+	typeRunes(pager, "40Gmc")
+
+	typeRunes(pager, "<`")
+	assert.Equal(t, pager.lineIndex().Index(), 19)
+	typeRunes(pager, "`")
+	assert.Equal(t, pager.lineIndex().Index(), 39)
+	typeRunes(pager, "`")
+	assert.Equal(t, pager.lineIndex().Index(), 59)
+	typeRunes(pager, "`")
+	assert.Equal(t, statusText(pager), "No more marks below")
+
+	typeRunes(pager, "2~")
+	assert.Equal(t, pager.lineIndex().Index(), 19)
+	typeRunes(pager, "~")
+	assert.Equal(t, statusText(pager), "No more marks above")
+}
+
+func TestListMarks(t *testing.T) {
+	pager := newMarksTestPager(t)
+
+	typeRunes(pager, "M")
+	assert.Equal(t, statusText(pager), "No marks set, press 'm' to set one!")
+
+	typeRunes(pager, "60Gma")
+	typeRunes(pager, "20Gmb")
+
+	typeRunes(pager, "M")
+	list, ok := pager.mode.(*PagerModeList)
+	assert.Assert(t, ok)
+	assert.Equal(t, list.title, "2 marks")
+	assert.DeepEqual(t, list.items, []string{"b  20: 20", "a  60: 60"})
+
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, pager.lineIndex().Index(), 59)
+}
+
+func TestDeleteMarks(t *testing.T) {
+	pager := newMarksTestPager(t)
+
+	typeRunes(pager, "60Gma")
+	typeRunes(pager, "20Gmb")
+
+	typeRunes(pager, "M")
+	list := pager.mode.(*PagerModeList)
+	typeRunes(pager, "d")
+	assert.Equal(t, list.title, "1 mark")
+	assert.DeepEqual(t, list.items, []string{"a  60: 60"})
+	assert.Equal(t, len(pager.marks), 1)
+
+	// Deleting the last mark closes the list
+	pager.mode.onKey(twin.KeyDelete)
+	assert.Equal(t, len(pager.marks), 0)
+	_, isViewing := pager.mode.(PagerModeViewing)
+	assert.Assert(t, isViewing)
+}
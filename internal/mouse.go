@@ -0,0 +1,127 @@
+package internal
+
+import (
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/twin"
+)
+
+func (p *Pager) onMouse(event twin.EventMouse) {
+	switch event.Buttons() {
+	case twin.MouseWheelUp:
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.PreviousLine(1)
+		return
+
+	case twin.MouseWheelDown:
+		// Clipping is done in _Redraw()
+		p.scrollPosition = p.scrollPosition.NextLine(1)
+		return
+
+	case twin.MouseWheelLeft:
+		p.moveRight(-p.SideScrollAmount)
+		return
+
+	case twin.MouseWheelRight:
+		p.moveRight(p.SideScrollAmount)
+		return
+	}
+
+	if event.Buttons() != twin.MouseButtonLeft {
+		return
+	}
+
+	column, row := event.Position()
+	if p.isSplit() && !p.isShowingHelp {
+		var isBottomPane bool
+		isBottomPane, row = p.paneAt(row)
+		if isBottomPane != p.isBottomPaneFocused {
+			if event.Action() != twin.MousePress {
+				// Dragging the scrollbar past the edge of the pane
+				if isBottomPane {
+					row += p.visibleHeight() + 1
+				} else {
+					row = -1
+				}
+			} else {
+				// Clicks in the other pane give it focus
+				p.switchPaneFocus()
+			}
+		}
+	}
+
+	headerHeight := p.headerScreenLineCount()
+	switch event.Action() {
+	case twin.MouseRelease:
+		p.isDraggingScrollbar = false
+		p.mouseSelectionAnchor = nil
+		if p.selection != nil {
+			p.copySelection()
+		}
+
+	case twin.MouseDrag:
+		if p.isDraggingScrollbar {
+			p.scrollToScrollbarRow(row - headerHeight)
+			return
+		}
+
+		if p.mouseSelectionAnchor == nil {
+			return
+		}
+		p.scrollTowardsRow(row - headerHeight)
+		lineIndex := p.lineIndexAtRow(row - headerHeight)
+		if lineIndex == nil {
+			return
+		}
+		if p.selection == nil && *lineIndex == *p.mouseSelectionAnchor {
+			// Still on the line where the drag started, not a selection yet
+			return
+		}
+		p.selection = &lineSelection{anchor: *p.mouseSelectionAnchor, cursor: *lineIndex}
+		p.mode = PagerModeSelect{pager: p}
+
+	case twin.MousePress:
+		p.cancelSelection()
+
+		if row >= headerHeight+p.visibleHeight() {
+			// Status bar click
+			return
+		}
+
+		if p.ShowScrollbar && column == p.scrollbarColumn() && row >= headerHeight {
+			p.isDraggingScrollbar = true
+			p.scrollToScrollbarRow(row - headerHeight)
+			return
+		}
+
+		url := p.hyperlinkAt(column, row)
+		if url != nil {
+			openURL(p.LinkOpener, *url)
+			return
+		}
+
+		if row >= headerHeight {
+			// Dragging from here will select lines
+			p.mouseSelectionAnchor = p.lineIndexAtRow(row - headerHeight)
+		}
+	}
+}
+
+// Returns the OSC 8 hyperlink URL at the given screen position, or nil if there
+// is none
+func (p *Pager) hyperlinkAt(column int, row int) *string {
+	screenLines, _ := p.renderScreenLines()
+	if row >= len(screenLines) {
+		return nil
+	}
+
+	cellColumn := 0
+	for _, cell := range screenLines[row] {
+		if column < cellColumn+cell.Width() {
+			return cell.Style.HyperlinkURL()
+		}
+		cellColumn += cell.Width()
+	}
+
+	log.Trace("No cell at screen column ", column, " of row ", row)
+	return nil
+}
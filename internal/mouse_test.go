@@ -0,0 +1,75 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// 100 lines, 10 lines visible
+func newScrollbarTestPager(t *testing.T) *Pager {
+	lines := make([]string, 0, 100)
+	for i := range 100 {
+		lines = append(lines, "line "+string(rune('a'+i%26)))
+	}
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestScrollbar", strings.Join(lines, "\n")), 20, 11)
+	pager.ShowScrollbar = true
+	return pager
+}
+
+func TestScrollbarThumb(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+
+	firstRow, rowCount := pager.scrollbarThumb()
+	assert.Equal(t, firstRow, 0)
+	assert.Equal(t, rowCount, 1)
+
+	pager.scrollToEnd()
+	firstRow, rowCount = pager.scrollbarThumb()
+	assert.Equal(t, firstRow, 9)
+	assert.Equal(t, rowCount, 1)
+}
+
+func TestScrollbarClickAndDrag(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+
+	// Click the middle of the scrollbar
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MousePress, 0, 19, 5))
+	assert.Equal(t, pager.lineIndex().Index(), 50)
+	assert.Assert(t, pager.isDraggingScrollbar)
+
+	// Drag it upwards, the mouse doesn't need to stay on the scrollbar
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseDrag, 0, 3, 2))
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseRelease, 0, 3, 2))
+	assert.Assert(t, !pager.isDraggingScrollbar)
+
+	// Not dragging any more, this should do nothing
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseDrag, 0, 19, 8))
+	assert.Equal(t, pager.lineIndex().Index(), 20)
+}
+
+func TestScrollbarReducesContentWidth(t *testing.T) {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestScrollbarReducesContentWidth", "0123456789"), 6, 4)
+	pager.ShowScrollbar = true
+	pager.WrapLongLines = true
+
+	lines, _ := pager.renderLines()
+	assert.Equal(t, len(lines), 2)
+	assert.Equal(t, rowToString(lines[0].cells), "01234")
+	assert.Equal(t, rowToString(lines[1].cells), "56789")
+}
+
+func TestHyperlinkAt(t *testing.T) {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestHyperlinkAt",
+		"Go to \x1b]8;;https://example.com\x1b\\example\x1b]8;;\x1b\\ now"), 40, 3)
+
+	assert.Assert(t, pager.hyperlinkAt(2, 0) == nil)
+	assert.Equal(t, *pager.hyperlinkAt(7, 0), "https://example.com")
+	assert.Assert(t, pager.hyperlinkAt(20, 0) == nil)
+	assert.Assert(t, pager.hyperlinkAt(2, 1) == nil)
+}
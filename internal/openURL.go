@@ -0,0 +1,47 @@
+package internal
+
+import (
+	"os/exec"
+	"runtime"
+	"runtime/debug"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+// Open a URL in the user's browser, or whatever the system thinks should handle
+// it. A non-empty opener command is used instead, with the URL added as its
+// last argument. Doesn't wait for the opener to finish.
+func openURL(opener string, url string) {
+	var command *exec.Cmd
+	switch {
+	case strings.TrimSpace(opener) != "":
+		commandWithArgs := append(strings.Fields(opener), url)
+		command = exec.Command(commandWithArgs[0], commandWithArgs[1:]...)
+	case runtime.GOOS == "darwin":
+		command = exec.Command("open", url)
+	case runtime.GOOS == "windows":
+		command = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		command = exec.Command("xdg-open", url)
+	}
+
+	log.Info("Opening URL: ", command.Args)
+	err := command.Start()
+	if err != nil {
+		log.Info("Failed to open ", url, ": ", err)
+		return
+	}
+
+	go func() {
+		defer func() {
+			PanicHandler("openURL()", recover(), debug.Stack())
+		}()
+
+		// Reap the opener process when it's done
+		err := command.Wait()
+		if err != nil {
+			log.Info("URL opener failed: ", command.Args, ": ", err)
+		}
+	}()
+}
@@ -4,7 +4,6 @@ import (
 	"fmt"
 	"math"
 	"regexp"
-	"runtime/debug"
 	"time"
 
 	"github.com/alecthomas/chroma/v2"
@@ -33,6 +32,7 @@ const (
 )
 
 type eventSpinnerUpdate struct {
+	buffer  *buffer
 	spinner string
 }
 
@@ -44,8 +44,14 @@ type eventMaybeDone struct{}
 
 // Pager is the main on-screen pager
 type Pager struct {
-	reader              *reader.ReaderImpl
-	filteringReader     FilteringReader
+	reader          *reader.ReaderImpl
+	filteringReader *FilteringReader
+
+	// All inputs we can page through, the current one is also in the reader
+	// field above
+	buffers            []*buffer
+	currentBufferIndex int
+
 	screen              twin.Screen
 	quit                bool
 	scrollPosition      scrollPosition
@@ -60,11 +66,59 @@ type Pager struct {
 	searchPattern *regexp.Regexp
 	filterPattern *regexp.Regexp
 
+	// Only show lines where shell commands were typed, see prompts.go
+	onlyCommands bool
+
+	// Hide unchanged diff context lines, see diff.go
+	onlyChanges bool
+
+	// The search hit we last scrolled to. Used for stepping through multiple
+	// hits on the same line when not wrapping long lines.
+	currentSearchHit *searchHit
+
+	// Link focused using the keyboard, see hyperlinks.go. nil means none.
+	focusedHyperlink *hyperlink
+
+	// Previous prompt inputs, browsable using the up and down arrow keys while
+	// in the prompt. Search and filter histories are persisted between runs.
+	searchHistory  *inputHistory
+	filterHistory  *inputHistory
+	gotoHistory    *inputHistory
+	commandHistory *inputHistory
+
+	// Shown in the status bar for a few seconds, see statusMessages.go. nil
+	// means no message.
+	statusMessage *statusMessage
+
+	// Tells the main loop when statusMessage expires. Stopped when paging
+	// ends.
+	statusMessageTimer *time.Timer
+
+	// For highlighting files opened from the command prompt. Set by
+	// StartPaging().
+	chromaStyle     *chroma.Style
+	chromaFormatter *chroma.Formatter
+
 	// We used to have a "Following" field here. If you want to follow, set
 	// TargetLineNumber to LineNumberMax() instead, see below.
 
+	// While the screen is split, this is the state of the pane that doesn't
+	// have focus. nil when not split.
+	otherPane *pane
+
+	// While split, p.screen is only the focused pane's part of this
+	wholeScreen         twin.Screen
+	isBottomPaneFocused bool
+
+	// Height of the top pane, including its status line
+	topPaneHeight int
+
 	isShowingHelp bool
 	preHelpState  *_PreHelpState
+	helpReader    *reader.ReaderImpl
+
+	// What keys do in viewing mode
+	Keymap *Keymap
 
 	// NewPager shows lines by default, this field can hide them
 	ShowLineNumbers bool
@@ -72,10 +126,58 @@ type Pager struct {
 	StatusBarStyle StatusBarOption
 	ShowStatusBar  bool
 
+	// Status bar templates, see ParseStatusFormat(). The filtering and
+	// following ones fall back to StatusFormat, which falls back to the
+	// default format.
+	StatusFormat          *StatusFormat
+	StatusFormatFiltering *StatusFormat
+	StatusFormatFollowing *StatusFormat
+
 	UnprintableStyle textstyles.UnprintableStyleT
 
 	WrapLongLines bool
 
+	// Sticky header lines stay at the top while scrolling vertically, and
+	// header columns stay at the left edge while scrolling sideways
+	HeaderLines   int
+	HeaderColumns int
+
+	// Show a scrollbar in the rightmost screen column
+	ShowScrollbar bool
+
+	// Command for opening links, the URL is added as its last argument. Empty
+	// means using the system's URL handler.
+	LinkOpener string
+
+	// Collected log lines, for showing them from inside the pager. nil means
+	// there are none.
+	Logs *LogWriter
+
+	// The reader showing Logs, nil if the logs haven't been shown
+	logReader *reader.ReaderImpl
+
+	// The reader showing the latest global search results, and the hits in
+	// it, one per line. nil if there has been no global search.
+	globalSearchReader *reader.ReaderImpl
+	globalSearchHits   []globalSearchHit
+
+	// True while the user is dragging the scrollbar thumb with the mouse
+	isDraggingScrollbar bool
+
+	// Digits typed before a command in viewing mode, like the "10" in "10j"
+	pendingCount string
+
+	// Lines selected for copying to the clipboard, nil when not selecting
+	selection *lineSelection
+
+	// Where the current mouse drag started, nil when not dragging
+	mouseSelectionAnchor *linemetadata.Index
+
+	// Search hit ticks for the scrollbar, and what we're currently computing
+	// in the background
+	scrollbarTicks        *scrollbarTicks
+	scrollbarTicksPending *scrollbarTicksKey
+
 	// Ref: https://github.com/walles/moor/issues/113
 	QuitIfOneScreen bool
 
@@ -85,6 +187,14 @@ type Pager struct {
 
 	SideScrollAmount int // Should be positive
 
+	// Whether to restore positions, marks, search and filter of previously
+	// viewed files
+	Remember RememberMode
+
+	// How search and filter strings are interpreted. Can be toggled from the
+	// search and filter prompts.
+	SearchOptions SearchOptions
+
 	// If non-nil, scroll to this line as soon as possible. Set this value to
 	// IndexMax() to follow the end of the input (tail).
 	//
@@ -110,6 +220,9 @@ type Pager struct {
 	// Ref: https://github.com/walles/moor/issues/175
 	marks map[rune]scrollPosition
 
+	// Where we were before recent "big" jumps, for going back there
+	jumpHistory jumpHistory
+
 	AfterExit func() error
 }
 
@@ -119,67 +232,6 @@ type _PreHelpState struct {
 	targetLine          *linemetadata.Index
 }
 
-var _HelpReader = reader.NewFromTextForTesting("Help", `
-Welcome to Moor, the nice pager!
-
-Miscellaneous
--------------
-* Press 'q' or 'ESC' to quit
-* Press 'w' to toggle wrapping of long lines
-* Press '=' to toggle showing the status bar at the bottom
-* Press 'v' to edit the file in your favorite editor
-
-Moving around
--------------
-* Arrow keys
-* Alt key plus left / right arrow steps one column at a time
-* Left / right can be used to hide / show line numbers
-* Home and End for start / end of the document
-* 'g' for going to a specific line number
-* 'm' sets a mark, you will be asked for a letter to label it with
-* ' (single quote) jumps to the mark
-* CTRL-p moves to the previous line
-* CTRL-n moves to the next line
-* PageUp / 'b' and PageDown / 'f'
-* SPACE moves down a page
-* < / 'gg' to go to the start of the document
-* > / 'G' to go to the end of the document
-* Half page 'u'p / 'd'own, or CTRL-u / CTRL-d
-* RETURN moves down one line
-
-Filtering
----------
-Type '&' to start filtering, then type your filter expression.
-
-While filtering, arrow keys, PageUp, PageDown, Home and End work as usual.
-
-Press 'ESC' or RETURN to exit filtering mode.
-
-Searching
----------
-* Type / to start searching, then type what you want to find
-* Type ? to search backwards, then type what you want to find
-* Type RETURN to stop searching, or ESC to skip back to where the search started
-* Find next by typing 'n' (for "next")
-* Find previous by typing SHIFT-N or 'p' (for "previous")
-* Search is case sensitive if it contains any UPPER CASE CHARACTERS
-* Search is interpreted as a regexp if it is a valid one
-
-Reporting bugs
---------------
-File issues at https://github.com/walles/moor/issues, or post
-questions to johan.walles@gmail.com.
-
-Installing Moor as your default pager
--------------------------------------
-Put the following line in your ~/.bashrc, ~/.bash_profile or ~/.zshrc:
-  export PAGER=moor
-
-Source Code
------------
-Available at https://github.com/walles/moor/.
-`)
-
 // NewPager creates a new Pager with default settings
 func NewPager(r *reader.ReaderImpl) *Pager {
 	var name string
@@ -199,27 +251,112 @@ func NewPager(r *reader.ReaderImpl) *Pager {
 		ScrollLeftHint:   twin.NewStyledRune('<', twin.StyleDefault.WithAttr(twin.AttrReverse)),
 		ScrollRightHint:  twin.NewStyledRune('>', twin.StyleDefault.WithAttr(twin.AttrReverse)),
 		scrollPosition:   newScrollPosition(name),
+		searchHistory:    &inputHistory{},
+		filterHistory:    &inputHistory{},
+		gotoHistory:      &inputHistory{},
+		commandHistory:   &inputHistory{},
 	}
+	pager.buffers = []*buffer{{reader: r}}
 
-	pager.mode = PagerModeViewing{pager: &pager}
-	pager.filteringReader = FilteringReader{
-		BackingReader: r,
-		FilterPattern: &pager.filterPattern,
+	keymap, err := NewKeymap("default")
+	if err != nil {
+		panic(err)
 	}
+	pager.Keymap = keymap
+
+	pager.mode = PagerModeViewing{pager: &pager}
+	pager.filteringReader = pager.newFilteringReader(r)
 
 	return &pager
 }
 
-// How many lines are visible on screen? Depends on screen height and whether or
-// not the status bar is visible.
+// How many scrolling lines are visible on screen? Depends on screen height,
+// whether or not the status bar is visible, and the number of header lines.
 func (p *Pager) visibleHeight() int {
+	return p.linesHeight() - p.headerScreenLineCount()
+}
+
+// How many sticky header lines are visible on screen?
+func (p *Pager) visibleHeaderLineCount() int {
+	return p.headerLineCount(p.linesHeight())
+}
+
+// How many screen lines the sticky header lines use. With wrapping, this can be
+// more than the number of header lines.
+func (p *Pager) headerScreenLineCount() int {
+	count := p.visibleHeaderLineCount()
+	if count == 0 || !p.WrapLongLines {
+		return count
+	}
+
+	screenLineCount := 0
+	for i := 0; i < count; i++ {
+		line := p.reader.GetLine(linemetadata.IndexFromZeroBased(i))
+		if line == nil {
+			break
+		}
+		screenLineCount += len(p.wrapHeaderLine(line.HighlightedTokens(plainTextStyle, standoutStyle, p.searchPattern).StyledRunes))
+	}
+
+	// Leave room for at least one scrolling line
+	return min(screenLineCount, max(p.linesHeight()-1, 0))
+}
+
+// Screen height minus the status bar, if any
+func (p *Pager) linesHeight() int {
 	_, height := p.screen.Size()
-	if p.ShowStatusBar {
+	if p.ShowStatusBar || p.isSplit() {
+		// Split panes always have status lines, otherwise you couldn't tell
+		// where one pane ends and the next one starts
 		return height - 1
 	}
 	return height
 }
 
+// How many sticky header lines to show above the scrolling lines, given the
+// screen height available for both.
+func (p *Pager) headerLineCount(height int) int {
+	if p.HeaderLines <= 0 || p.isShowingHelp {
+		return 0
+	}
+
+	if p.reader.GetLineCount() <= p.HeaderLines {
+		// Everything fits in the header, nothing to scroll
+		return 0
+	}
+
+	// Leave room for at least one scrolling line
+	if p.HeaderLines > height-1 {
+		return max(height-1, 0)
+	}
+
+	return p.HeaderLines
+}
+
+// The header lines are never scrolled into, except while filtering since then
+// the filtered lines don't include the header
+func (p *Pager) firstScrollableIndex() linemetadata.Index {
+	if p.isFiltering() {
+		return linemetadata.Index{}
+	}
+
+	return linemetadata.IndexFromZeroBased(p.visibleHeaderLineCount())
+}
+
+func (p *Pager) isFiltering() bool {
+	return p.onlyCommands || p.onlyChanges || (p.filterPattern != nil && len(p.filterPattern.String()) > 0)
+}
+
+// How many columns are available for the file contents? Depends on screen width
+// and whether or not the scrollbar is visible.
+func (p *Pager) contentWidth() int {
+	width, _ := p.screen.Size()
+	if p.ShowScrollbar {
+		return width - 1
+	}
+	return width
+}
+
 // How many cells are needed for this line number?
 //
 // Returns 0 if line numbers are disabled.
@@ -229,6 +366,10 @@ func (p *Pager) getLineNumberPrefixLength(lineNumber linemetadata.Number) int {
 	}
 
 	length := len(lineNumber.Format()) + 1 // +1 for the space after the line number
+	if len(p.marks) > 0 && !p.isShowingHelp {
+		// Room for showing marks to the left of the line numbers
+		length++
+	}
 
 	if length < 4 {
 		// 4 = space for 3 digits followed by one whitespace
@@ -242,15 +383,19 @@ func (p *Pager) getLineNumberPrefixLength(lineNumber linemetadata.Number) int {
 
 // Draw the footer string at the bottom using the status bar style
 func (p *Pager) setFooter(footer string) {
+	p.setFooterWithStyle(footer, statusbarStyle)
+}
+
+func (p *Pager) setFooterWithStyle(footer string, style twin.Style) {
 	width, height := p.screen.Size()
 
 	pos := 0
 	for _, token := range footer {
-		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(token, statusbarStyle))
+		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(token, style))
 	}
 
 	for pos < width {
-		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', statusbarStyle))
+		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', style))
 	}
 }
 
@@ -297,9 +442,9 @@ func (p *Pager) moveRight(delta int) {
 
 func (p *Pager) Reader() reader.Reader {
 	if p.isShowingHelp {
-		return _HelpReader
+		return p.helpReader
 	}
-	return &p.filteringReader
+	return p.filteringReader
 }
 
 func (p *Pager) handleScrolledUp() {
@@ -340,13 +485,38 @@ func (p *Pager) setTargetLine(targetLine *linemetadata.Index) {
 	p.reader.SetPauseAfterLines(targetValue)
 }
 
+// If we have a target line, scroll as close to it as the available lines allow
+func (p *Pager) scrollTowardsTargetLine() {
+	if p.TargetLine == nil {
+		return
+	}
+
+	lastIndex := linemetadata.IndexFromLength(p.Reader().GetLineCount())
+	if lastIndex == nil {
+		// No lines yet, we'll get here again when they arrive
+		return
+	}
+
+	// The user wants to scroll down to a specific line number
+	if lastIndex.IsBefore(*p.TargetLine) {
+		// Not there yet, keep scrolling
+		p.scrollToEnd()
+	} else {
+		// We see the target, scroll to it
+		p.scrollPosition = NewScrollPositionFromIndex(*p.TargetLine, "goToTargetLine")
+		p.setTargetLine(nil)
+	}
+}
+
 // StartPaging brings up the pager on screen
 func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chromaFormatter *chroma.Formatter) {
 	log.Info("Pager starting")
 
 	defer func() {
-		if p.reader.Err != nil {
-			log.Warnf("Reader reported an error: %s", p.reader.Err.Error())
+		for _, b := range p.buffers {
+			if b.reader.Err != nil {
+				log.Warnf("Reader for %s reported an error: %s", bufferName(b.reader), b.reader.Err.Error())
+			}
 		}
 	}()
 
@@ -355,70 +525,36 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 	styleUI(chromaStyle, chromaFormatter, p.StatusBarStyle, p.WithTerminalFg)
 
 	p.screen = screen
+	p.chromaStyle = chromaStyle
+	p.chromaFormatter = chromaFormatter
 	p.mode = PagerModeViewing{pager: p}
 	p.marks = make(map[rune]scrollPosition)
+	p.searchHistory = loadInputHistory("search_history")
+	p.filterHistory = loadInputHistory("filter_history")
+	p.commandHistory = loadInputHistory("command_history")
+	p.restoreFileStates()
+	defer p.saveFileStates()
+	defer p.stopStatusMessageTimer()
 
 	// Make sure the reader knows how many lines we want
 	p.setTargetLine(p.TargetLine)
 
-	go func() {
-		defer func() {
-			PanicHandler("StartPaging()/moreLinesAvailable", recover(), debug.Stack())
-		}()
-
-		for range p.reader.MoreLinesAdded {
-			// Notify the main loop about the new lines so it can show them
-			screen.Events() <- eventMoreLinesAvailable{}
-
-			// Delay updates a bit so that we don't waste time refreshing
-			// the screen too often.
-			//
-			// Note that the delay is *after* reacting, this way single-line
-			// updates are reacted to immediately, and the first output line
-			// read will appear on screen without delay.
-			time.Sleep(200 * time.Millisecond)
-		}
-	}()
-
-	go func() {
-		defer func() {
-			PanicHandler("StartPaging()/spinner", recover(), debug.Stack())
-		}()
-
-		// Spin the spinner as long as contents is still loading
-		spinnerFrames := [...]string{"/.\\", "-o-", "\\O/", "| |"}
-		spinnerIndex := 0
-		for !p.reader.Done.Load() {
-			screen.Events() <- eventSpinnerUpdate{spinnerFrames[spinnerIndex]}
-			spinnerIndex++
-			if spinnerIndex >= len(spinnerFrames) {
-				spinnerIndex = 0
-			}
-
-			time.Sleep(200 * time.Millisecond)
-		}
-
-		// Empty our spinner, loading done!
-		screen.Events() <- eventSpinnerUpdate{""}
-	}()
-
-	go func() {
-		defer func() {
-			PanicHandler("StartPaging()/maybeDone", recover(), debug.Stack())
-		}()
+	for _, b := range p.buffers {
+		p.watchBuffer(b)
+	}
 
-		for range p.reader.MaybeDone {
-			screen.Events() <- eventMaybeDone{}
-		}
-	}()
+	if p.statusFormatHasClock() {
+		go tickClock(screen.Events())
+	}
 
 	log.Info("Entering pager main loop...")
 
 	// Main loop
-	spinner := ""
 	for !p.quit {
 		if len(screen.Events()) == 0 {
 			// Nothing more to process for now, redraw the screen
+			spinner := p.currentBuffer().spinner
+			p.updatePaneScreens()
 			p.redraw(spinner)
 
 			// Ref:
@@ -426,7 +562,7 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 			//
 			// Note that we do the slow (atomic) checks only if the fast ones (no locking
 			// required) passed
-			if p.QuitIfOneScreen && !p.isShowingHelp && p.reader.Done.Load() && p.reader.HighlightingDone.Load() {
+			if p.QuitIfOneScreen && !p.isShowingHelp && len(p.buffers) == 1 && p.reader.Done.Load() && p.reader.HighlightingDone.Load() {
 				width, height := p.screen.Size()
 				if fitsOnOneScreen(p.reader, width, height-p.DeInitFalseMargin) {
 					// Ref:
@@ -446,6 +582,11 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 		}
 
 		event := <-screen.Events()
+
+		// The screen may have been resized, or help may have been shown or
+		// hidden
+		p.updatePaneScreens()
+
 		switch event := event.(type) {
 		case twin.EventKeyCode:
 			log.Tracef("Handling key event %d...", event.KeyCode())
@@ -457,21 +598,7 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 
 		case twin.EventMouse:
 			log.Tracef("Handling mouse event %d...", event.Buttons())
-			switch event.Buttons() {
-			case twin.MouseWheelUp:
-				// Clipping is done in _Redraw()
-				p.scrollPosition = p.scrollPosition.PreviousLine(1)
-
-			case twin.MouseWheelDown:
-				// Clipping is done in _Redraw()
-				p.scrollPosition = p.scrollPosition.NextLine(1)
-
-			case twin.MouseWheelLeft:
-				p.moveRight(-p.SideScrollAmount)
-
-			case twin.MouseWheelRight:
-				p.moveRight(p.SideScrollAmount)
-			}
+			p.onMouse(event)
 
 		case twin.EventResize:
 			// We'll be implicitly redrawn just by taking another lap in the loop
@@ -481,24 +608,33 @@ func (p *Pager) StartPaging(screen twin.Screen, chromaStyle *chroma.Style, chrom
 			return
 
 		case eventMoreLinesAvailable:
-			if p.TargetLine != nil {
-				// The user wants to scroll down to a specific line number
-				if linemetadata.IndexFromLength(p.Reader().GetLineCount()).IsBefore(*p.TargetLine) {
-					// Not there yet, keep scrolling
-					p.scrollToEnd()
-				} else {
-					// We see the target, scroll to it
-					p.scrollPosition = NewScrollPositionFromIndex(*p.TargetLine, "goToTargetLine")
-					p.setTargetLine(nil)
-				}
-			}
+			p.scrollTowardsTargetLine()
+			p.scrollOtherPaneTowardsTargetLine()
 
 		case eventMaybeDone:
 			// Do nothing. We got this just so that we'll do the QuitIfOneScreen
 			// check (above) as soon as highlighting is done.
 
+		case eventClockTick:
+			// Do nothing. We'll redraw the status bar clock on the next lap.
+
 		case eventSpinnerUpdate:
-			spinner = event.spinner
+			event.buffer.spinner = event.spinner
+
+		case eventScrollbarTicks:
+			p.onScrollbarTicks(event.ticks)
+
+		case eventBufferOpened:
+			p.openBuffer(event.reader)
+
+		case eventGlobalSearchHits:
+			p.onGlobalSearchHits(event)
+
+		case eventStatusMessage:
+			p.showStatusMessage(event.level, event.text)
+
+		case eventStatusMessageExpired:
+			// Do nothing. The expired message will be gone on the next redraw.
 
 		case twin.EventTerminalBackgroundDetected:
 			// Do nothing, we don't care about background color updates
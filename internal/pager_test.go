@@ -6,6 +6,7 @@ import (
 	"path"
 	"regexp"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -98,15 +99,33 @@ func TestBrokenUtf8(t *testing.T) {
 	}
 }
 
-func startPaging(t *testing.T, reader *reader.ReaderImpl) *twin.FakeScreen {
+// Create a pager for the reader on a FakeScreen of the given size, without line
+// numbers. Waits for the reader, but doesn't draw anything.
+func newTestPager(t *testing.T, reader *reader.ReaderImpl, width int, height int) (*Pager, *twin.FakeScreen) {
 	err := reader.Wait()
 	if err != nil {
 		t.Fatalf("Failed waiting for reader: %v", err)
 	}
 
-	screen := twin.NewFakeScreen(20, 10)
+	screen := twin.NewFakeScreen(width, height)
 	pager := NewPager(reader)
 	pager.ShowLineNumbers = false
+	pager.screen = screen
+
+	return pager, screen
+}
+
+// The numbers from 1 to count, one per line
+func numberLines(count int) string {
+	lines := make([]string, 0, count)
+	for i := 1; i <= count; i++ {
+		lines = append(lines, strconv.Itoa(i))
+	}
+	return strings.Join(lines, "\n")
+}
+
+func startPaging(t *testing.T, reader *reader.ReaderImpl) *twin.FakeScreen {
+	pager, screen := newTestPager(t, reader, 20, 10)
 
 	// Tell our Pager to quit immediately
 	pager.Quit()
@@ -122,14 +141,7 @@ func startPaging(t *testing.T, reader *reader.ReaderImpl) *twin.FakeScreen {
 
 // Set style to "native" and use the TTY16m formatter
 func startPagingWithTerminalFg(t *testing.T, reader *reader.ReaderImpl, withTerminalFg bool) *twin.FakeScreen {
-	err := reader.Wait()
-	if err != nil {
-		t.Fatalf("Failed waiting for reader: %v", err)
-	}
-
-	screen := twin.NewFakeScreen(20, 10)
-	pager := NewPager(reader)
-	pager.ShowLineNumbers = false
+	pager, screen := newTestPager(t, reader, 20, 10)
 	pager.WithTerminalFg = withTerminalFg
 
 	// Tell our Pager to quit immediately
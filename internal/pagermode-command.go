@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/twin"
+)
+
+// The prompt for commands like "open FILE" or "set nowrap", see commands.go
+type PagerModeCommand struct {
+	pager *Pager
+
+	inputBox *lineEditor
+
+	// While cycling through completions using TAB. The prefix is the command
+	// line before the word being completed. completionIndex is -1 before the
+	// first completion has been picked.
+	completionPrefix string
+	completions      []string
+	completionIndex  int
+}
+
+func newPagerModeCommand(p *Pager) *PagerModeCommand {
+	return &PagerModeCommand{
+		pager:    p,
+		inputBox: newLineEditor(p.commandHistory, nil),
+	}
+}
+
+func (m *PagerModeCommand) drawFooter(_ string, _ string) {
+	p := m.pager
+
+	width, height := p.screen.Size()
+	m.inputBox.draw(p.screen, height-1, ":")
+
+	if len(m.completions) < 2 {
+		return
+	}
+
+	// List the candidates above the prompt
+	pos := 0
+	for i, completion := range m.completions {
+		style := statusbarStyle
+		if i == m.completionIndex {
+			style = style.WithAttr(twin.AttrBold)
+		}
+
+		for _, token := range strings.TrimSpace(completion) + "  " {
+			pos += p.screen.SetCell(pos, height-2, twin.NewStyledRune(token, style))
+		}
+	}
+	for pos < width {
+		pos += p.screen.SetCell(pos, height-2, twin.NewStyledRune(' ', statusbarStyle))
+	}
+}
+
+func (m *PagerModeCommand) onKey(key twin.KeyCode) {
+	p := m.pager
+	m.completions = nil
+
+	switch key {
+	case twin.KeyEnter:
+		m.inputBox.commitToHistory()
+		p.mode = PagerModeViewing{pager: p}
+
+		// Don't leave an earlier command's error up after this one worked
+		p.statusMessage = nil
+
+		err := p.runCommand(m.inputBox.String())
+		if err != nil {
+			p.showError(err.Error())
+		}
+
+	case twin.KeyEscape:
+		p.mode = PagerModeViewing{pager: p}
+
+	case twin.KeyBackspace:
+		if len(m.inputBox.text) == 0 {
+			// Like in bash, backspacing past the start of the prompt leaves it
+			p.mode = PagerModeViewing{pager: p}
+			return
+		}
+		m.inputBox.onKey(key)
+
+	default:
+		m.inputBox.onKey(key)
+	}
+}
+
+func (m *PagerModeCommand) onRune(char rune) {
+	if char == '\t' {
+		m.complete()
+		return
+	}
+
+	m.completions = nil
+	m.inputBox.onRune(char)
+}
+
+// Complete the word at the end of the command line. If there are multiple
+// candidates, complete as far as they agree, then cycle through them on
+// repeated presses.
+func (m *PagerModeCommand) complete() {
+	if !m.inputBox.cursorIsAtEnd() {
+		return
+	}
+
+	if m.completions != nil {
+		m.completionIndex = (m.completionIndex + 1) % len(m.completions)
+		m.inputBox.setText(m.completionPrefix + m.completions[m.completionIndex])
+		return
+	}
+
+	prefix, candidates := completeCommandLine(m.pager, m.inputBox.String())
+	if len(candidates) == 0 {
+		return
+	}
+
+	m.inputBox.setText(prefix + commonPrefix(candidates))
+	if len(candidates) == 1 {
+		return
+	}
+
+	m.completionPrefix = prefix
+	m.completions = candidates
+	m.completionIndex = -1
+}
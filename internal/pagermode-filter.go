@@ -2,36 +2,41 @@ package internal
 
 import (
 	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
 	"github.com/walles/moor/twin"
 )
 
 type PagerModeFilter struct {
-	pager        *Pager
-	filterString string
+	pager    *Pager
+	inputBox *lineEditor
 }
 
-func (m PagerModeFilter) drawFooter(_ string, _ string) {
-	width, height := m.pager.screen.Size()
-
-	prompt := "Filter: "
+func newPagerModeFilter(p *Pager) *PagerModeFilter {
+	m := &PagerModeFilter{pager: p}
+	m.inputBox = newLineEditor(p.filterHistory, func(text string) {
+		m.pager.searchString = text
+		m.updateFilterPattern()
+	})
+	return m
+}
 
-	pos := 0
-	for _, token := range prompt + m.filterString {
-		pos += m.pager.screen.SetCell(pos, height-1, twin.NewStyledRune(token, twin.StyleDefault))
-	}
+func (m *PagerModeFilter) updateFilterPattern() {
+	pattern := m.pager.SearchOptions.toPattern(m.pager.searchString)
+	m.pager.filterPattern = pattern
+	m.pager.searchPattern = pattern
+}
 
-	// Add a cursor
-	pos += m.pager.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', twin.StyleDefault.WithAttr(twin.AttrReverse)))
+func (m PagerModeFilter) drawFooter(_ string, _ string) {
+	_, height := m.pager.screen.Size()
 
-	// Clear the rest of the line
-	for pos < width {
-		pos += m.pager.screen.SetCell(pos, height-1, twin.NewStyledRune(' ', twin.StyleDefault))
-	}
+	prompt := m.pager.SearchOptions.prompt("Filter", m.pager.searchString)
+	m.inputBox.draw(m.pager.screen, height-1, prompt)
 }
 
 func (m *PagerModeFilter) onKey(key twin.KeyCode) {
 	switch key {
 	case twin.KeyEnter:
+		m.inputBox.commitToHistory()
 		m.pager.mode = PagerModeViewing{pager: m.pager}
 
 	case twin.KeyEscape:
@@ -40,40 +45,51 @@ func (m *PagerModeFilter) onKey(key twin.KeyCode) {
 		m.pager.searchString = ""
 		m.pager.searchPattern = nil
 
-	case twin.KeyBackspace, twin.KeyDelete:
-		if len(m.filterString) == 0 {
-			return
-		}
-
-		m.filterString = removeLastChar(m.filterString)
-		m.pager.filterPattern = toPattern(m.filterString)
-		m.pager.searchString = m.filterString
-		m.pager.searchPattern = toPattern(m.filterString)
-
-	case twin.KeyUp, twin.KeyDown, twin.KeyRight, twin.KeyLeft, twin.KeyPgUp, twin.KeyPgDown, twin.KeyHome, twin.KeyEnd:
+	case twin.KeyPgUp, twin.KeyPgDown:
 		viewing := PagerModeViewing{pager: m.pager}
 
 		// Scroll up / down
 		viewing.onKey(key)
 
 	default:
-		log.Debugf("Unhandled filter key event %v", key)
+		if m.pager.SearchOptions.onKey(key) {
+			m.updateFilterPattern()
+			return
+		}
+
+		if !m.inputBox.onKey(key) {
+			log.Debugf("Unhandled filter key event %v", key)
+		}
 	}
 }
 
 func (m *PagerModeFilter) onRune(char rune) {
-	if char == '\x08' {
-		// Backspace
-		if len(m.filterString) == 0 {
-			return
+	if !m.inputBox.onRune(char) {
+		log.Debugf("Unhandled filter rune '%s'/0x%08x", string(char), int32(char))
+	}
+}
+
+// Change what we filter on, while still showing the same part of the input.
+// Line numbers stay the same when filtering, line indices don't.
+func (p *Pager) changeFilterKeepingPosition(change func()) {
+	var topNumber *linemetadata.Number
+	if p.lineIndex() != nil {
+		if line := p.Reader().GetLine(*p.lineIndex()); line != nil {
+			topNumber = &line.Number
 		}
+	}
+
+	change()
 
-		m.filterString = removeLastChar(m.filterString)
-	} else {
-		m.filterString = m.filterString + string(char)
+	if topNumber == nil {
+		return
 	}
 
-	m.pager.filterPattern = toPattern(m.filterString)
-	m.pager.searchString = m.filterString
-	m.pager.searchPattern = toPattern(m.filterString)
+	for _, line := range p.Reader().GetLines(linemetadata.Index{}, p.Reader().GetLineCount()).Lines {
+		if !line.Number.IsBefore(*topNumber) {
+			p.scrollPosition = NewScrollPositionFromIndex(line.Index, "changeFilterKeepingPosition")
+			p.setTargetLine(nil)
+			return
+		}
+	}
 }
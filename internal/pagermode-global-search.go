@@ -0,0 +1,331 @@
+package internal
+
+import (
+	"regexp"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode/utf8"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/internal/textstyles"
+	"github.com/walles/moor/internal/util"
+	"github.com/walles/moor/twin"
+)
+
+// Listing more hits than this is unlikely to be useful, and would use lots of
+// memory for large inputs
+const maxGlobalSearchHits = 10_000
+
+type globalSearchHit struct {
+	bufferIndex int
+	line        *reader.NumberedLine
+
+	// Where in the search results this hit is, like "file.txt:12"
+	reference textstyles.FileReference
+}
+
+// Search for a pattern in all buffers, listing hits across all of them
+type PagerModeGlobalSearch struct {
+	pager    *Pager
+	inputBox *lineEditor
+}
+
+func newPagerModeGlobalSearch(p *Pager) *PagerModeGlobalSearch {
+	return &PagerModeGlobalSearch{
+		pager:    p,
+		inputBox: newLineEditor(p.searchHistory, nil),
+	}
+}
+
+func (m *PagerModeGlobalSearch) drawFooter(_ string, _ string) {
+	_, height := m.pager.screen.Size()
+
+	prompt := m.pager.SearchOptions.prompt("Search all buffers", m.inputBox.String())
+	m.inputBox.draw(m.pager.screen, height-1, prompt)
+}
+
+func (m *PagerModeGlobalSearch) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyEnter:
+		m.inputBox.commitToHistory()
+		p.mode = PagerModeViewing{pager: p}
+		p.searchString = m.inputBox.String()
+		p.searchPattern = p.SearchOptions.toPattern(p.searchString)
+		p.listGlobalSearchHits()
+
+	case twin.KeyEscape:
+		p.mode = PagerModeViewing{pager: p}
+
+	default:
+		if p.SearchOptions.onKey(key) {
+			return
+		}
+
+		if !m.inputBox.onKey(key) {
+			log.Debugf("Unhandled global search key event %v", key)
+		}
+	}
+}
+
+func (m *PagerModeGlobalSearch) onRune(char rune) {
+	if !m.inputBox.onRune(char) {
+		log.Debugf("Unhandled global search rune '%s'/0x%08x", string(char), int32(char))
+	}
+}
+
+// A global search running in the background
+type globalSearch struct {
+	searchString string
+
+	// Set when the user doesn't want the results any more
+	cancelled atomic.Bool
+}
+
+// Background global search results are ready
+type eventGlobalSearchHits struct {
+	search *globalSearch
+	hits   []globalSearchHit
+}
+
+// Waiting for a global search to finish
+type PagerModeGlobalSearching struct {
+	pager  *Pager
+	search *globalSearch
+}
+
+func (m PagerModeGlobalSearching) drawFooter(_ string, _ string) {
+	m.pager.setFooter("Searching all buffers for \"" + m.search.searchString + "\"...")
+}
+
+// Any key press cancels the search, and is then handled as usual
+func (m PagerModeGlobalSearching) onKey(key twin.KeyCode) {
+	m.search.cancelled.Store(true)
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+	m.pager.mode.onKey(key)
+}
+
+func (m PagerModeGlobalSearching) onRune(char rune) {
+	m.search.cancelled.Store(true)
+	m.pager.mode = PagerModeViewing{pager: m.pager}
+	m.pager.mode.onRune(char)
+}
+
+// Search all buffers for the current search pattern in the background. When
+// done, the hits are shown in a search results buffer. Pressing ENTER on a hit
+// there opens its buffer at the hit line.
+func (p *Pager) listGlobalSearchHits() {
+	if p.searchPattern == nil {
+		return
+	}
+
+	// Search what the user sees, so that while filtering only lines matching
+	// the filter are listed. Earlier search results are not searched, those
+	// would just repeat the hits.
+	readers := make([]reader.Reader, 0, len(p.buffers))
+	for _, b := range p.buffers {
+		if p.globalSearchReader != nil && b.reader == p.globalSearchReader {
+			readers = append(readers, nil)
+			continue
+		}
+		readers = append(readers, p.filteredSnapshot(b.reader))
+	}
+
+	search := &globalSearch{searchString: p.searchString}
+	p.mode = PagerModeGlobalSearching{pager: p, search: search}
+
+	pattern := *p.searchPattern
+	events := p.screen.Events()
+	go func() {
+		defer func() {
+			PanicHandler("listGlobalSearchHits()", recover(), debug.Stack())
+		}()
+
+		t0 := time.Now()
+		hits := findHitsInAllReaders(readers, pattern, &search.cancelled)
+		log.Debugf("Found %d global search hits in %s", len(hits), time.Since(t0))
+
+		events <- eventGlobalSearchHits{search: search, hits: hits}
+	}()
+}
+
+// Show the results of a background global search, unless the user has moved on
+func (p *Pager) onGlobalSearchHits(event eventGlobalSearchHits) {
+	searching, isSearching := p.mode.(PagerModeGlobalSearching)
+	if !isSearching || searching.search != event.search {
+		// Cancelled
+		return
+	}
+
+	hits := event.hits
+	if len(hits) == 0 {
+		p.mode = PagerModeNotFound{pager: p}
+		return
+	}
+	p.mode = PagerModeViewing{pager: p}
+
+	var results strings.Builder
+	for i, hit := range hits {
+		name := bufferName(p.buffers[hit.bufferIndex].reader)
+		prefix := name + ":" + hit.line.Number.Format()
+		hits[i].reference = textstyles.FileReference{
+			Path:      name,
+			Line:      hit.line.Number.AsOneBased(),
+			RuneRange: [2]int{0, utf8.RuneCountInString(prefix)},
+		}
+
+		results.WriteString(prefix + ": " + hit.line.Plain() + "\n")
+	}
+
+	title := util.FormatInt(len(hits)) + " hits for \"" + event.search.searchString + "\""
+	if len(hits) == 1 {
+		title = "1 hit for \"" + event.search.searchString + "\""
+	}
+	if len(hits) >= maxGlobalSearchHits {
+		title = "First " + title
+	}
+	if len(p.buffers) > 1 {
+		title += " in " + strconv.Itoa(len(p.buffers)) + " buffers"
+	}
+
+	resultsReader, err := reader.NewFromStream(title, strings.NewReader(results.String()), p.formatter(), p.readerOptions(nil))
+	if err != nil {
+		p.showError("Showing the search results failed: " + err.Error())
+		return
+	}
+
+	// Like the log, there is only one search results buffer. A new search
+	// replaces the results of the previous one.
+	resultsBufferIndex := -1
+	for index, b := range p.buffers {
+		if p.globalSearchReader != nil && b.reader == p.globalSearchReader {
+			resultsBufferIndex = index
+			break
+		}
+	}
+	p.globalSearchReader = resultsReader
+	p.globalSearchHits = hits
+
+	p.rememberJump()
+	if resultsBufferIndex < 0 {
+		p.openBuffer(resultsReader)
+	} else {
+		p.replaceBufferReader(resultsBufferIndex, resultsReader)
+		p.switchToBuffer(resultsBufferIndex)
+	}
+
+	// New results, start from the first hit
+	p.scrollPosition = newScrollPosition("onGlobalSearchHits")
+	p.setTargetLine(nil)
+	p.focusedHyperlink = nil
+}
+
+func (p *Pager) isShowingGlobalSearchHits() bool {
+	return !p.isShowingHelp && p.globalSearchReader != nil && p.reader == p.globalSearchReader
+}
+
+// The hit a line in the search results is about, or nil if there is none
+func (p *Pager) globalSearchHitOnLine(line *reader.NumberedLine) *globalSearchHit {
+	if !p.isShowingGlobalSearchHits() || line == nil {
+		return nil
+	}
+
+	index := line.Number.AsZeroBased()
+	if index >= len(p.globalSearchHits) {
+		return nil
+	}
+	return &p.globalSearchHits[index]
+}
+
+// In the search results, open the hit the selected link is on. Without a
+// selected link, open the hit on the top line.
+func (p *Pager) openGlobalSearchHitOnScreen() {
+	lineIndex := p.lineIndex()
+	if link := p.visibleFocusedHyperlink(); link != nil {
+		lineIndex = &link.lineIndex
+	}
+	if lineIndex == nil {
+		return
+	}
+
+	hit := p.globalSearchHitOnLine(p.Reader().GetLine(*lineIndex))
+	if hit == nil {
+		return
+	}
+	p.openGlobalSearchHit(*hit)
+}
+
+// Switch to the hit's buffer and scroll to the hit
+func (p *Pager) openGlobalSearchHit(hit globalSearchHit) {
+	p.rememberJump()
+	p.switchToBuffer(hit.bufferIndex)
+
+	// The hit has the line number from the unfiltered input, find where that
+	// line is in what we're showing
+	lineIndex := linemetadata.IndexFromZeroBased(hit.line.Number.AsZeroBased())
+	filteredIndices := p.filteringReader.filteredIndices()
+	if filteredIndices != nil {
+		index, found := filteredIndices[lineIndex.Index()]
+		if !found {
+			p.showStatusMessage(statusMessageInfo, "Line "+hit.line.Number.Format()+" is hidden by the filter")
+			return
+		}
+		lineIndex = index
+	}
+
+	p.scrollPosition = NewScrollPositionFromIndex(lineIndex, "openGlobalSearchHit")
+	p.setTargetLine(nil)
+	p.selectSearchHitOnLine(lineIndex, false)
+}
+
+// Search this many lines at a time, checking for cancellation in between
+const globalSearchChunkSize = 10_000
+
+// Find search hits in all readers, in reader order. Stops after
+// maxGlobalSearchHits hits, or when cancelled. nil readers are skipped.
+func findHitsInAllReaders(readers []reader.Reader, pattern regexp.Regexp, cancelled *atomic.Bool) []globalSearchHit {
+	hits := make([]globalSearchHit, 0)
+	for bufferIndex, r := range readers {
+		if r == nil {
+			continue
+		}
+
+		for _, line := range _findAllHits(r, pattern, maxGlobalSearchHits-len(hits), cancelled) {
+			hits = append(hits, globalSearchHit{bufferIndex: bufferIndex, line: line})
+		}
+
+		if len(hits) >= maxGlobalSearchHits || cancelled.Load() {
+			break
+		}
+	}
+
+	return hits
+}
+
+// Return at most maxCount lines matching the pattern, in input order. Gives up
+// early if cancelled.
+func _findAllHits(inputReader reader.Reader, pattern regexp.Regexp, maxCount int, cancelled *atomic.Bool) []*reader.NumberedLine {
+	hits := make([]*reader.NumberedLine, 0)
+	lineCount := inputReader.GetLineCount()
+	searchStart := linemetadata.Index{}
+	for len(hits) < maxCount && !cancelled.Load() && searchStart.IsWithinLength(lineCount) {
+		chunkEnd := searchStart.NonWrappingAdd(globalSearchChunkSize)
+		hit := _findFirstHit(inputReader, searchStart, pattern, &chunkEnd, false)
+		if hit == nil {
+			searchStart = chunkEnd
+			continue
+		}
+
+		hitIndex := *hit.internalDontTouch.lineIndex
+		hits = append(hits, inputReader.GetLine(hitIndex))
+		searchStart = hitIndex.NonWrappingAdd(1)
+	}
+
+	return hits
+}
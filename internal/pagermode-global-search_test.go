@@ -0,0 +1,175 @@
+package internal
+
+import (
+	"testing"
+	"time"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func createTwoBuffersPager(t *testing.T) *Pager {
+	second := reader.NewFromTextForTesting("second.txt", "x\nhit\ny\nz\nhit again\n")
+	assert.NilError(t, second.Wait())
+
+	pager, screen := newTestPager(t, reader.NewFromTextForTesting("first.txt", "a\nb\nc\nd\ne\nf\n"), 20, 3)
+	pager.AddBuffer(second)
+	pager.screen = eventsFakeScreen{FakeScreen: screen, events: make(chan twin.Event, 10)}
+	pager.marks = make(map[rune]scrollPosition)
+
+	return pager
+}
+
+// Start a global search and wait for it to finish
+func searchAllBuffers(t *testing.T, pager *Pager, searchString string) {
+	pager.searchString = searchString
+	pager.searchPattern = toPattern(searchString)
+	pager.listGlobalSearchHits()
+
+	_, isSearching := pager.mode.(PagerModeGlobalSearching)
+	assert.Assert(t, isSearching)
+
+	// Earlier search results buffers send spinner updates, skip those
+	timeout := time.After(10 * time.Second)
+	for {
+		select {
+		case event := <-pager.screen.Events():
+			if hits, isHits := event.(eventGlobalSearchHits); isHits {
+				pager.onGlobalSearchHits(hits)
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for global search hits")
+		}
+	}
+}
+
+func TestSwitchBuffer(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	pager.scrollPosition = pager.scrollPosition.NextLine(2)
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+	assert.Equal(t, pager.bufferStatusPrefix(), "(1/2) ")
+
+	pager.switchBuffer(1)
+	assert.Equal(t, *pager.reader.Name, "second.txt")
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	assert.Equal(t, pager.bufferStatusPrefix(), "(2/2) ")
+
+	// Wrap around back to the first buffer, which should have kept its
+	// position
+	pager.switchBuffer(1)
+	assert.Equal(t, *pager.reader.Name, "first.txt")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+}
+
+// The lines of the search results buffer
+func globalSearchResults(t *testing.T, pager *Pager) []string {
+	assert.Assert(t, pager.isShowingGlobalSearchHits())
+	assert.NilError(t, pager.globalSearchReader.Wait())
+
+	results := []string{}
+	for _, line := range pager.Reader().GetLines(linemetadata.Index{}, 100).Lines {
+		results = append(results, line.Plain())
+	}
+	return results
+}
+
+func TestGlobalSearch(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	searchAllBuffers(t, pager, "hit")
+
+	assert.DeepEqual(t, globalSearchResults(t, pager), []string{
+		"second.txt:2: hit",
+		"second.txt:5: hit again",
+	})
+	assert.Equal(t, *pager.reader.Name, "2 hits for \"hit\" in 2 buffers")
+	assert.Equal(t, pager.currentBufferIndex, 2)
+
+	// Select the second hit and open it
+	pager.focusNextHyperlink(false)
+	pager.focusNextHyperlink(false)
+	pager.mode.onKey(twin.KeyEnter)
+
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, *pager.reader.Name, "second.txt")
+	assert.Equal(t, pager.currentSearchHit.lineIndex.Index(), 4)
+	assert.Assert(t, pager.isLineVisible(pager.currentSearchHit.lineIndex))
+
+	// Back to the results, ENTER without a selection opens the top hit
+	pager.switchToBuffer(2)
+	pager.focusedHyperlink = nil
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, *pager.reader.Name, "second.txt")
+	assert.Equal(t, pager.currentSearchHit.lineIndex.Index(), 1)
+}
+
+// A new search replaces the results of the previous one, without searching
+// them
+func TestGlobalSearchAgain(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	searchAllBuffers(t, pager, "hit")
+	searchAllBuffers(t, pager, "again")
+
+	assert.DeepEqual(t, globalSearchResults(t, pager), []string{
+		"second.txt:5: hit again",
+	})
+	assert.Equal(t, len(pager.buffers), 3)
+}
+
+// Outside of the search results, ENTER does what it's bound to
+func TestGlobalSearchEnterElsewhere(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, *pager.reader.Name, "first.txt")
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+}
+
+func TestGlobalSearchNotFound(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	searchAllBuffers(t, pager, "nope")
+
+	assert.Equal(t, "NotFound", modeName(pager))
+}
+
+// While filtering, only hits matching the filter should be listed, and
+// picking one should keep the filter
+func TestGlobalSearchWhileFiltering(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+	filter := toPattern("again")
+	pager.filterPattern = filter
+
+	searchAllBuffers(t, pager, "hit")
+
+	assert.DeepEqual(t, globalSearchResults(t, pager), []string{
+		"second.txt:5: hit again",
+	})
+
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, *pager.reader.Name, "second.txt")
+	assert.Equal(t, pager.filterPattern, filter)
+
+	// Only one line passes the filter, and that's the hit
+	assert.Equal(t, pager.currentSearchHit.lineIndex.Index(), 0)
+	assert.Equal(t, pager.Reader().GetLine(pager.currentSearchHit.lineIndex).Plain(), "hit again")
+}
+
+func TestGlobalSearchCancel(t *testing.T) {
+	pager := createTwoBuffersPager(t)
+
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+	pager.listGlobalSearchHits()
+	pager.mode.onKey(twin.KeyEscape)
+	assert.Equal(t, "Viewing", modeName(pager))
+
+	// Results arriving after cancelling should be ignored
+	pager.onGlobalSearchHits((<-pager.screen.Events()).(eventGlobalSearchHits))
+	assert.Equal(t, "Viewing", modeName(pager))
+}
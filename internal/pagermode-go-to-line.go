@@ -12,7 +12,30 @@ import (
 type PagerModeGotoLine struct {
 	pager *Pager
 
-	gotoLineString string
+	inputBox *lineEditor
+}
+
+func newPagerModeGotoLine(p *Pager) *PagerModeGotoLine {
+	m := &PagerModeGotoLine{pager: p}
+	m.inputBox = newLineEditor(p.gotoHistory, nil)
+	m.inputBox.accept = func(text string) bool {
+		if len(text) == 0 {
+			return true
+		}
+
+		newGotoLineNumber, err := strconv.Atoi(text)
+		if err != nil {
+			log.Debugf("Got non-number goto line: %s", err)
+			return false
+		}
+		if newGotoLineNumber < 1 {
+			log.Debugf("Got non-positive goto line number: %d", newGotoLineNumber)
+			return false
+		}
+
+		return true
+	}
+	return m
 }
 
 func (m *PagerModeGotoLine) drawFooter(_ string, _ string) {
@@ -20,17 +43,21 @@ func (m *PagerModeGotoLine) drawFooter(_ string, _ string) {
 
 	_, height := p.screen.Size()
 
-	formattedGotoLineString := m.gotoLineString
-	if len(formattedGotoLineString) > 0 {
-		goToLineInt, err := strconv.Atoi(formattedGotoLineString)
-		if err != nil {
-			panic("goto line string should always be a number: " + formattedGotoLineString)
-		}
-		formattedGotoLineString = util.FormatInt(goToLineInt)
+	const prompt = "Go to line number: "
+
+	if !m.inputBox.cursorIsAtEnd() || len(m.inputBox.text) == 0 {
+		m.inputBox.draw(p.screen, height-1, prompt)
+		return
+	}
+
+	// Cursor at the end, show the number formatted for readability
+	goToLineInt, err := strconv.Atoi(m.inputBox.String())
+	if err != nil {
+		panic("goto line string should always be a number: " + m.inputBox.String())
 	}
 
 	pos := 0
-	for _, token := range "Go to line number: " + formattedGotoLineString {
+	for _, token := range prompt + util.FormatInt(goToLineInt) {
 		pos += p.screen.SetCell(pos, height-1, twin.NewStyledRune(token, twin.StyleDefault))
 	}
 
@@ -43,28 +70,21 @@ func (m *PagerModeGotoLine) onKey(key twin.KeyCode) {
 
 	switch key {
 	case twin.KeyEnter:
-		newLineNumber, err := strconv.Atoi(m.gotoLineString)
+		m.inputBox.commitToHistory()
+		newLineNumber, err := strconv.Atoi(m.inputBox.String())
 		if err == nil {
-			targetIndex := linemetadata.IndexFromOneBased(newLineNumber)
-			p.scrollPosition = NewScrollPositionFromIndex(
-				targetIndex,
-				"onGotoLineKey",
-			)
-			p.setTargetLine(&targetIndex)
+			p.goToLine(linemetadata.IndexFromOneBased(newLineNumber))
 		}
 		p.mode = PagerModeViewing{pager: p}
 
 	case twin.KeyEscape:
 		p.mode = PagerModeViewing{pager: p}
 
-	case twin.KeyBackspace, twin.KeyDelete:
-		if len(m.gotoLineString) == 0 {
+	default:
+		if m.inputBox.onKey(key) {
 			return
 		}
 
-		m.gotoLineString = removeLastChar(m.gotoLineString)
-
-	default:
 		log.Tracef("Unhandled goto key event %v, treating as a viewing key event", key)
 		p.mode = PagerModeViewing{pager: p}
 		p.mode.onKey(key)
@@ -80,22 +100,14 @@ func (m *PagerModeGotoLine) onRune(char rune) {
 	}
 
 	if char == 'g' {
+		p.rememberJump()
 		p.scrollPosition = newScrollPosition("Pager scroll position")
 		p.handleScrolledUp()
 		p.mode = PagerModeViewing{pager: p}
 		return
 	}
 
-	newGotoLineString := m.gotoLineString + string(char)
-	newGotoLineNumber, err := strconv.Atoi(newGotoLineString)
-	if err != nil {
-		log.Debugf("Got non-number goto rune '%s'/0x%08x: %s", string(char), int32(char), err)
-		return
+	if !m.inputBox.onRune(char) {
+		log.Debugf("Unhandled goto rune '%s'/0x%08x", string(char), int32(char))
 	}
-	if newGotoLineNumber < 1 {
-		log.Debugf("Got non-positive goto line number: %d", newGotoLineNumber)
-		return
-	}
-
-	m.gotoLineString = newGotoLineString
 }
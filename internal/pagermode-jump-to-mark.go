@@ -72,8 +72,16 @@ func (m PagerModeJumpToMark) onRune(char rune) {
 		return
 	}
 
+	if char == '\'' {
+		// Like in less, '' goes back to where we were before the latest jump
+		m.pager.mode = PagerModeViewing(m)
+		m.pager.jumpToPreviousPosition()
+		return
+	}
+
 	destination, ok := m.pager.marks[char]
 	if ok {
+		m.pager.rememberJump()
 		m.pager.scrollPosition = destination
 	}
 
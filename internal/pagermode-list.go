@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"unicode"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/twin"
+)
+
+// A full screen list of items for the user to pick one from.
+//
+// This mode draws over the whole screen, not just the footer.
+type PagerModeList struct {
+	pager *Pager
+
+	// Shown in the footer
+	title string
+
+	items []string
+
+	selected     int
+	firstVisible int
+
+	// Called with the index of the picked item after the pager has been put
+	// back into viewing mode
+	onSelect func(index int)
+
+	// If set, the user can delete items. Called with the index of the item
+	// before it is removed from the list.
+	onDelete func(index int)
+}
+
+func newPagerModeList(p *Pager, title string, items []string, onSelect func(index int)) *PagerModeList {
+	return &PagerModeList{
+		pager:    p,
+		title:    title,
+		items:    items,
+		onSelect: onSelect,
+	}
+}
+
+// How many list items fit on screen
+func (m *PagerModeList) visibleHeight() int {
+	_, height := m.pager.screen.Size()
+	return height - 1
+}
+
+// Adjust firstVisible so that the selected item is on screen
+func (m *PagerModeList) scrollToSelected() {
+	visibleHeight := m.visibleHeight()
+	if m.selected < m.firstVisible {
+		m.firstVisible = m.selected
+	}
+	if m.selected >= m.firstVisible+visibleHeight {
+		m.firstVisible = m.selected - visibleHeight + 1
+	}
+	if m.firstVisible < 0 {
+		m.firstVisible = 0
+	}
+}
+
+func (m *PagerModeList) drawFooter(_ string, _ string) {
+	screen := m.pager.screen
+	width, _ := screen.Size()
+
+	m.scrollToSelected()
+
+	for row := 0; row < m.visibleHeight(); row++ {
+		style := twin.StyleDefault
+		text := ""
+
+		index := m.firstVisible + row
+		if index < len(m.items) {
+			text = m.items[index]
+			if index == m.selected {
+				style = style.WithAttr(twin.AttrReverse)
+			}
+		}
+
+		pos := 0
+		for _, char := range text {
+			if pos >= width {
+				break
+			}
+			if !unicode.IsPrint(char) {
+				char = ' '
+			}
+			pos += screen.SetCell(pos, row, twin.NewStyledRune(char, style))
+		}
+
+		// Clear the rest of the row, showing the selection across the whole
+		// screen width
+		for pos < width {
+			pos += screen.SetCell(pos, row, twin.NewStyledRune(' ', style))
+		}
+	}
+
+	if m.onDelete != nil {
+		m.pager.setFooter(m.title + "  Press RETURN to pick one, 'd' to delete, 'ESC' / 'q' to go back")
+		return
+	}
+	m.pager.setFooter(m.title + "  Press RETURN to pick one, 'ESC' / 'q' to go back")
+}
+
+func (m *PagerModeList) moveSelection(delta int) {
+	m.selected += delta
+	if m.selected >= len(m.items) {
+		m.selected = len(m.items) - 1
+	}
+	if m.selected < 0 {
+		m.selected = 0
+	}
+}
+
+// Remove the selected item, going back to viewing if the list becomes empty
+func (m *PagerModeList) deleteSelected() {
+	if m.onDelete == nil {
+		return
+	}
+
+	m.onDelete(m.selected)
+	m.items = append(m.items[:m.selected], m.items[m.selected+1:]...)
+	if len(m.items) == 0 {
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+		return
+	}
+	m.moveSelection(0)
+}
+
+func (m *PagerModeList) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyEnter:
+		p.mode = PagerModeViewing{pager: p}
+		if m.selected < len(m.items) {
+			m.onSelect(m.selected)
+		}
+
+	case twin.KeyEscape:
+		p.mode = PagerModeViewing{pager: p}
+
+	case twin.KeyUp:
+		m.moveSelection(-1)
+
+	case twin.KeyDown:
+		m.moveSelection(1)
+
+	case twin.KeyPgUp:
+		m.moveSelection(-m.visibleHeight())
+
+	case twin.KeyPgDown:
+		m.moveSelection(m.visibleHeight())
+
+	case twin.KeyHome:
+		m.selected = 0
+
+	case twin.KeyEnd:
+		m.moveSelection(len(m.items))
+
+	case twin.KeyDelete:
+		m.deleteSelected()
+
+	default:
+		log.Debugf("Unhandled list key event %v", key)
+	}
+}
+
+func (m *PagerModeList) onRune(char rune) {
+	switch char {
+	case 'q':
+		m.pager.mode = PagerModeViewing{pager: m.pager}
+
+	case 'k', '\x10': // CTRL-p
+		m.moveSelection(-1)
+
+	case 'j', '\x0e': // CTRL-n
+		m.moveSelection(1)
+
+	case 'b':
+		m.moveSelection(-m.visibleHeight())
+
+	case 'f', ' ':
+		m.moveSelection(m.visibleHeight())
+
+	case '<', 'g':
+		m.selected = 0
+
+	case '>', 'G':
+		m.moveSelection(len(m.items))
+
+	case 'd':
+		m.deleteSelected()
+
+	default:
+		log.Debugf("Unhandled list rune '%s'/0x%08x", string(char), int32(char))
+	}
+}
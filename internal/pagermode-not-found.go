@@ -16,18 +16,14 @@ func (m PagerModeNotFound) onKey(key twin.KeyCode) {
 }
 
 func (m PagerModeNotFound) onRune(char rune) {
-	switch char {
-
-	// Should match the pagermode-viewing.go next-search-hit bindings
-	case 'n':
-		m.pager.scrollToNextSearchHit()
-
-	// Should match the pagermode-viewing.go previous-search-hit bindings
-	case 'p', 'N':
-		m.pager.scrollToPreviousSearchHit()
-
-	default:
-		m.pager.mode = PagerModeViewing(m)
-		m.pager.mode.onRune(char)
+	// Searching again should keep telling us it's not found, so don't switch
+	// back to viewing mode for those
+	action := m.pager.Keymap.lookup(key{char: char})
+	if action != nil && (action.name == "search-next" || action.name == "search-previous") {
+		action.do(m.pager)
+		return
 	}
+
+	m.pager.mode = PagerModeViewing(m)
+	m.pager.mode.onRune(char)
 }
@@ -0,0 +1,63 @@
+package internal
+
+import (
+	"fmt"
+
+	"github.com/walles/moor/internal/util"
+	"github.com/walles/moor/twin"
+)
+
+// Selecting lines to copy to the clipboard. The selection itself lives in
+// Pager.selection.
+type PagerModeSelect struct {
+	pager *Pager
+}
+
+func (m PagerModeSelect) drawFooter(_ string, _ string) {
+	p := m.pager
+	if p.selection == nil {
+		return
+	}
+
+	lineCount := p.selection.lineCount()
+	linesText := "1 line"
+	if lineCount != 1 {
+		linesText = util.FormatInt(lineCount) + " lines"
+	}
+
+	p.setFooter(fmt.Sprintf("%s selected, press RETURN or 'y' to copy, ESC to cancel", linesText))
+}
+
+func (m PagerModeSelect) onKey(key twin.KeyCode) {
+	p := m.pager
+
+	switch key {
+	case twin.KeyUp:
+		p.moveSelectionCursor(-1)
+	case twin.KeyDown:
+		p.moveSelectionCursor(1)
+	case twin.KeyPgUp:
+		p.moveSelectionCursor(-p.visibleHeight())
+	case twin.KeyPgDown:
+		p.moveSelectionCursor(p.visibleHeight())
+	case twin.KeyEnter:
+		p.copySelection()
+	case twin.KeyEscape:
+		p.cancelSelection()
+	}
+}
+
+func (m PagerModeSelect) onRune(char rune) {
+	p := m.pager
+
+	switch char {
+	case 'k':
+		p.moveSelectionCursor(-1)
+	case 'j':
+		p.moveSelectionCursor(1)
+	case 'y':
+		p.copySelection()
+	case 'q':
+		p.cancelSelection()
+	}
+}
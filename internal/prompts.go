@@ -0,0 +1,145 @@
+package internal
+
+import (
+	"strings"
+
+	"github.com/walles/moor/internal/linemetadata"
+)
+
+// Navigation between shell prompts and command outputs in terminal scrollback,
+// using OSC 133 prompt markers. See textstyles.PromptMarkers() for what the
+// marker letters mean.
+
+// Indices into p.Reader() of the lines with the given prompt marker. While
+// filtering, only lines matching the filter are included.
+func (p *Pager) linesWithPromptMarker(marker string) []linemetadata.Index {
+	if p.isShowingHelp {
+		return nil
+	}
+
+	filteredIndices := p.filteringReader.filteredIndices()
+
+	indices := []linemetadata.Index{}
+	for _, line := range p.reader.PromptMarkers() {
+		if !strings.Contains(line.Markers, marker) {
+			continue
+		}
+
+		index := line.Index
+		if filteredIndices != nil {
+			filteredIndex, found := filteredIndices[index.Index()]
+			if !found {
+				continue
+			}
+			index = filteredIndex
+		}
+
+		indices = append(indices, index)
+	}
+	return indices
+}
+
+// Where the prompts are. If there are no prompt start markers, we go by where
+// the commands start instead.
+func (p *Pager) promptLines() []linemetadata.Index {
+	prompts := p.linesWithPromptMarker("A")
+	if len(prompts) > 0 {
+		return prompts
+	}
+	return p.linesWithPromptMarker("B")
+}
+
+// Where command outputs start
+func (p *Pager) commandOutputLines() []linemetadata.Index {
+	return p.linesWithPromptMarker("C")
+}
+
+// Scroll so that the count-th of the lines below the top of the screen is at
+// the top, or the last one if there are fewer. Returns false if there was no
+// such line.
+func (p *Pager) scrollToNextOf(lines []linemetadata.Index, count int, why string) bool {
+	current := p.lineIndex()
+	var target *linemetadata.Index
+	for i := range lines {
+		if current != nil && !lines[i].IsAfter(*current) {
+			continue
+		}
+
+		target = &lines[i]
+		count--
+		if count <= 0 {
+			break
+		}
+	}
+
+	return p.scrollToIndex(target, why)
+}
+
+// Scroll so that the count-th of the lines above the top of the screen is at
+// the top, or the first one if there are fewer. Returns false if there was no
+// such line.
+func (p *Pager) scrollToPreviousOf(lines []linemetadata.Index, count int, why string) bool {
+	current := p.lineIndex()
+	var target *linemetadata.Index
+	for i := len(lines) - 1; i >= 0 && current != nil; i-- {
+		if !lines[i].IsBefore(*current) {
+			continue
+		}
+
+		target = &lines[i]
+		count--
+		if count <= 0 {
+			break
+		}
+	}
+
+	return p.scrollToIndex(target, why)
+}
+
+// One jump, however far we went. Returns false if there is no target.
+func (p *Pager) scrollToIndex(target *linemetadata.Index, why string) bool {
+	if target == nil {
+		return false
+	}
+
+	p.rememberJump()
+	p.scrollPosition = NewScrollPositionFromIndex(*target, why)
+	p.setTargetLine(nil)
+	return true
+}
+
+func (p *Pager) scrollToNextPrompt(count int) {
+	if !p.scrollToNextOf(p.promptLines(), count, "scrollToNextPrompt") {
+		p.showStatusMessage(statusMessageInfo, "No more prompts below")
+	}
+}
+
+func (p *Pager) scrollToPreviousPrompt(count int) {
+	if !p.scrollToPreviousOf(p.promptLines(), count, "scrollToPreviousPrompt") {
+		p.showStatusMessage(statusMessageInfo, "No more prompts above")
+	}
+}
+
+func (p *Pager) scrollToNextCommandOutput(count int) {
+	if !p.scrollToNextOf(p.commandOutputLines(), count, "scrollToNextCommandOutput") {
+		p.showStatusMessage(statusMessageInfo, "No more command outputs below")
+	}
+}
+
+func (p *Pager) scrollToPreviousCommandOutput(count int) {
+	if !p.scrollToPreviousOf(p.commandOutputLines(), count, "scrollToPreviousCommandOutput") {
+		p.showStatusMessage(statusMessageInfo, "No more command outputs above")
+	}
+}
+
+// Show only the lines where commands were typed, or show everything again
+func (p *Pager) toggleOnlyCommands() {
+	if !p.onlyCommands && len(p.linesWithPromptMarker("B")) == 0 {
+		p.showStatusMessage(statusMessageInfo, "No commands found, they are recognized by OSC 133 prompt markers")
+		return
+	}
+
+	p.changeFilterKeepingPosition(func() {
+		p.onlyCommands = !p.onlyCommands
+	})
+}
@@ -0,0 +1,98 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// Terminal scrollback with three commands, as recorded by a shell emitting OSC
+// 133 prompt markers
+func newPromptsTestPager(t *testing.T) *Pager {
+	reader := reader.NewFromTextForTesting("scrollback", strings.Join([]string{
+		"Welcome!",
+		"\x1b]133;A\x07$ \x1b]133;B\x07ls",
+		"\x1b]133;C\x07README.md",
+		"go.mod",
+		"\x1b]133;D;0\x07\x1b]133;A\x07$ \x1b]133;B\x07echo hello",
+		"\x1b]133;C\x07hello",
+		"\x1b]133;D;0\x07\x1b]133;A\x07$ \x1b]133;B\x07false",
+		"\x1b]133;C\x07\x1b]133;D;1\x07\x1b]133;A\x07$ ",
+	}, "\n"))
+	pager, _ := newTestPager(t, reader, 80, 2)
+	return pager
+}
+
+func TestPromptNavigation(t *testing.T) {
+	pager := newPromptsTestPager(t)
+
+	typeRunes(pager, "x")
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+	typeRunes(pager, "x")
+	assert.Equal(t, pager.lineIndex().Index(), 4)
+	typeRunes(pager, "2x")
+	assert.Equal(t, pager.lineIndex().Index(), 7)
+	typeRunes(pager, "x")
+	assert.Equal(t, statusText(pager), "No more prompts below")
+
+	typeRunes(pager, "X")
+	assert.Equal(t, pager.lineIndex().Index(), 6)
+}
+
+func TestCommandOutputNavigation(t *testing.T) {
+	pager := newPromptsTestPager(t)
+
+	typeRunes(pager, "r")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+	typeRunes(pager, "r")
+	assert.Equal(t, pager.lineIndex().Index(), 5)
+	typeRunes(pager, "R")
+	assert.Equal(t, pager.lineIndex().Index(), 2)
+	typeRunes(pager, "R")
+	assert.Equal(t, statusText(pager), "No more command outputs above")
+}
+
+func TestPromptNavigationWithoutPromptStarts(t *testing.T) {
+	// Only command start markers, we should go by those
+	reader := reader.NewFromTextForTesting("scrollback", strings.Join([]string{
+		"Welcome!",
+		"$ \x1b]133;B\x07ls",
+		"README.md",
+		"$ \x1b]133;B\x07pwd",
+	}, "\n"))
+	pager, _ := newTestPager(t, reader, 80, 2)
+
+	typeRunes(pager, "x")
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+	typeRunes(pager, "x")
+	assert.Equal(t, pager.lineIndex().Index(), 3)
+}
+
+func TestCommandsFilter(t *testing.T) {
+	pager := newPromptsTestPager(t)
+	typeRunes(pager, "xx")
+
+	typeRunes(pager, "!")
+	assert.Equal(t, pager.Reader().GetLineCount(), 3)
+
+	// Scrolled to the "echo hello" line
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+
+	pager.redraw("")
+	assert.Equal(t, rowToString(pager.screen.(*twin.FakeScreen).GetRow(0)), "$ echo hello")
+
+	typeRunes(pager, "!")
+	assert.Equal(t, pager.Reader().GetLineCount(), 8)
+	assert.Equal(t, pager.lineIndex().Index(), 4)
+}
+
+func TestCommandsFilterWithoutCommands(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "!")
+	assert.Equal(t, pager.Reader().GetLineCount(), 100)
+	assert.Equal(t, statusText(pager), "No commands found, they are recognized by OSC 133 prompt markers")
+}
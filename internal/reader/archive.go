@@ -0,0 +1,331 @@
+package reader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"runtime/debug"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	log "github.com/sirupsen/logrus"
+)
+
+var zipMagic = []byte{0x50, 0x4b, 0x03, 0x04}
+
+// Tar archives have this at offset 257 of their first header block
+var tarMagic = []byte("ustar")
+
+const tarMagicOffset = 257
+
+// Delivers the contents of one archive member once readArchiveMembers() gets
+// to it. Until then, reading blocks.
+type archiveMemberStream struct {
+	contents chan io.Reader
+	stream   io.Reader
+}
+
+func newArchiveMemberStream() *archiveMemberStream {
+	return &archiveMemberStream{contents: make(chan io.Reader, 1)}
+}
+
+func (s *archiveMemberStream) Read(p []byte) (int, error) {
+	if s.stream == nil {
+		// Members can be compressed too
+		stream, err := ZReader(<-s.contents)
+		if err != nil {
+			s.stream = failingReader{err: err}
+		} else {
+			s.stream = stream
+		}
+	}
+
+	return s.stream.Read(p)
+}
+
+type failingReader struct {
+	err error
+}
+
+func (r failingReader) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+// Like NewFromFilename(), but if the file is a tar or zip archive (possibly
+// compressed), you get one reader per file in the archive.
+//
+// Archive members are named like "archive.tar:path/to/member". Only their
+// names are read up front, their contents are read in the background.
+func NewFromArchiveOrFilename(filename string, formatter chroma.Formatter, options ReaderOptions) ([]*ReaderImpl, error) {
+	names, err := listArchive(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(names) == 0 {
+		// Not an archive, or nothing in it worth showing
+		reader, err := NewFromFilename(filename, formatter, options)
+		if err != nil {
+			return nil, err
+		}
+		return []*ReaderImpl{reader}, nil
+	}
+
+	readers := make([]*ReaderImpl, 0, len(names))
+	streams := make([]*archiveMemberStream, 0, len(names))
+	for _, name := range names {
+		memberOptions := options
+		if memberOptions.Lexer == nil {
+			memberOptions.Lexer = lexers.Match(name)
+		}
+
+		stream := newArchiveMemberStream()
+		streams = append(streams, stream)
+		readers = append(readers, newArchiveMemberReader(filename+":"+name, stream, formatter, memberOptions))
+	}
+
+	go func() {
+		defer func() {
+			PanicHandler("NewFromArchiveOrFilename()/readArchiveMembers()", recover(), debug.Stack())
+		}()
+
+		readArchiveMembers(filename, streams)
+	}()
+
+	return readers, nil
+}
+
+// Like NewFromStream(), but without peeking at the stream, since that would
+// wait for the member to be read
+func newArchiveMemberReader(name string, stream *archiveMemberStream, formatter chroma.Formatter, options ReaderOptions) *ReaderImpl {
+	reader := newReaderFromStream(stream, nil, formatter, options)
+
+	reader.Lock()
+	reader.Name = &name
+	reader.Unlock()
+
+	if options.Lexer == nil {
+		reader.HighlightingDone.Store(true)
+	}
+
+	if options.Style != nil {
+		reader.SetStyleForHighlighting(*options.Style)
+	}
+
+	return reader
+}
+
+// Returns the names of the regular files in a tar or zip archive. Returns nil
+// if the file isn't an archive.
+func listArchive(filename string) ([]string, error) {
+	fileError := TryOpen(filename)
+	if fileError != nil {
+		return nil, fileError
+	}
+
+	isZip, err := hasZipMagic(filename)
+	if err != nil {
+		return nil, err
+	}
+	if isZip {
+		log.Debugf("File is a zip archive: %v", filename)
+		return listZipArchive(filename)
+	}
+
+	tarReader, closer, err := openTarArchive(filename)
+	if err != nil || tarReader == nil {
+		return nil, err
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	log.Debugf("File is a tar archive: %v", filename)
+	names := make([]string, 0)
+	for {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return names, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		if header.Typeflag == tar.TypeReg {
+			names = append(names, header.Name)
+		}
+	}
+}
+
+// Returns nil if the file isn't a tar archive
+func openTarArchive(filename string) (*tar.Reader, io.Closer, error) {
+	stream, _, err := ZOpen(filename)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	buffered := bufio.NewReader(stream)
+	header, err := buffered.Peek(tarMagicOffset + len(tarMagic))
+	if err != nil && err != io.EOF {
+		_ = stream.Close()
+		return nil, nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(header) < tarMagicOffset+len(tarMagic) || !bytes.Equal(header[tarMagicOffset:], tarMagic) {
+		_ = stream.Close()
+		return nil, nil, nil
+	}
+
+	return tar.NewReader(buffered), stream, nil
+}
+
+func hasZipMagic(filename string) (bool, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	firstBytes := make([]byte, len(zipMagic))
+	_, err = io.ReadFull(file, firstBytes)
+	if err == io.EOF || err == io.ErrUnexpectedEOF {
+		// Too short to be a zip archive
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read file: %w", err)
+	}
+
+	return bytes.Equal(firstBytes, zipMagic), nil
+}
+
+func listZipArchive(filename string) ([]string, error) {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+
+	names := make([]string, 0)
+	for _, file := range zipReader.File {
+		if file.Mode().IsRegular() {
+			names = append(names, file.Name)
+		}
+	}
+
+	return names, nil
+}
+
+// Hand each stream the contents of its archive member, in archive order. The
+// streams are for the members listArchive() found.
+func readArchiveMembers(filename string, streams []*archiveMemberStream) {
+	delivered := 0
+	deliver := func(contents []byte) {
+		streams[delivered].contents <- bytes.NewReader(contents)
+		delivered++
+	}
+
+	isZip, err := hasZipMagic(filename)
+	if err == nil {
+		if isZip {
+			err = readZipMembers(filename, len(streams), deliver)
+		} else {
+			err = readTarMembers(filename, len(streams), deliver)
+		}
+	}
+
+	if err == nil && delivered < len(streams) {
+		err = fmt.Errorf("%s changed while reading it", filename)
+	}
+	if err != nil {
+		log.Warn("Reading archive members failed: ", err)
+	}
+	for _, stream := range streams[delivered:] {
+		stream.contents <- failingReader{err: err}
+	}
+}
+
+// Calls deliver() with the contents of each of the first count regular files
+func readTarMembers(filename string, count int, deliver func([]byte)) error {
+	tarReader, closer, err := openTarArchive(filename)
+	if err != nil {
+		return err
+	}
+	if tarReader == nil {
+		return fmt.Errorf("%s is not a tar archive any more", filename)
+	}
+	defer func() {
+		_ = closer.Close()
+	}()
+
+	for count > 0 {
+		header, err := tarReader.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read tar archive: %w", err)
+		}
+
+		if header.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		contents, err := io.ReadAll(tarReader)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from tar archive: %w", header.Name, err)
+		}
+		deliver(contents)
+		count--
+	}
+
+	return nil
+}
+
+// Calls deliver() with the contents of each of the first count regular files
+func readZipMembers(filename string, count int, deliver func([]byte)) error {
+	zipReader, err := zip.OpenReader(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read zip archive: %w", err)
+	}
+	defer func() {
+		_ = zipReader.Close()
+	}()
+
+	for _, file := range zipReader.File {
+		if count == 0 {
+			return nil
+		}
+		if !file.Mode().IsRegular() {
+			continue
+		}
+
+		contents, err := readZipMember(file)
+		if err != nil {
+			return fmt.Errorf("failed to read %s from zip archive: %w", file.Name, err)
+		}
+		deliver(contents)
+		count--
+	}
+
+	return nil
+}
+
+func readZipMember(file *zip.File) ([]byte, error) {
+	stream, err := file.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stream.Close()
+	}()
+
+	return io.ReadAll(stream)
+}
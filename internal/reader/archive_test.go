@@ -0,0 +1,102 @@
+package reader
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/walles/moor/internal/linemetadata"
+	"gotest.tools/v3/assert"
+)
+
+func assertArchiveMembers(t *testing.T, filename string) {
+	readers, err := NewFromArchiveOrFilename(filename, formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.Equal(t, len(readers), 2)
+
+	assert.Equal(t, *readers[0].Name, filename+":a.txt")
+	assert.Equal(t, *readers[1].Name, filename+":dir/b.txt")
+
+	for _, reader := range readers {
+		assert.NilError(t, reader.Wait())
+	}
+	assert.Equal(t, readers[0].GetLine(linemetadata.Index{}).Plain(), "first")
+	assert.Equal(t, readers[1].GetLine(linemetadata.Index{}).Plain(), "second")
+}
+
+func TestReadTarGzArchive(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.tar.gz")
+	file, err := os.Create(filename)
+	assert.NilError(t, err)
+
+	gzipWriter := gzip.NewWriter(file)
+	tarWriter := tar.NewWriter(gzipWriter)
+	assert.NilError(t, tarWriter.WriteHeader(&tar.Header{Name: "dir/", Typeflag: tar.TypeDir, Mode: 0o755}))
+	for _, member := range [][2]string{{"a.txt", "first\n"}, {"dir/b.txt", "second\n"}} {
+		assert.NilError(t, tarWriter.WriteHeader(&tar.Header{Name: member[0], Typeflag: tar.TypeReg, Mode: 0o644, Size: int64(len(member[1]))}))
+		_, err = tarWriter.Write([]byte(member[1]))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, tarWriter.Close())
+	assert.NilError(t, gzipWriter.Close())
+	assert.NilError(t, file.Close())
+
+	assertArchiveMembers(t, filename)
+}
+
+func writeZipArchive(t *testing.T, filename string, members [][2]string) {
+	file, err := os.Create(filename)
+	assert.NilError(t, err)
+
+	zipWriter := zip.NewWriter(file)
+	_, err = zipWriter.Create("dir/")
+	assert.NilError(t, err)
+	for _, member := range members {
+		writer, err := zipWriter.Create(member[0])
+		assert.NilError(t, err)
+		_, err = writer.Write([]byte(member[1]))
+		assert.NilError(t, err)
+	}
+	assert.NilError(t, zipWriter.Close())
+	assert.NilError(t, file.Close())
+}
+
+func TestReadZipArchive(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.zip")
+	writeZipArchive(t, filename, [][2]string{{"a.txt", "first\n"}, {"dir/b.txt", "second\n"}})
+
+	assertArchiveMembers(t, filename)
+}
+
+// Members are read in the background, by then the archive may have lost some
+func TestReadArchiveChangedWhileReading(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "test.zip")
+	writeZipArchive(t, filename, [][2]string{{"a.txt", "first\n"}})
+
+	first := newArchiveMemberStream()
+	second := newArchiveMemberStream()
+	readArchiveMembers(filename, []*archiveMemberStream{first, second})
+
+	contents, err := io.ReadAll(first)
+	assert.NilError(t, err)
+	assert.Equal(t, string(contents), "first\n")
+
+	_, err = io.ReadAll(second)
+	assert.ErrorContains(t, err, "changed while reading it")
+}
+
+func TestReadNonArchive(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "plain.txt")
+	assert.NilError(t, os.WriteFile(filename, []byte("just text\n"), 0o600))
+
+	readers, err := NewFromArchiveOrFilename(filename, formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.Equal(t, len(readers), 1)
+	assert.Equal(t, *readers[0].FileName, filename)
+}
@@ -0,0 +1,174 @@
+package reader
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/walles/moor/internal/linemetadata"
+)
+
+// Like the "\t2024-01-02 12:00:00.000000000 +0100" after the file names in
+// "diff -u" output. Tabs are spaces in the plain text.
+var diffTimestamp = regexp.MustCompile(`\s+\d{4}-\d\d-\d\d \d\d:\d\d:\d\d.*$`)
+
+// A DiffMarker is a file header or a hunk header in unified diff input, like
+// the output of "git diff" or "git log -p". See DiffMarkers().
+type DiffMarker struct {
+	Index linemetadata.Index
+
+	// True for file headers, false for hunk headers
+	IsFileHeader bool
+
+	// The name of the changed file. Hunk headers get the name from the file
+	// header they belong to.
+	FileName string
+}
+
+// Where DiffMarkers() is in the input. Streaming input can get long, so we pick
+// up from here rather than starting over when more lines arrive.
+type diffScanState struct {
+	markers []DiffMarker
+
+	// This many lines have been scanned
+	scannedCount int
+
+	// True between a "diff" line and its first hunk, where the "---" and "+++"
+	// lines belong to the "diff" line's file header
+	inDiffHeader bool
+}
+
+// DiffMarkers returns the file headers and hunk headers in the input, in input
+// order. Returns an empty list if this is not a unified diff.
+func (reader *ReaderImpl) DiffMarkers() []DiffMarker {
+	reader.Lock()
+	defer reader.Unlock()
+
+	state := &reader.diffScanState
+	lineCount := len(reader.lines)
+	if !reader.Done.Load() || !reader.endsWithNewline {
+		// The last line may still grow, and we may need to see the line after a
+		// "---" line to know what it is
+		lineCount--
+	}
+
+	for state.scannedCount < lineCount {
+		index := state.scannedCount
+		state.scannedCount++
+
+		if !mightBeDiffMarker(reader.lines[index].raw) {
+			// Don't compute the plain text of every line, that would use lots
+			// of memory for large inputs
+			continue
+		}
+
+		plain := reader.plainUnlocked(index)
+		if strings.HasPrefix(plain, "diff ") {
+			state.inDiffHeader = true
+			state.markers = append(state.markers, DiffMarker{
+				Index:        linemetadata.IndexFromZeroBased(index),
+				IsFileHeader: true,
+				FileName:     fileNameFromDiffLine(plain),
+			})
+			continue
+		}
+
+		if strings.HasPrefix(plain, "@@ ") {
+			state.inDiffHeader = false
+			state.markers = append(state.markers, DiffMarker{
+				Index:    linemetadata.IndexFromZeroBased(index),
+				FileName: state.currentFileName(),
+			})
+			continue
+		}
+
+		if !strings.HasPrefix(plain, "--- ") || index+1 >= len(reader.lines) {
+			continue
+		}
+		next := reader.plainUnlocked(index + 1)
+		if !strings.HasPrefix(next, "+++ ") {
+			continue
+		}
+
+		fileName := fileNameFromDiffLine(next)
+		if fileName == "" {
+			// Deleted file, "+++ /dev/null"
+			fileName = fileNameFromDiffLine(plain)
+		}
+
+		if state.inDiffHeader && len(state.markers) > 0 {
+			// Part of a "diff" line's file header, the "+++" name is better
+			// than whatever we got from the "diff" line
+			if fileName != "" {
+				state.markers[len(state.markers)-1].FileName = fileName
+			}
+			continue
+		}
+
+		if fileName == "" {
+			// Not much of a file header
+			continue
+		}
+
+		// File header without a "diff" line, as from "diff -u"
+		state.inDiffHeader = true
+		state.markers = append(state.markers, DiffMarker{
+			Index:        linemetadata.IndexFromZeroBased(index),
+			IsFileHeader: true,
+			FileName:     fileName,
+		})
+	}
+
+	return state.markers
+}
+
+// True for lines starting with "diff ", "@@ " or "--- ", or with formatting
+func mightBeDiffMarker(raw string) bool {
+	for _, prefix := range []string{"diff ", "@@ ", "--- ", "\x1b"} {
+		if strings.HasPrefix(raw, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+func (state *diffScanState) currentFileName() string {
+	for i := len(state.markers) - 1; i >= 0; i-- {
+		if state.markers[i].IsFileHeader {
+			return state.markers[i].FileName
+		}
+	}
+	return ""
+}
+
+// Get the file name from a "diff --git a/x b/x", "--- a/x" or "+++ b/x" line.
+// Returns an empty string for /dev/null.
+func fileNameFromDiffLine(line string) string {
+	if strings.HasPrefix(line, "diff ") {
+		// The "b/" name is last on the line. File names with spaces make this
+		// ambiguous, but then the "+++" line will help us out.
+		fields := strings.Fields(line)
+		return strings.TrimPrefix(fields[len(fields)-1], "b/")
+	}
+
+	name := line[len("+++ "):]
+
+	// "diff -u" puts a timestamp after the name
+	name = diffTimestamp.ReplaceAllString(name, "")
+
+	if name == "/dev/null" {
+		return ""
+	}
+
+	for _, prefix := range []string{"a/", "b/"} {
+		if strings.HasPrefix(name, prefix) {
+			return name[len(prefix):]
+		}
+	}
+	return name
+}
+
+// plainUnlocked() assumes that its caller is holding the lock
+func (reader *ReaderImpl) plainUnlocked(index int) string {
+	lineIndex := linemetadata.IndexFromZeroBased(index)
+	return reader.lines[index].Plain(&lineIndex)
+}
@@ -0,0 +1,78 @@
+package reader
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"gotest.tools/v3/assert"
+)
+
+func describeDiffMarkers(markers []DiffMarker) []string {
+	descriptions := []string{}
+	for _, marker := range markers {
+		kind := "hunk"
+		if marker.IsFileHeader {
+			kind = "file"
+		}
+		descriptions = append(descriptions, fmt.Sprintf("%d %s %s", marker.Index.Index(), kind, marker.FileName))
+	}
+	return descriptions
+}
+
+func TestDiffMarkersGit(t *testing.T) {
+	testMe := NewFromTextForTesting("git log -p", strings.Join([]string{
+		"commit 0123456789abcdef",
+		"Author: Someone <someone@example.com>",
+		"",
+		"    Commit message",
+		"",
+		"diff --git a/README.md b/README.md",
+		"index 1234567..89abcde 100644",
+		"--- a/README.md",
+		"+++ b/README.md",
+		"@@ -1,2 +1,2 @@",
+		"-old",
+		"+new",
+		" context",
+		"@@ -10,1 +10,1 @@",
+		"-- a list item",
+		"+- another list item",
+		"diff --git a/gone.txt b/gone.txt",
+		"deleted file mode 100644",
+		"--- a/gone.txt",
+		"+++ /dev/null",
+		"@@ -1 +0,0 @@",
+		"-gone",
+	}, "\n"))
+
+	markers := testMe.DiffMarkers()
+	assert.DeepEqual(t, describeDiffMarkers(markers), []string{
+		"5 file README.md",
+		"9 hunk README.md",
+		"13 hunk README.md",
+		"16 file gone.txt",
+		"20 hunk gone.txt",
+	})
+}
+
+func TestDiffMarkersPlainDiff(t *testing.T) {
+	testMe := NewFromTextForTesting("diff -u", strings.Join([]string{
+		"--- old/file.txt\t2024-01-01 12:00:00",
+		"+++ new/file.txt\t2024-01-02 12:00:00",
+		"@@ -1 +1 @@",
+		"-a",
+		"+b",
+	}, "\n"))
+
+	markers := testMe.DiffMarkers()
+	assert.DeepEqual(t, describeDiffMarkers(markers), []string{
+		"0 file new/file.txt",
+		"2 hunk new/file.txt",
+	})
+}
+
+func TestDiffMarkersNotADiff(t *testing.T) {
+	testMe := NewFromTextForTesting("markdown", "# Heading\n\n--- \n+++ \n")
+	assert.Equal(t, len(testMe.DiffMarkers()), 0)
+}
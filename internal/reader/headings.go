@@ -0,0 +1,120 @@
+package reader
+
+import (
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/textstyles"
+)
+
+// A Heading is a line starting a section of the input, see Headings()
+type Heading struct {
+	Index linemetadata.Index
+
+	// Zero for top level headings, higher numbers for subheadings
+	Level int
+
+	// The heading line without formatting
+	Text string
+}
+
+// Headings returns the man page section headings, Markdown headings and other
+// headings recognized by the input's lexer, in input order.
+func (reader *ReaderImpl) Headings() []Heading {
+	reader.Lock()
+	lines := reader.lines
+	if reader.headings != nil && reader.headingsLineCount == len(lines) {
+		headings := reader.headings
+		reader.Unlock()
+		return headings
+	}
+	reader.Unlock()
+
+	headings := findHeadings(lines, reader.lexer)
+
+	reader.Lock()
+	reader.headings = headings
+	reader.headingsLineCount = len(lines)
+	reader.Unlock()
+
+	return headings
+}
+
+func findHeadings(lines []*Line, lexer chroma.Lexer) []Heading {
+	isHeading := make(map[int]int)
+
+	plainLines := make([]string, 0, len(lines))
+	var byteCount int64
+	for i, line := range lines {
+		lineIndex := linemetadata.IndexFromZeroBased(i)
+		plain := line.Plain(&lineIndex)
+		plainLines = append(plainLines, plain)
+		byteCount += int64(len(plain))
+
+		if textstyles.IsManPageHeading(line.raw) {
+			isHeading[i] = 0
+		}
+	}
+
+	if lexer != nil && byteCount <= MAX_HIGHLIGHT_SIZE {
+		for index, level := range lexerHeadings(plainLines, lexer) {
+			isHeading[index] = level
+		}
+	}
+
+	headings := []Heading{}
+	for i, plain := range plainLines {
+		level, found := isHeading[i]
+		if !found {
+			continue
+		}
+
+		headings = append(headings, Heading{
+			Index: linemetadata.IndexFromZeroBased(i),
+			Level: level,
+			Text:  strings.TrimSpace(plain),
+		})
+	}
+
+	return headings
+}
+
+// Map from line indices to heading levels, for lines the lexer says are
+// headings
+func lexerHeadings(plainLines []string, lexer chroma.Lexer) map[int]int {
+	headings := make(map[int]int)
+
+	iterator, err := lexer.Tokenise(nil, strings.Join(plainLines, "\n")+"\n")
+	if err != nil {
+		log.Info("Tokenizing for headings failed: ", err)
+		return headings
+	}
+
+	lineIndex := 0
+	for token := iterator(); token != chroma.EOF; token = iterator() {
+		if token.Type == chroma.GenericHeading || token.Type == chroma.GenericSubheading {
+			if _, found := headings[lineIndex]; !found {
+				headings[lineIndex] = headingLevel(token)
+			}
+		}
+
+		lineIndex += strings.Count(token.Value, "\n")
+	}
+
+	return headings
+}
+
+func headingLevel(token chroma.Token) int {
+	hashes := len(token.Value) - len(strings.TrimLeft(token.Value, "#"))
+	if hashes > 0 {
+		// Markdown, "#" is level zero
+		return hashes - 1
+	}
+
+	if token.Type == chroma.GenericSubheading {
+		return 1
+	}
+	return 0
+}
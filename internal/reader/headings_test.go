@@ -0,0 +1,64 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+	"gotest.tools/v3/assert"
+)
+
+func TestMarkdownHeadings(t *testing.T) {
+	markdown := strings.Join([]string{
+		"# Title",
+		"",
+		"Some text",
+		"",
+		"## Section",
+		"",
+		"```sh",
+		"# Not a heading",
+		"```",
+		"",
+		"### Subsection",
+	}, "\n")
+
+	testMe, err := NewFromStream("README.md", strings.NewReader(markdown), formatters.TTY16m, ReaderOptions{
+		Lexer: lexers.Get("markdown"),
+		Style: styles.Get("native"),
+	})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	headings := testMe.Headings()
+	assert.Equal(t, len(headings), 3)
+
+	assert.Equal(t, headings[0].Index.Index(), 0)
+	assert.Equal(t, headings[0].Level, 0)
+	assert.Equal(t, headings[0].Text, "# Title")
+
+	assert.Equal(t, headings[1].Index.Index(), 4)
+	assert.Equal(t, headings[1].Level, 1)
+
+	assert.Equal(t, headings[2].Index.Index(), 10)
+	assert.Equal(t, headings[2].Level, 2)
+	assert.Equal(t, headings[2].Text, "### Subsection")
+}
+
+func TestManPageHeadings(t *testing.T) {
+	testMe := NewFromTextForTesting("man page", strings.Join([]string{
+		"N\bNA\bAM\bME\bE",
+		"       moor - the nice pager",
+		"",
+		"D\bDE\bES\bSC\bCR\bRI\bIP\bPT\bTI\bIO\bON\bN",
+		"       Pages things",
+	}, "\n"))
+
+	headings := testMe.Headings()
+	assert.Equal(t, len(headings), 2)
+	assert.Equal(t, headings[0].Text, "NAME")
+	assert.Equal(t, headings[1].Index.Index(), 3)
+	assert.Equal(t, headings[1].Text, "DESCRIPTION")
+}
@@ -2,6 +2,7 @@ package reader
 
 import (
 	"regexp"
+	"strings"
 	"sync"
 
 	"github.com/walles/moor/internal/linemetadata"
@@ -68,3 +69,15 @@ func (line *Line) Plain(lineIndex *linemetadata.Index) string {
 	}
 	return *line.plain
 }
+
+// IsCommandLine returns true if a shell command was typed on this line,
+// according to its OSC 133 prompt markers
+func (line *Line) IsCommandLine() bool {
+	return strings.Contains(line.PromptMarkers(), "B")
+}
+
+// PromptMarkers returns the OSC 133 prompt marker letters in this line, see
+// textstyles.PromptMarkers()
+func (line *Line) PromptMarkers() string {
+	return textstyles.PromptMarkers(line.raw)
+}
@@ -21,3 +21,14 @@ func (nl *NumberedLine) Plain() string {
 func (nl *NumberedLine) HighlightedTokens(plainTextStyle twin.Style, standoutStyle *twin.Style, search *regexp.Regexp) textstyles.StyledRunesWithTrailer {
 	return nl.Line.HighlightedTokens(plainTextStyle, standoutStyle, search, &nl.Index)
 }
+
+// Rune index ranges of all pattern matches in the plain text of this line. The
+// end of each range is exclusive. A nil pattern gives you no ranges.
+func (nl *NumberedLine) MatchRanges(pattern *regexp.Regexp) [][2]int {
+	plain := nl.Plain()
+	matchRanges := getMatchRanges(&plain, pattern)
+	if matchRanges == nil {
+		return nil
+	}
+	return matchRanges.Matches
+}
@@ -0,0 +1,54 @@
+package reader
+
+import (
+	"github.com/walles/moor/internal/linemetadata"
+)
+
+// A PromptMarker is a line with OSC 133 prompt markers in it. See
+// PromptMarkers().
+type PromptMarker struct {
+	Index linemetadata.Index
+
+	// The marker letters on this line, see textstyles.PromptMarkers()
+	Markers string
+}
+
+// Where PromptMarkers() is in the input. Streaming input can get long, so we
+// pick up from here rather than starting over when more lines arrive.
+type promptScanState struct {
+	markers []PromptMarker
+
+	// This many lines have been scanned
+	scannedCount int
+}
+
+// PromptMarkers returns the lines with OSC 133 prompt markers in them, in input
+// order. Returns an empty list if there are none.
+func (reader *ReaderImpl) PromptMarkers() []PromptMarker {
+	reader.Lock()
+	defer reader.Unlock()
+
+	state := &reader.promptScanState
+	lineCount := len(reader.lines)
+	if !reader.Done.Load() {
+		// The last line may still grow
+		lineCount--
+	}
+
+	for state.scannedCount < lineCount {
+		index := state.scannedCount
+		state.scannedCount++
+
+		markers := reader.lines[index].PromptMarkers()
+		if markers == "" {
+			continue
+		}
+
+		state.markers = append(state.markers, PromptMarker{
+			Index:   linemetadata.IndexFromZeroBased(index),
+			Markers: markers,
+		})
+	}
+
+	return state.markers
+}
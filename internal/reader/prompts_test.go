@@ -0,0 +1,34 @@
+package reader
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/styles"
+	"gotest.tools/v3/assert"
+)
+
+func TestPromptMarkers(t *testing.T) {
+	testMe := NewFromTextForTesting("", "\x1b]133;A\x07$ \x1b]133;B\x07ls\n\x1b]133;C\x07output\nmore output\n\x1b]133;D;0\x07")
+
+	markers := testMe.PromptMarkers()
+	assert.Equal(t, len(markers), 3)
+	assert.Equal(t, markers[0].Index.Index(), 0)
+	assert.Equal(t, markers[0].Markers, "AB")
+	assert.Equal(t, markers[1].Index.Index(), 1)
+	assert.Equal(t, markers[1].Markers, "C")
+	assert.Equal(t, markers[2].Index.Index(), 3)
+	assert.Equal(t, markers[2].Markers, "D")
+}
+
+func TestPromptMarkersWhileStreaming(t *testing.T) {
+	testMe, err := NewFromStream("", strings.NewReader("\x1b]133;B\x07ls\noutput\n\x1b]133;B\x07pwd"), formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	// Asking again gets the same answer without rescanning
+	assert.Equal(t, len(testMe.PromptMarkers()), 2)
+	assert.Equal(t, len(testMe.PromptMarkers()), 2)
+	assert.Equal(t, testMe.promptScanState.scannedCount, 3)
+}
@@ -78,6 +78,14 @@ type ReaderImpl struct {
 
 	lines []*Line
 
+	// Where in the input each line starts, in bytes. One entry per line.
+	// Tracked while reading, since highlighting changes the lines but not the
+	// input.
+	lineOffsets []int64
+
+	// Where in the input the line after the last one starts
+	endOffset int64
+
 	// Display name for the buffer. If not set, no buffer name will be shown.
 	//
 	// For files, this will be the file name. For our help text, this will be
@@ -91,6 +99,29 @@ type ReaderImpl struct {
 	// How many bytes have we read so far?
 	bytesCount int64
 
+	// Used for highlighting, and for finding headings. nil if we don't know
+	// the input language.
+	lexer chroma.Lexer
+
+	// The lexer the lines were highlighted with, nil if they haven't been
+	// highlighted. Can be a guess when lexer is nil.
+	highlightedWith chroma.Lexer
+
+	// Held while highlighting, so that highlighting again with another style
+	// waits for any earlier highlighting to finish
+	highlighting sync.Mutex
+
+	// Headings() is expensive, so we remember what it returned last time.
+	// Only valid as long as the line count is headingsLineCount.
+	headings          []Heading
+	headingsLineCount int
+
+	// For DiffMarkers()
+	diffScanState diffScanState
+
+	// For PromptMarkers()
+	promptScanState promptScanState
+
 	endsWithNewline bool
 
 	Err error
@@ -160,6 +191,7 @@ func (reader *ReaderImpl) preAllocLines() {
 
 	// We had no lines since before, this is the expected happy path.
 	reader.lines = make([]*Line, 0, lineCount)
+	reader.lineOffsets = make([]int64, 0, lineCount)
 }
 
 // This is the reader's main function. It will be run in a goroutine. First it
@@ -167,11 +199,13 @@ func (reader *ReaderImpl) preAllocLines() {
 func (reader *ReaderImpl) readStream(stream io.Reader, formatter chroma.Formatter, options ReaderOptions) {
 	reader.consumeLinesFromStream(stream)
 
+	reader.highlighting.Lock()
 	t0 := time.Now()
 	style := <-reader.highlightingStyle
 	options.Style = &style
 	highlightFromMemory(reader, formatter, options)
 	log.Debug("highlightFromMemory() took ", time.Since(t0))
+	reader.highlighting.Unlock()
 
 	reader.Done.Store(true)
 	select {
@@ -218,6 +252,11 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 	bufioReader := bufio.NewReader(&inspectionReader)
 	completeLine := make([]byte, 0)
 
+	// When tailing, we continue where we stopped last time
+	reader.Lock()
+	streamOffset := reader.endOffset
+	reader.Unlock()
+
 	t0 := time.Now()
 	for {
 		reader.maybePause()
@@ -268,6 +307,9 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 		newLineString := string(completeLine)
 		newLine := NewLine(newLineString)
 
+		// The line and its line ending have been consumed from the input
+		nextLineOffset := streamOffset + inspectionReader.bytesCount - int64(bufioReader.Buffered())
+
 		reader.Lock()
 		if len(reader.lines) > 0 && !reader.endsWithNewline {
 			// The last line didn't end with a newline, append to it
@@ -275,8 +317,10 @@ func (reader *ReaderImpl) consumeLinesFromStream(stream io.Reader) {
 			newLine = NewLine(newLineString)
 			reader.lines[len(reader.lines)-1] = &newLine
 		} else {
+			reader.lineOffsets = append(reader.lineOffsets, reader.endOffset)
 			reader.lines = append(reader.lines, &newLine)
 		}
+		reader.endOffset = nextLineOffset
 		reader.endsWithNewline = true
 
 		reader.Unlock()
@@ -453,6 +497,8 @@ func newReaderFromStream(reader io.Reader, originalFileName *string, formatter c
 		FileName: originalFileName,
 		Name:     originalFileName,
 
+		lexer: options.Lexer,
+
 		pauseAfterLines:        pauseAfterLines,
 		pauseAfterLinesUpdated: make(chan bool, 1),
 
@@ -489,10 +535,14 @@ func newReaderFromStream(reader io.Reader, originalFileName *string, formatter c
 func NewFromTextForTesting(name string, text string) *ReaderImpl {
 	noExternalNewlines := strings.Trim(text, "\n")
 	lines := []*Line{}
+	lineOffsets := []int64{}
+	var offset int64
 	if len(noExternalNewlines) > 0 {
 		for _, lineString := range strings.Split(noExternalNewlines, "\n") {
 			line := NewLine(lineString)
 			lines = append(lines, &line)
+			lineOffsets = append(lineOffsets, offset)
+			offset += int64(len(lineString)) + 1
 		}
 	}
 	done := atomic.Bool{}
@@ -501,6 +551,8 @@ func NewFromTextForTesting(name string, text string) *ReaderImpl {
 	highlightingDone.Store(true) // No highlighting to do = nothing left = Done!
 	returnMe := &ReaderImpl{
 		lines:                   lines,
+		lineOffsets:             lineOffsets,
+		endOffset:               int64(len(strings.TrimLeft(text, "\n"))),
 		Done:                    &done,
 		HighlightingDone:        &highlightingDone,
 		doneWaitingForFirstByte: make(chan bool, 1),
@@ -750,6 +802,65 @@ func highlightFromMemory(reader *ReaderImpl, formatter chroma.Formatter, options
 	}
 
 	reader.setText(*highlighted)
+
+	reader.Lock()
+	reader.highlightedWith = options.Lexer
+	reader.Unlock()
+}
+
+// Rehighlight highlights the lines in memory again using another style. Unlike
+// reading the input again, this works for streams as well. Input that hasn't
+// been highlighted yet gets the new style when it is.
+//
+// Highlighting happens in the background.
+func (reader *ReaderImpl) Rehighlight(style chroma.Style, formatter chroma.Formatter) {
+	select {
+	case <-reader.highlightingStyle:
+		// Still reading, swap in the new style
+		reader.highlightingStyle <- style
+		return
+	default:
+	}
+
+	reader.HighlightingDone.Store(false)
+	go func() {
+		defer func() {
+			reader.HighlightingDone.Store(true)
+			PanicHandler("Rehighlight()", recover(), debug.Stack())
+		}()
+
+		reader.highlighting.Lock()
+		defer reader.highlighting.Unlock()
+
+		reader.Lock()
+		lexer := reader.highlightedWith
+		text := strings.Builder{}
+		if lexer != nil {
+			// Highlighting doesn't change the plain text
+			for i, line := range reader.lines {
+				lineIndex := linemetadata.IndexFromZeroBased(i)
+				text.WriteString(line.Plain(&lineIndex))
+				text.WriteString("\n")
+			}
+		}
+		reader.Unlock()
+
+		if lexer == nil || formatter == nil {
+			// Nothing highlighted, nothing to do
+			return
+		}
+
+		highlighted, err := Highlight(text.String(), style, formatter, lexer)
+		if err != nil {
+			log.Warn("Highlighting again failed: ", err)
+			return
+		}
+		if highlighted == nil {
+			return
+		}
+
+		reader.setText(*highlighted)
+	}()
 }
 
 // createStatusUnlocked() assumes that its caller is holding the lock
@@ -820,6 +931,19 @@ func (reader *ReaderImpl) GetLineCount() int {
 	return len(reader.lines)
 }
 
+// ByteOffset returns where in the input the given line starts. For lines past
+// the end, you get the end of the input.
+func (reader *ReaderImpl) ByteOffset(index linemetadata.Index) int64 {
+	reader.Lock()
+	defer reader.Unlock()
+
+	if index.Index() < len(reader.lineOffsets) {
+		return reader.lineOffsets[index.Index()]
+	}
+
+	return reader.endOffset
+}
+
 func (reader *ReaderImpl) ShouldShowLineCount() bool {
 	if reader.Done.Load() {
 		// We are done, the number won't change, show it!
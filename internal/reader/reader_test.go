@@ -255,6 +255,56 @@ func TestStatusText(t *testing.T) {
 	assert.Equal(t, line.StatusText, "empty: <empty>")
 }
 
+func TestByteOffset(t *testing.T) {
+	testMe := NewFromTextForTesting("", "ab\n\x1b[1mcd\x1b[0m\nef")
+	assert.Equal(t, testMe.ByteOffset(linemetadata.Index{}), int64(0))
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(1)), int64(3))
+
+	// Formatting is part of the input, so it counts
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(2)), int64(14))
+
+	// Past the end
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(5)), int64(16))
+}
+
+func TestByteOffsetWhileStreaming(t *testing.T) {
+	testMe, err := NewFromStream("", strings.NewReader("ab\r\ncd\nef"), formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+
+	// Both bytes of the CRLF line ending count
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(1)), int64(4))
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(2)), int64(7))
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(3)), int64(9))
+}
+
+func TestByteOffsetAfterHighlighting(t *testing.T) {
+	testMe, err := NewFromStream("main.go", strings.NewReader("package main\n\nfunc main() {}\n"), formatters.TTY16m,
+		ReaderOptions{Lexer: lexers.Get("go"), Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+	assert.Assert(t, strings.Contains(testMe.lines[2].raw, "\x1b["), "Should have been highlighted")
+
+	// Highlighting must not move the lines around in the input
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(2)), int64(14))
+	assert.Equal(t, testMe.ByteOffset(linemetadata.IndexFromZeroBased(3)), int64(29))
+}
+
+func TestRehighlight(t *testing.T) {
+	testMe, err := NewFromStream("main.go", strings.NewReader("package main\n\nfunc main() {}\n"), formatters.TTY16m,
+		ReaderOptions{Lexer: lexers.Get("go"), Style: styles.Get("native")})
+	assert.NilError(t, err)
+	assert.NilError(t, testMe.Wait())
+	before := testMe.lines[0].raw
+
+	testMe.Rehighlight(*styles.Get("monokai"), formatters.TTY16m)
+	assert.NilError(t, testMe.Wait())
+
+	assert.Assert(t, testMe.lines[0].raw != before, "Should have been highlighted differently")
+	assert.Equal(t, testMe.GetLine(linemetadata.Index{}).Plain(), "package main")
+	assert.Equal(t, testMe.GetLineCount(), 3)
+}
+
 func testCompressedFile(t *testing.T, filename string) {
 	filenameWithPath := path.Join(samplesDir, filename)
 	reader, e := NewFromFilename(filenameWithPath, formatters.TTY16m, ReaderOptions{Style: styles.Get("native")})
@@ -30,12 +30,27 @@ type renderedLine struct {
 // Refresh the whole pager display, both contents lines and the status line at
 // the bottom
 func (p *Pager) redraw(spinner string) {
+	if p.isSplit() && !p.isShowingHelp {
+		p.redrawSplit(spinner)
+		return
+	}
+
 	p.screen.Clear()
+
+	statusText := p.drawPane(spinner)
+	p.mode.drawFooter(p.bufferStatusPrefix()+statusText, spinner)
+
+	p.screen.Show()
+}
+
+// Draw the contents lines, the scrollbar and the EOF marker. The status line is
+// left to the caller.
+func (p *Pager) drawPane(spinner string) (statusText string) {
 	p.longestLineLength = 0
 
 	lastUpdatedScreenLineNumber := -1
 	var renderedScreenLines [][]twin.StyledRune
-	renderedScreenLines, statusText := p.renderScreenLines()
+	renderedScreenLines, statusText = p.renderScreenLines()
 	for screenLineNumber, row := range renderedScreenLines {
 		lastUpdatedScreenLineNumber = screenLineNumber
 		column := 0
@@ -44,7 +59,9 @@ func (p *Pager) redraw(spinner string) {
 		}
 	}
 
-	// Status line code follows
+	if p.ShowScrollbar {
+		p.drawScrollbar()
+	}
 
 	eofSpinner := spinner
 	if eofSpinner == "" {
@@ -57,9 +74,7 @@ func (p *Pager) redraw(spinner string) {
 		column += p.screen.SetCell(column, lastUpdatedScreenLineNumber+1, cell)
 	}
 
-	p.mode.drawFooter(statusText, spinner)
-
-	p.screen.Show()
+	return statusText
 }
 
 // Render screen lines into an array of lines consisting of Cells.
@@ -74,9 +89,24 @@ func (p *Pager) renderScreenLines() (lines [][]twin.StyledRune, statusText strin
 		return
 	}
 
+	// Line numbers in the header should line up with the ones below it
+	lastVisibleLine := p.Reader().GetLine(renderedLines[len(renderedLines)-1].inputLineIndex)
+	numberPrefixLength := 0
+	if lastVisibleLine != nil {
+		numberPrefixLength = p.getLineNumberPrefixLength(lastVisibleLine.Number)
+	}
+	headerLines := p.renderHeaderLines(numberPrefixLength)
+	renderedLines = append(headerLines, renderedLines...)
+
 	// Construct the screen lines to return
 	screenLines := make([][]twin.StyledRune, 0, len(renderedLines))
-	for _, renderedLine := range renderedLines {
+	for i, renderedLine := range renderedLines {
+		isHeaderLine := i < len(headerLines)
+		if !isHeaderLine && p.selection != nil && p.selection.contains(renderedLine.inputLineIndex) {
+			screenLines = append(screenLines, p.highlightSelectedLine(renderedLine.cells))
+			continue
+		}
+
 		screenLines = append(screenLines, renderedLine.cells)
 
 		if renderedLine.trailer == twin.StyleDefault {
@@ -84,7 +114,7 @@ func (p *Pager) renderScreenLines() (lines [][]twin.StyledRune, statusText strin
 		}
 
 		// Fill up with the trailer
-		screenWidth, _ := p.screen.Size()
+		screenWidth := p.contentWidth()
 		for len(screenLines[len(screenLines)-1]) < screenWidth {
 			screenLines[len(screenLines)-1] =
 				append(screenLines[len(screenLines)-1], twin.NewStyledRune(' ', renderedLine.trailer))
@@ -94,6 +124,66 @@ func (p *Pager) renderScreenLines() (lines [][]twin.StyledRune, statusText strin
 	return screenLines, statusText
 }
 
+// Render the sticky header lines. They come from the unfiltered input, and are
+// wrapped just like the other lines.
+func (p *Pager) renderHeaderLines(numberPrefixLength int) []renderedLine {
+	count := p.visibleHeaderLineCount()
+	screenLineCount := p.headerScreenLineCount()
+
+	rendered := make([]renderedLine, 0, screenLineCount)
+	for i := 0; i < count; i++ {
+		line := p.reader.GetLine(linemetadata.IndexFromZeroBased(i))
+		if line == nil {
+			break
+		}
+
+		highlighted := line.HighlightedTokens(plainTextStyle, standoutStyle, p.searchPattern)
+		wrapped := p.wrapHeaderLine(highlighted.StyledRunes)
+		for wrapIndex, inputLinePart := range wrapped {
+			lineNumber := line.Number
+			visibleLineNumber := &lineNumber
+			if wrapIndex > 0 {
+				visibleLineNumber = nil
+			}
+
+			rendered = append(rendered, renderedLine{
+				inputLineIndex: line.Index,
+				wrapIndex:      wrapIndex,
+				cells:          p.decorateLine(visibleLineNumber, numberPrefixLength, inputLinePart),
+			})
+		}
+
+		// Like for other lines, the trailer goes on the last wrap line only
+		rendered[len(rendered)-1].trailer = highlighted.Trailer
+	}
+
+	if len(rendered) > screenLineCount {
+		rendered = rendered[:screenLineCount]
+	}
+
+	return rendered
+}
+
+// Wrap a header line if wrapping is enabled.
+//
+// The wrapping width can't depend on the scroll position, since the number of
+// header screen lines decides how many other lines fit on screen. So this leaves
+// room for the widest possible line number, rather than for the ones currently
+// visible.
+func (p *Pager) wrapHeaderLine(contents []twin.StyledRune) [][]twin.StyledRune {
+	if !p.WrapLongLines {
+		return [][]twin.StyledRune{contents}
+	}
+
+	numberPrefixLength := 0
+	lastLineNumber := linemetadata.NumberFromLength(p.reader.GetLineCount())
+	if lastLineNumber != nil {
+		numberPrefixLength = p.getLineNumberPrefixLength(*lastLineNumber)
+	}
+
+	return wrapLine(p.contentWidth()-numberPrefixLength, contents)
+}
+
 // Render all lines that should go on the screen.
 //
 // Returns both the lines and a suitable status text.
@@ -103,7 +193,8 @@ func (p *Pager) renderScreenLines() (lines [][]twin.StyledRune, statusText strin
 //
 // The maximum number of lines returned by this method is limited by the screen
 // height. If the status line is visible, you'll get at most one less than the
-// screen height from this method.
+// screen height from this method. Sticky header lines are not included, see
+// renderHeaderLines().
 func (p *Pager) renderLines() ([]renderedLine, string) {
 	var lineIndex linemetadata.Index
 	if p.lineIndex() != nil {
@@ -184,10 +275,10 @@ func (p *Pager) renderLines() ([]renderedLine, string) {
 // indent, and to (optionally) render the line number.
 func (p *Pager) renderLine(line *reader.NumberedLine, numberPrefixLength int) []renderedLine {
 	highlighted := line.HighlightedTokens(plainTextStyle, standoutStyle, p.searchPattern)
+	p.highlightFocusedHyperlink(line, highlighted.StyledRunes)
 	var wrapped [][]twin.StyledRune
 	if p.WrapLongLines {
-		width, _ := p.screen.Size()
-		wrapped = wrapLine(width-numberPrefixLength, highlighted.StyledRunes)
+		wrapped = wrapLine(p.contentWidth()-numberPrefixLength, highlighted.StyledRunes)
 	} else {
 		// All on one line
 		wrapped = [][]twin.StyledRune{highlighted.StyledRunes}
@@ -202,6 +293,9 @@ func (p *Pager) renderLine(line *reader.NumberedLine, numberPrefixLength int) []
 		}
 
 		decorated := p.decorateLine(visibleLineNumber, numberPrefixLength, inputLinePart)
+		if wrapIndex == 0 {
+			p.decorateMarkedLine(line.Index, numberPrefixLength, decorated)
+		}
 
 		rendered = append(rendered, renderedLine{
 			inputLineIndex: line.Index,
@@ -221,10 +315,62 @@ func (p *Pager) renderLine(line *reader.NumberedLine, numberPrefixLength int) []
 
 // Take a rendered line and decorate as needed:
 //   - Line number, or leading whitespace for wrapped lines
+//   - Sticky header columns
 //   - Scroll left indicator
 //   - Scroll right indicator
 func (p *Pager) decorateLine(lineNumberToShow *linemetadata.Number, numberPrefixLength int, contents []twin.StyledRune) []twin.StyledRune {
-	width, _ := p.screen.Size()
+	if p.HeaderColumns > 0 && p.leftColumnZeroBased > 0 {
+		return p.decorateLineWithHeaderColumns(lineNumberToShow, numberPrefixLength, contents)
+	}
+
+	return p.decorateLineWithWidth(lineNumberToShow, numberPrefixLength, contents, p.contentWidth())
+}
+
+// When scrolled sideways, keep the header columns in place and scroll only what
+// comes after them
+func (p *Pager) decorateLineWithHeaderColumns(lineNumberToShow *linemetadata.Number, numberPrefixLength int, contents []twin.StyledRune) []twin.StyledRune {
+	headerColumns, rest := splitHeaderColumns(contents, p.HeaderColumns)
+
+	newLine := createLinePrefix(lineNumberToShow, numberPrefixLength)
+	newLine = append(newLine, headerColumns...)
+
+	// Decorate the rest as if it was a line of its own on a narrower screen
+	restWidth := p.contentWidth() - numberPrefixLength - p.HeaderColumns
+	if restWidth <= 0 {
+		return newLine
+	}
+	return append(newLine, p.decorateLineWithWidth(nil, 0, rest, restWidth)...)
+}
+
+// Split a line into its first headerWidth screen columns and the rest. The
+// header part is padded to exactly headerWidth columns, so that the rest lines
+// up between lines.
+func splitHeaderColumns(contents []twin.StyledRune, headerWidth int) (header []twin.StyledRune, rest []twin.StyledRune) {
+	header = make([]twin.StyledRune, 0, headerWidth)
+	column := 0
+	i := 0
+	for ; i < len(contents); i++ {
+		if column+contents[i].Width() > headerWidth {
+			break
+		}
+		header = append(header, contents[i])
+		column += contents[i].Width()
+	}
+
+	if i < len(contents) && column < headerWidth {
+		// A wide rune is crossing the header edge, skip it
+		i++
+	}
+
+	for column < headerWidth {
+		header = append(header, twin.NewStyledRune(' ', plainTextStyle))
+		column++
+	}
+
+	return header, contents[i:]
+}
+
+func (p *Pager) decorateLineWithWidth(lineNumberToShow *linemetadata.Number, numberPrefixLength int, contents []twin.StyledRune, width int) []twin.StyledRune {
 	newLine := make([]twin.StyledRune, 0, width)
 	newLine = append(newLine, createLinePrefix(lineNumberToShow, numberPrefixLength)...)
 
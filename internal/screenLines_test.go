@@ -84,7 +84,7 @@ func TestEmpty(t *testing.T) {
 
 		scrollPosition: newScrollPosition("TestEmpty"),
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -102,7 +102,7 @@ func TestSearchHighlight(t *testing.T) {
 		screen:        twin.NewFakeScreen(100, 10),
 		searchPattern: regexp.MustCompile("\""),
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -143,7 +143,7 @@ func TestOverflowDown(t *testing.T) {
 		// This value can be anything and should be clipped, that's what we're testing
 		scrollPosition: *scrollPositionFromIndex("TestOverflowDown", linemetadata.IndexFromOneBased(42)),
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -168,7 +168,7 @@ func TestOverflowUp(t *testing.T) {
 
 		// NOTE: scrollPosition intentionally not initialized
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -237,7 +237,7 @@ func TestOneLineTerminal(t *testing.T) {
 		reader:        reader.NewFromTextForTesting("test", "hej"),
 		ShowStatusBar: true,
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -259,7 +259,7 @@ func TestShortenedInput(t *testing.T) {
 
 		scrollPosition: newScrollPosition("TestShortenedInput"),
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -296,7 +296,7 @@ func TestShortenedInputManyLines(t *testing.T) {
 		reader:         reader.NewFromTextForTesting("test", strings.Join(lines, "\n")),
 		scrollPosition: newScrollPosition("TestShortenedInputManyLines"),
 	}
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
@@ -38,11 +38,12 @@ type scrollPositionInternal struct {
 
 // If any of these change, we have to recompute the scrollPositionInternal values
 type scrollPositionCanonical struct {
-	width           int  // From pager
+	width           int  // From pager.contentWidth()
 	height          int  // From pager
 	showLineNumbers bool // From pager
 	showStatusBar   bool // From pager
 	wrapLongLines   bool // From pager
+	hasMarks        bool // From pager, marks make the line numbers wider
 
 	pagerLineCount int // From pager.Reader().GetLineCount()
 
@@ -51,13 +52,14 @@ type scrollPositionCanonical struct {
 }
 
 func canonicalFromPager(pager *Pager) scrollPositionCanonical {
-	width, height := pager.screen.Size()
+	_, height := pager.screen.Size()
 	return scrollPositionCanonical{
-		width:           width,
+		width:           pager.contentWidth(),
 		height:          height,
 		showLineNumbers: pager.ShowLineNumbers,
 		showStatusBar:   pager.ShowStatusBar,
 		wrapLongLines:   pager.WrapLongLines,
+		hasMarks:        len(pager.marks) > 0,
 
 		pagerLineCount: pager.Reader().GetLineCount(),
 
@@ -103,7 +105,8 @@ func NewScrollPositionFromIndex(index linemetadata.Index, name string) scrollPos
 
 // Move towards the top until deltaScreenLines is not negative any more
 func (si *scrollPositionInternal) handleNegativeDeltaScreenLines(pager *Pager) {
-	for !si.lineIndex.IsZero() && si.deltaScreenLines < 0 {
+	top := pager.firstScrollableIndex()
+	for si.lineIndex.IsAfter(top) && si.deltaScreenLines < 0 {
 		// Render the previous line
 		previousLineIndex := si.lineIndex.NonWrappingAdd(-1)
 		previousLine := pager.Reader().GetLine(previousLineIndex)
@@ -119,7 +122,7 @@ func (si *scrollPositionInternal) handleNegativeDeltaScreenLines(pager *Pager) {
 		si.deltaScreenLines += previousSubLinesCount
 	}
 
-	if si.lineIndex.IsZero() && si.deltaScreenLines <= 0 {
+	if !si.lineIndex.IsAfter(top) && si.deltaScreenLines <= 0 {
 		// Can't go any higher
 		si.deltaScreenLines = 0
 		return
@@ -286,9 +289,16 @@ func (si *scrollPositionInternal) canonicalize(pager *Pager) {
 		return
 	}
 
+	top := pager.firstScrollableIndex()
 	if si.lineIndex == nil {
 		// We have lines, but no line number, start at the top
-		si.lineIndex = &linemetadata.Index{}
+		si.lineIndex = &top
+	}
+
+	if si.lineIndex.IsBefore(top) {
+		// Header lines are always visible, no need to scroll to them
+		si.lineIndex = &top
+		si.deltaScreenLines = 0
 	}
 
 	si.handleNegativeDeltaScreenLines(pager)
@@ -18,7 +18,7 @@ func testCanonicalize1000(t *testing.T, withStatusBar bool, currentStartLine lin
 	pager := Pager{}
 	pager.screen = twin.NewFakeScreen(100, screenHeight)
 	pager.reader = reader.NewFromTextForTesting("test", strings.Repeat("a\n", 2000))
-	pager.filteringReader = FilteringReader{
+	pager.filteringReader = &FilteringReader{
 		BackingReader: pager.reader,
 		FilterPattern: &pager.filterPattern,
 	}
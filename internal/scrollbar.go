@@ -0,0 +1,358 @@
+package internal
+
+import (
+	"regexp"
+	"runtime/debug"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+)
+
+var scrollbarTrack = twin.NewStyledRune('│', twin.StyleDefault.WithAttr(twin.AttrDim))
+var scrollbarThumb = twin.NewStyledRune(' ', twin.StyleDefault.WithAttr(twin.AttrReverse))
+
+// Drawn on the scrollbar rows containing search hits. Filtering also sets the
+// search pattern, so while filtering this shows the filter matches.
+var scrollbarSearchHitStyle = twin.StyleDefault.WithAttr(twin.AttrBold)
+
+const scrollbarSearchHitRune = '•'
+
+// With more lines than this, search hit ticks are computed in the background
+const scrollbarTicksSyncLineCount = 10_000
+
+// While streaming, recompute the background computed ticks at most this often
+const scrollbarTicksInterval = 500 * time.Millisecond
+
+// What a set of scrollbar ticks was computed for. If any of this changes, the
+// ticks must be recomputed.
+type scrollbarTicksKey struct {
+	reader        *reader.ReaderImpl
+	searchPattern string
+	filterPattern string
+	onlyCommands  bool
+	onlyChanges   bool
+	lineCount     int
+	height        int
+}
+
+type scrollbarTicks struct {
+	key scrollbarTicksKey
+
+	// One entry per scrollbar row, true if that row has a search hit
+	searchHitRows []bool
+
+	// Number of lines with search hits, for the status bar
+	hitCount int
+
+	// When computing these ticks was done
+	computedAt time.Time
+}
+
+// Background computed scrollbar ticks are ready
+type eventScrollbarTicks struct {
+	ticks scrollbarTicks
+}
+
+// The scrollbar goes into this screen column
+func (p *Pager) scrollbarColumn() int {
+	width, _ := p.screen.Size()
+	return width - 1
+}
+
+// Where on screen the scrollbar thumb goes, as a first row and a row count.
+// Both are zero if there is nothing to scroll through.
+func (p *Pager) scrollbarThumb() (firstRow int, rowCount int) {
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 {
+		return 0, 0
+	}
+
+	renderedLines, _ := p.renderLines()
+	if len(renderedLines) == 0 {
+		return 0, 0
+	}
+	firstShown := renderedLines[0].inputLineIndex.Index()
+	lastShown := renderedLines[len(renderedLines)-1].inputLineIndex.Index()
+
+	height := p.visibleHeight()
+	rowCount = height * (lastShown - firstShown + 1) / lineCount
+	if rowCount < 1 {
+		rowCount = 1
+	}
+	if rowCount > height {
+		rowCount = height
+	}
+
+	firstRow = height * firstShown / lineCount
+	if firstRow+rowCount > height || lastShown == lineCount-1 {
+		// Always put the thumb at the bottom when the last line is visible
+		firstRow = height - rowCount
+	}
+
+	return firstRow, rowCount
+}
+
+func (p *Pager) drawScrollbar() {
+	column := p.scrollbarColumn()
+	thumbRow, thumbHeight := p.scrollbarThumb()
+	searchHitRows := p.scrollbarSearchHitRows()
+	markRows := p.scrollbarMarkRows()
+
+	// The scrollbar is next to the scrolling lines only, not the header lines
+	firstRow := p.headerScreenLineCount()
+
+	for row := 0; row < p.visibleHeight(); row++ {
+		cell := scrollbarTrack
+		if mark, found := markRows[row]; found {
+			cell = twin.NewStyledRune(mark, scrollbarSearchHitStyle)
+		} else if row < len(searchHitRows) && searchHitRows[row] {
+			cell = twin.NewStyledRune(scrollbarSearchHitRune, scrollbarSearchHitStyle)
+		}
+
+		if row >= thumbRow && row < thumbRow+thumbHeight {
+			if cell == scrollbarTrack {
+				cell = scrollbarThumb
+			} else {
+				cell.Style = cell.Style.WithAttr(twin.AttrReverse)
+			}
+		}
+
+		p.screen.SetCell(column, firstRow+row, cell)
+	}
+}
+
+// Which scrollbar row a line goes on
+func scrollbarRow(lineIndex int, lineCount int, height int) int {
+	row := lineIndex * height / lineCount
+	if row >= height {
+		row = height - 1
+	}
+	return row
+}
+
+// Map from scrollbar rows to the mark characters on those rows. If multiple
+// marks end up on the same row, the lowest one is shown, like in
+// markOnLine().
+func (p *Pager) scrollbarMarkRows() map[int]rune {
+	result := make(map[int]rune)
+	if p.isShowingHelp {
+		return result
+	}
+
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 {
+		return result
+	}
+
+	for mark, position := range p.marks {
+		lineIndex := position.lineIndex(p)
+		if lineIndex == nil {
+			continue
+		}
+		row := scrollbarRow(lineIndex.Index(), lineCount, p.visibleHeight())
+		if shown, found := result[row]; found && shown < mark {
+			continue
+		}
+		result[row] = mark
+	}
+
+	return result
+}
+
+func (p *Pager) currentScrollbarTicksKey() scrollbarTicksKey {
+	key := scrollbarTicksKey{
+		reader:    p.reader,
+		lineCount: p.Reader().GetLineCount(),
+		height:    p.visibleHeight(),
+	}
+	if p.isShowingHelp {
+		key.reader = p.helpReader
+	}
+	if p.searchPattern != nil {
+		key.searchPattern = p.searchPattern.String()
+	}
+	if p.filterPattern != nil {
+		key.filterPattern = p.filterPattern.String()
+	}
+	key.onlyCommands = p.onlyCommands
+	key.onlyChanges = p.onlyChanges
+	return key
+}
+
+// Returns nil if there is no search, or if the search hits are still being
+// computed
+func (p *Pager) scrollbarSearchHitRows() []bool {
+	ticks := p.searchHitTicks()
+	if ticks == nil {
+		return nil
+	}
+	return ticks.searchHitRows
+}
+
+// Number of lines matching the search. Returns nil if there is no search, or if
+// the search hits are still being computed.
+func (p *Pager) searchHitCount() *int {
+	ticks := p.searchHitTicks()
+	if ticks == nil {
+		return nil
+	}
+	return &ticks.hitCount
+}
+
+// Returns nil if there is no search, or if the search hits are still being
+// computed
+func (p *Pager) searchHitTicks() *scrollbarTicks {
+	if p.searchPattern == nil || p.searchPattern.String() == "" {
+		return nil
+	}
+
+	key := p.currentScrollbarTicksKey()
+	if key.lineCount == 0 {
+		return nil
+	}
+	if p.scrollbarTicks != nil && p.scrollbarTicks.key == key {
+		return p.scrollbarTicks
+	}
+
+	pattern := p.searchPattern
+
+	if key.lineCount <= scrollbarTicksSyncLineCount {
+		lines := p.Reader().GetLines(linemetadata.Index{}, key.lineCount).Lines
+		searchHitRows, hitCount := computeSearchHitRows(lines, pattern, key.lineCount, key.height)
+		p.scrollbarTicks = &scrollbarTicks{
+			key:           key,
+			searchHitRows: searchHitRows,
+			hitCount:      hitCount,
+			computedAt:    time.Now(),
+		}
+		return p.scrollbarTicks
+	}
+
+	stale := p.staleSearchHitRows(key)
+	if p.scrollbarTicksPending != nil {
+		// Only one computation at a time. When this one is done we'll get
+		// redrawn, and then the next one can start.
+		return stale
+	}
+	p.scrollbarTicksPending = &key
+
+	// While streaming, don't recompute more often than every
+	// scrollbarTicksInterval
+	var delay time.Duration
+	if stale != nil {
+		delay = scrollbarTicksInterval - time.Since(stale.computedAt)
+	}
+
+	lineSource := p.snapshotReader()
+	events := p.screen.Events()
+	go func() {
+		defer func() {
+			PanicHandler("scrollbarSearchHitRows()", recover(), debug.Stack())
+		}()
+
+		if delay > 0 {
+			time.Sleep(delay)
+		}
+
+		t0 := time.Now()
+		lines := lineSource.GetLines(linemetadata.Index{}, key.lineCount).Lines
+		searchHitRows, hitCount := computeSearchHitRows(lines, pattern, key.lineCount, key.height)
+		log.Debugf("Computed scrollbar search hits for %d lines in %s", len(lines), time.Since(t0))
+
+		events <- eventScrollbarTicks{ticks: scrollbarTicks{
+			key:           key,
+			searchHitRows: searchHitRows,
+			hitCount:      hitCount,
+			computedAt:    time.Now(),
+		}}
+	}()
+
+	return stale
+}
+
+// A reader showing the same lines as p.Reader() does now, even if the filter
+// changes later on. Safe to use from other goroutines.
+func (p *Pager) snapshotReader() reader.Reader {
+	if p.isShowingHelp {
+		return p.helpReader
+	}
+
+	return p.filteredSnapshot(p.reader)
+}
+
+// While new ticks are being computed, keep showing the old ones as long as
+// they are for the same search. This prevents flicker while streaming input.
+func (p *Pager) staleSearchHitRows(key scrollbarTicksKey) *scrollbarTicks {
+	if p.scrollbarTicks == nil {
+		return nil
+	}
+
+	old := p.scrollbarTicks.key
+	if old.reader != key.reader || old.searchPattern != key.searchPattern || old.filterPattern != key.filterPattern || old.onlyCommands != key.onlyCommands || old.onlyChanges != key.onlyChanges || old.height != key.height {
+		return nil
+	}
+
+	return p.scrollbarTicks
+}
+
+func (p *Pager) onScrollbarTicks(ticks scrollbarTicks) {
+	other := p.otherPane
+	if other != nil && other.scrollbarTicksPending != nil && *other.scrollbarTicksPending == ticks.key {
+		// Computed for the pane that doesn't have focus
+		other.scrollbarTicks = &ticks
+		other.scrollbarTicksPending = nil
+		return
+	}
+
+	if p.scrollbarTicksPending != nil && *p.scrollbarTicksPending == ticks.key {
+		p.scrollbarTicks = &ticks
+		p.scrollbarTicksPending = nil
+	}
+}
+
+// Returns one entry per scrollbar row, true for rows containing at least one
+// line matching the pattern. Also returns the number of matching lines.
+func computeSearchHitRows(lines []*reader.NumberedLine, pattern *regexp.Regexp, lineCount int, height int) ([]bool, int) {
+	rows := make([]bool, height)
+	if lineCount == 0 {
+		return rows, 0
+	}
+
+	hitCount := 0
+	for i, line := range lines {
+		if !pattern.MatchString(line.Plain()) {
+			continue
+		}
+
+		hitCount++
+		rows[scrollbarRow(i, lineCount, height)] = true
+	}
+
+	return rows, hitCount
+}
+
+// Scroll so that the scrollbar thumb starts at the given scrollbar row. Row
+// zero is just below the header lines, if any.
+func (p *Pager) scrollToScrollbarRow(row int) {
+	lineCount := p.Reader().GetLineCount()
+	if lineCount == 0 {
+		return
+	}
+
+	height := p.visibleHeight()
+	if row < 0 {
+		row = 0
+	}
+	if row >= height {
+		row = height - 1
+	}
+
+	index := linemetadata.IndexFromZeroBased(row * lineCount / height)
+	p.scrollPosition = NewScrollPositionFromIndex(index, "Scrollbar")
+
+	// Start following if the user dragged all the way to the end
+	p.handleScrolledDown()
+}
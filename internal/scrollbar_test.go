@@ -0,0 +1,127 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestComputeSearchHitRows(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+	lines := pager.Reader().GetLines(linemetadata.Index{}, 100).Lines
+
+	// "line c" is on lines 2, 28, 54 and 80
+	rows, hitCount := computeSearchHitRows(lines, toPattern("line c"), 100, 10)
+	assert.DeepEqual(t, rows, []bool{true, false, true, false, false, true, false, false, true, false})
+	assert.Equal(t, hitCount, 4)
+}
+
+func TestScrollbarTicks(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+	pager.searchPattern = toPattern("line c")
+	pager.marks = map[rune]scrollPosition{
+		'x': NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(95), "x"),
+	}
+
+	pager.drawScrollbar()
+
+	column := pager.scrollbarColumn()
+	scrollbarRunes := ""
+	for row := 0; row < pager.visibleHeight(); row++ {
+		scrollbarRunes += string(pager.screen.(*twin.FakeScreen).GetRow(row)[column].Rune)
+	}
+
+	// The first row has both the thumb and a search hit
+	assert.Equal(t, scrollbarRunes, "•│•││•││•x")
+	assert.Equal(t,
+		pager.screen.(*twin.FakeScreen).GetRow(0)[column].Style,
+		scrollbarSearchHitStyle.WithAttr(twin.AttrReverse))
+}
+
+func TestScrollbarMarksOnTheSameRow(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+	pager.marks = map[rune]scrollPosition{
+		'c': NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(95), "c"),
+		'a': NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(96), "a"),
+		'b': NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(97), "b"),
+	}
+
+	// Same as in the line number gutter, no matter the map order
+	for range 10 {
+		assert.DeepEqual(t, pager.scrollbarMarkRows(), map[int]rune{9: 'a'})
+	}
+}
+
+func TestScrollbarTicksAreCached(t *testing.T) {
+	pager := newScrollbarTestPager(t)
+	pager.searchPattern = toPattern("line c")
+
+	rows := pager.scrollbarSearchHitRows()
+	assert.Assert(t, pager.scrollbarTicks != nil)
+	assert.Equal(t, &rows[0], &pager.scrollbarSearchHitRows()[0])
+
+	// A new search should invalidate the cache
+	// "line z" is on lines 25, 51 and 77
+	pager.searchPattern = toPattern("line z")
+	rows = pager.scrollbarSearchHitRows()
+	assert.Equal(t, pager.scrollbarTicks.key.searchPattern, pager.searchPattern.String())
+	assert.Assert(t, !rows[0])
+	assert.Assert(t, rows[2])
+}
+
+// A fake screen with an events channel, for code that reports back to the main
+// loop from other goroutines
+type eventsFakeScreen struct {
+	*twin.FakeScreen
+	events chan twin.Event
+}
+
+func (screen eventsFakeScreen) Events() chan twin.Event {
+	return screen.events
+}
+
+func TestScrollbarTicksInTheBackground(t *testing.T) {
+	lines := make([]string, 0, scrollbarTicksSyncLineCount+1)
+	for i := range scrollbarTicksSyncLineCount + 1 {
+		lines = append(lines, "line "+string(rune('a'+i%26)))
+	}
+	pager, fakeScreen := newTestPager(t, reader.NewFromTextForTesting("TestScrollbar", strings.Join(lines, "\n")), 20, 11)
+	screen := eventsFakeScreen{FakeScreen: fakeScreen, events: make(chan twin.Event, 10)}
+	pager.screen = screen
+
+	pager.searchPattern = toPattern("line c")
+	assert.Assert(t, pager.scrollbarSearchHitRows() == nil)
+	pending := *pager.scrollbarTicksPending
+
+	// Changing the search must not start another computation until the first
+	// one is done
+	pager.searchPattern = toPattern("line d")
+	assert.Assert(t, pager.scrollbarSearchHitRows() == nil)
+	assert.Equal(t, *pager.scrollbarTicksPending, pending)
+
+	receive := func() eventScrollbarTicks {
+		select {
+		case event := <-screen.events:
+			return event.(eventScrollbarTicks)
+		case <-time.After(10 * time.Second):
+			t.Fatal("Timed out waiting for scrollbar ticks")
+			return eventScrollbarTicks{}
+		}
+	}
+
+	event := receive()
+	assert.Equal(t, event.ticks.key, pending)
+	pager.onScrollbarTicks(event.ticks)
+	assert.Assert(t, pager.scrollbarTicksPending == nil)
+
+	// Now the computation for the new search can start
+	assert.Assert(t, pager.scrollbarSearchHitRows() == nil)
+	pager.onScrollbarTicks(receive().ticks)
+	// "line d" is on every 26th line, starting with line 3
+	assert.Equal(t, *pager.searchHitCount(), (scrollbarTicksSyncLineCount-3)/26+1)
+}
@@ -41,12 +41,11 @@ func (p *Pager) scrollToSearchHits() {
 		return
 	}
 
-	if firstHitPosition.isVisible(p) {
-		// Already on-screen, never mind
-		return
+	if !firstHitPosition.isVisible(p) {
+		p.scrollPosition = *firstHitPosition
 	}
 
-	p.scrollPosition = *firstHitPosition
+	p.selectSearchHitOnLine(*firstHitPosition.internalDontTouch.lineIndex, false)
 }
 
 // Scroll backwards to the previous search hit, while the user is typing the
@@ -92,13 +91,12 @@ func (p *Pager) scrollToSearchHitsBackwards() {
 		return
 	}
 
-	if firstHitPosition.isVisible(p) {
-		// Already on-screen, never mind
-		return
+	if !firstHitPosition.isVisible(p) {
+		// Scroll so that the first hit is at the bottom of the screen
+		p.scrollPosition = firstHitPosition.PreviousLine(p.visibleHeight() - 1)
 	}
 
-	// Scroll so that the first hit is at the bottom of the screen
-	p.scrollPosition = firstHitPosition.PreviousLine(p.visibleHeight() - 1)
+	p.selectSearchHitOnLine(*firstHitPosition.internalDontTouch.lineIndex, true)
 }
 
 // NOTE: When we search, we do that by looping over the *input lines*, not the
@@ -260,6 +258,16 @@ func (p *Pager) isNotFound() bool {
 }
 
 func (p *Pager) scrollToNextSearchHit() {
+	p.scrollToNthNextSearchHit(1)
+}
+
+func (p *Pager) scrollToPreviousSearchHit() {
+	p.scrollToNthPreviousSearchHit(1)
+}
+
+// Go count lines with search hits down. With a count, all hits on one line
+// count as one.
+func (p *Pager) scrollToNthNextSearchHit(count int) {
 	if p.searchPattern == nil {
 		// Nothing to search for, never mind
 		return
@@ -270,6 +278,10 @@ func (p *Pager) scrollToNextSearchHit() {
 		return
 	}
 
+	if count == 1 && p.isViewing() && p.stepSearchHitWithinLine(false) {
+		return
+	}
+
 	if p.isViewing() && p.isScrolledToEnd() {
 		p.mode = PagerModeNotFound{pager: p}
 		return
@@ -297,13 +309,18 @@ func (p *Pager) scrollToNextSearchHit() {
 		p.mode = PagerModeNotFound{pager: p}
 		return
 	}
-	p.scrollPosition = *firstHitPosition
+	hitPosition := p.skipSearchHits(*firstHitPosition, count-1, false)
+	p.rememberJump()
+	p.scrollPosition = hitPosition
+	p.selectSearchHitOnLine(*hitPosition.internalDontTouch.lineIndex, false)
 
 	// Don't let any search hit scroll out of sight
 	p.setTargetLine(nil)
 }
 
-func (p *Pager) scrollToPreviousSearchHit() {
+// Go count lines with search hits up. With a count, all hits on one line count
+// as one.
+func (p *Pager) scrollToNthPreviousSearchHit(count int) {
 	if p.searchPattern == nil {
 		// Nothing to search for, never mind
 		return
@@ -314,6 +331,10 @@ func (p *Pager) scrollToPreviousSearchHit() {
 		return
 	}
 
+	if count == 1 && p.isViewing() && p.stepSearchHitWithinLine(true) {
+		return
+	}
+
 	var firstSearchPosition linemetadata.Index
 
 	switch {
@@ -336,8 +357,41 @@ func (p *Pager) scrollToPreviousSearchHit() {
 		p.mode = PagerModeNotFound{pager: p}
 		return
 	}
-	p.scrollPosition = *firstHitPosition
+	hitPosition := p.skipSearchHits(*firstHitPosition, count-1, true)
+	p.rememberJump()
+	p.scrollPosition = hitPosition
+	p.selectSearchHitOnLine(*hitPosition.internalDontTouch.lineIndex, true)
 
 	// Don't let any search hit scroll out of sight
 	p.setTargetLine(nil)
 }
+
+// Starting at a hit, go this many more lines with hits onwards, or as far as
+// there are hits. Doesn't wrap around, so the whole count is one pass.
+func (p *Pager) skipSearchHits(hit scrollPosition, count int, backwards bool) scrollPosition {
+	lineCount := p.Reader().GetLineCount()
+	for range count {
+		hitLine := *hit.internalDontTouch.lineIndex
+
+		var next *scrollPosition
+		if backwards {
+			if hitLine.IsZero() {
+				break
+			}
+			next = p.findFirstHit(hitLine.NonWrappingAdd(-1), nil, true)
+		} else {
+			searchStart := hitLine.NonWrappingAdd(1)
+			if !searchStart.IsWithinLength(lineCount) {
+				break
+			}
+			next = p.findFirstHit(searchStart, nil, false)
+		}
+
+		if next == nil {
+			break
+		}
+		hit = *next
+	}
+
+	return hit
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"regexp/syntax"
+	"sort"
+	"sync"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// For each folded rune, all the runes that fold into it. Example: 'e' maps to
+// 'é', 'è', 'ê', 'ｅ' and many more.
+//
+// Built on first use, see foldVariants().
+var foldVariantsTable map[rune][]rune
+var foldVariantsOnce sync.Once
+
+// Matches combining diacritical marks following a base character, as in
+// decomposed (NFD) text
+var combiningMarks = &syntax.Regexp{
+	Op:  syntax.OpStar,
+	Sub: []*syntax.Regexp{{Op: syntax.OpCharClass, Rune: []rune{0x300, 0x36f}}},
+}
+
+// Strip diacritics and compatibility differences like full width forms from a
+// rune. Returns the rune itself if it doesn't fold into exactly one other rune.
+func foldRune(char rune) rune {
+	var folded rune
+	for _, decomposed := range norm.NFKD.String(string(char)) {
+		if unicode.Is(unicode.Mn, decomposed) {
+			continue
+		}
+
+		if folded != 0 {
+			// Decomposes into multiple base runes, like 'ﬁ' into "fi". We can't
+			// handle those.
+			return char
+		}
+		folded = decomposed
+	}
+
+	if folded == 0 {
+		return char
+	}
+	return folded
+}
+
+func foldVariants() map[rune][]rune {
+	foldVariantsOnce.Do(func() {
+		foldVariantsTable = make(map[rune][]rune)
+
+		// Everything up to and including the Supplementary Multilingual Plane,
+		// which contains the mathematical alphanumeric symbols
+		for char := rune(0); char <= 0x1ffff; char++ {
+			if char >= 0xd800 && char <= 0xdfff {
+				// Surrogates
+				continue
+			}
+
+			folded := foldRune(char)
+			if folded == char {
+				continue
+			}
+
+			foldVariantsTable[folded] = append(foldVariantsTable[folded], char)
+		}
+	})
+
+	return foldVariantsTable
+}
+
+// Rewrite a regexp so that it also matches accented and width variants of the
+// characters it would otherwise match.
+//
+// Rather than folding the searched text, we make the pattern match the text as
+// it is. This way match positions are always in terms of the original text,
+// which is what we need for highlighting.
+func foldPattern(expression string) (string, error) {
+	parsed, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+
+	return foldRegexp(parsed).String(), nil
+}
+
+func foldRegexp(re *syntax.Regexp) *syntax.Regexp {
+	switch re.Op {
+	case syntax.OpLiteral:
+		caseInsensitive := re.Flags&syntax.FoldCase != 0
+
+		concat := &syntax.Regexp{Op: syntax.OpConcat, Flags: re.Flags}
+		for _, char := range re.Rune {
+			if unicode.Is(unicode.Mn, char) {
+				// Combining marks are matched by combiningMarks below
+				continue
+			}
+
+			concat.Sub = append(concat.Sub,
+				&syntax.Regexp{
+					Op:    syntax.OpCharClass,
+					Flags: re.Flags,
+					Rune:  runesToRanges(foldedClass([]rune{foldRune(char)}, caseInsensitive)),
+				},
+				combiningMarks,
+			)
+		}
+		if len(concat.Sub) == 0 {
+			return &syntax.Regexp{Op: syntax.OpEmptyMatch}
+		}
+		return concat
+
+	case syntax.OpCharClass:
+		return &syntax.Regexp{
+			Op:    syntax.OpCharClass,
+			Flags: re.Flags,
+			Rune:  foldRanges(re.Rune),
+		}
+	}
+
+	for i, sub := range re.Sub {
+		re.Sub[i] = foldRegexp(sub)
+	}
+	return re
+}
+
+// Add all variants of the given runes, plus their upper / lower case versions
+// if caseInsensitive is set
+func foldedClass(runes []rune, caseInsensitive bool) []rune {
+	variants := foldVariants()
+
+	result := make([]rune, 0, len(runes))
+	for _, char := range runes {
+		result = append(result, char)
+		result = append(result, variants[char]...)
+	}
+
+	if caseInsensitive {
+		for _, char := range result {
+			for other := unicode.SimpleFold(char); other != char; other = unicode.SimpleFold(other) {
+				result = append(result, other)
+			}
+		}
+	}
+
+	return result
+}
+
+// Add the variants of all runes in the ranges to the ranges
+func foldRanges(ranges []rune) []rune {
+	inRanges := func(char rune) bool {
+		for i := 0; i+1 < len(ranges); i += 2 {
+			if char >= ranges[i] && char <= ranges[i+1] {
+				return true
+			}
+		}
+		return false
+	}
+
+	extra := make([]rune, 0)
+	for folded, variants := range foldVariants() {
+		if inRanges(folded) {
+			extra = append(extra, variants...)
+		}
+	}
+
+	if len(extra) == 0 {
+		return ranges
+	}
+
+	result := append([]rune{}, ranges...)
+	for _, char := range extra {
+		result = append(result, char, char)
+	}
+	return normalizeRanges(result)
+}
+
+func runesToRanges(runes []rune) []rune {
+	ranges := make([]rune, 0, 2*len(runes))
+	for _, char := range runes {
+		ranges = append(ranges, char, char)
+	}
+	return normalizeRanges(ranges)
+}
+
+// Sort and merge ranges the way syntax.OpCharClass expects them to be
+func normalizeRanges(ranges []rune) []rune {
+	pairs := make([][2]rune, 0, len(ranges)/2)
+	for i := 0; i+1 < len(ranges); i += 2 {
+		pairs = append(pairs, [2]rune{ranges[i], ranges[i+1]})
+	}
+	sort.Slice(pairs, func(i, j int) bool {
+		return pairs[i][0] < pairs[j][0]
+	})
+
+	result := make([]rune, 0, len(ranges))
+	for _, pair := range pairs {
+		last := len(result) - 1
+		if last > 0 && pair[0] <= result[last]+1 {
+			if pair[1] > result[last] {
+				result[last] = pair[1]
+			}
+			continue
+		}
+		result = append(result, pair[0], pair[1])
+	}
+	return result
+}
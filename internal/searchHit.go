@@ -0,0 +1,198 @@
+package internal
+
+import (
+	"github.com/walles/moor/internal/linemetadata"
+)
+
+// One search match on a particular input line
+type searchHit struct {
+	lineIndex linemetadata.Index
+
+	// Rune indices into the plain text of the line, end exclusive
+	runeRange [2]int
+}
+
+// Make the first (or last if backwards) search hit on the given line current,
+// and scroll sideways to make it visible.
+//
+// Does nothing when wrapping long lines, all hits are visible then anyway.
+func (p *Pager) selectSearchHitOnLine(lineIndex linemetadata.Index, backwards bool) {
+	p.currentSearchHit = nil
+	if p.WrapLongLines {
+		return
+	}
+
+	line := p.Reader().GetLine(lineIndex)
+	if line == nil {
+		return
+	}
+
+	ranges := line.MatchRanges(p.searchPattern)
+	if len(ranges) == 0 {
+		return
+	}
+
+	runeRange := ranges[0]
+	if backwards {
+		runeRange = ranges[len(ranges)-1]
+	}
+
+	p.currentSearchHit = &searchHit{lineIndex: lineIndex, runeRange: runeRange}
+	p.scrollHorizontallyToSearchHit()
+}
+
+// If the current search hit is on screen, step to the next (or previous if
+// backwards) hit on the same line.
+//
+// Returns false if there is no such hit, or if we're wrapping long lines.
+func (p *Pager) stepSearchHitWithinLine(backwards bool) bool {
+	hit := p.currentSearchHit
+	if p.WrapLongLines || hit == nil {
+		return false
+	}
+
+	if !p.isLineVisible(hit.lineIndex) {
+		// The user has scrolled away from the current hit
+		return false
+	}
+
+	line := p.Reader().GetLine(hit.lineIndex)
+	if line == nil {
+		return false
+	}
+
+	ranges := line.MatchRanges(p.searchPattern)
+	if backwards {
+		for i := len(ranges) - 1; i >= 0; i-- {
+			if ranges[i][0] < hit.runeRange[0] {
+				hit.runeRange = ranges[i]
+				p.scrollHorizontallyToSearchHit()
+				return true
+			}
+		}
+		return false
+	}
+
+	for _, runeRange := range ranges {
+		if runeRange[0] > hit.runeRange[0] {
+			hit.runeRange = runeRange
+			p.scrollHorizontallyToSearchHit()
+			return true
+		}
+	}
+	return false
+}
+
+// Scroll sideways as little as possible to make the current search hit
+// visible
+func (p *Pager) scrollHorizontallyToSearchHit() {
+	hit := p.currentSearchHit
+	if hit == nil {
+		return
+	}
+
+	p.scrollHorizontallyToRunes(hit.lineIndex, hit.runeRange)
+}
+
+// Scroll sideways so that the given rune range of a line becomes visible. Rune
+// indices are into the plain text of the line, end exclusive.
+//
+// Does nothing when wrapping long lines, everything is visible then anyway.
+func (p *Pager) scrollHorizontallyToRunes(lineIndex linemetadata.Index, runeRange [2]int) {
+	if p.WrapLongLines {
+		return
+	}
+
+	line := p.Reader().GetLine(lineIndex)
+	if line == nil {
+		return
+	}
+
+	// Find the screen columns of the range, relative to the start of the line
+	// contents
+	startColumn := 0
+	endColumn := 0
+	for i, cell := range line.HighlightedTokens(plainTextStyle, nil, nil).StyledRunes {
+		if i >= runeRange[1] {
+			break
+		}
+		if i < runeRange[0] {
+			startColumn += cell.Width()
+		}
+		endColumn += cell.Width()
+	}
+
+	if p.HeaderColumns > 0 {
+		if endColumn <= p.HeaderColumns {
+			// Header columns are always visible
+			return
+		}
+
+		// Everything after the header columns scrolls as if it was a line of
+		// its own, see decorateLineWithHeaderColumns()
+		startColumn = max(startColumn-p.HeaderColumns, 0)
+		endColumn -= p.HeaderColumns
+	}
+
+	numberPrefixLength := 0
+	lastVisiblePosition := p.getLastVisiblePosition()
+	if lastVisiblePosition != nil {
+		lastVisibleLine := p.Reader().GetLine(*lastVisiblePosition.lineIndex(p))
+		if lastVisibleLine != nil {
+			numberPrefixLength = p.getLineNumberPrefixLength(lastVisibleLine.Number)
+		}
+	}
+
+	// The line contents start after the line number prefix, and the rightmost
+	// column may be taken by the scroll right hint
+	contentsWidth := p.contentWidth() - numberPrefixLength - p.HeaderColumns - 1
+
+	// leftColumnZeroBased normally counts the line number prefix, but not
+	// when scrolling what comes after the header columns
+	leftColumnOffset := numberPrefixLength
+	if p.HeaderColumns > 0 {
+		leftColumnOffset = 0
+	}
+
+	firstContentsColumn := p.leftColumnZeroBased - leftColumnOffset
+	if firstContentsColumn < 0 {
+		firstContentsColumn = 0
+	}
+
+	firstFullyVisible := firstContentsColumn
+	if p.leftColumnZeroBased > 0 {
+		// Make room for the scroll left hint
+		firstFullyVisible++
+	}
+	lastVisibleEnd := firstContentsColumn + contentsWidth
+
+	if startColumn >= firstFullyVisible && endColumn <= lastVisibleEnd {
+		// Already visible, never mind
+		return
+	}
+
+	// Hit is off to the left, or too wide to fit. Put its start just to the
+	// right of the scroll left hint.
+	newFirstContentsColumn := startColumn - 1
+	if startColumn >= firstFullyVisible && endColumn-contentsWidth < startColumn {
+		// Hit is off to the right, move just far enough to show its end
+		newFirstContentsColumn = endColumn - contentsWidth
+	}
+
+	if newFirstContentsColumn <= 0 {
+		p.leftColumnZeroBased = 0
+		return
+	}
+	p.leftColumnZeroBased = newFirstContentsColumn + leftColumnOffset
+}
+
+// Is any part of the given input line on screen?
+func (p *Pager) isLineVisible(lineIndex linemetadata.Index) bool {
+	renderedLines, _ := p.renderLines()
+	for _, renderedLine := range renderedLines {
+		if renderedLine.inputLineIndex == lineIndex {
+			return true
+		}
+	}
+	return false
+}
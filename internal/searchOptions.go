@@ -0,0 +1,272 @@
+package internal
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"strings"
+	"unicode"
+
+	"github.com/walles/moor/twin"
+)
+
+// How a search string should be interpreted
+type RegexpMode int
+
+const (
+	// Regexp if the search string is a valid one, literal otherwise
+	RegexpModeAuto RegexpMode = iota
+
+	// Always a regexp. Invalid regexps won't match anything.
+	RegexpModeOn
+
+	// Always a literal string
+	RegexpModeOff
+)
+
+type CaseMode int
+
+const (
+	// Case sensitive if the search string contains any upper case characters
+	CaseModeSmart CaseMode = iota
+
+	CaseModeSensitive
+	CaseModeInsensitive
+)
+
+// SearchOptions control how search and filter strings are turned into
+// patterns. The zero value gives you auto regexp and smart case.
+type SearchOptions struct {
+	Regexp    RegexpMode `json:"regexp"`
+	Case      CaseMode   `json:"case"`
+	WholeWord bool       `json:"wholeWord,omitempty"`
+
+	// Ignore diacritics and character width differences, so that "resume"
+	// matches "résumé" and "ＡＢＣ" matches "ABC"
+	Fold bool `json:"fold,omitempty"`
+}
+
+func (m RegexpMode) String() string {
+	switch m {
+	case RegexpModeOn:
+		return "regexp"
+	case RegexpModeOff:
+		return "literal"
+	}
+	return "auto"
+}
+
+func (m CaseMode) String() string {
+	switch m {
+	case CaseModeSensitive:
+		return "case sensitive"
+	case CaseModeInsensitive:
+		return "ignore case"
+	}
+	return "smart case"
+}
+
+// Step to the next mode, for toggling from the prompt
+func (m RegexpMode) next() RegexpMode {
+	return (m + 1) % 3
+}
+
+// Step to the next mode, for toggling from the prompt
+func (m CaseMode) next() CaseMode {
+	return (m + 1) % 3
+}
+
+// Describe the options for showing in the search and filter prompts. Example:
+// "auto, smart case, word".
+func (o SearchOptions) String() string {
+	parts := []string{o.Regexp.String(), o.Case.String()}
+	if o.WholeWord {
+		parts = append(parts, "word")
+	}
+	if o.Fold {
+		parts = append(parts, "fold")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// toPattern compiles a search string into a pattern according to the options.
+//
+// If the string is empty the pattern will be nil.
+//
+// With RegexpModeOn, strings that aren't valid regexps will give you a nil
+// pattern.
+func (o SearchOptions) toPattern(compileMe string) *regexp.Regexp {
+	if len(compileMe) == 0 {
+		return nil
+	}
+
+	caseInsensitive := false
+	switch o.Case {
+	case CaseModeSmart:
+		// Smart case; be case insensitive unless there are upper case chars
+		// in the search string
+		caseInsensitive = true
+		for _, char := range compileMe {
+			if unicode.IsUpper(char) {
+				caseInsensitive = false
+				break
+			}
+		}
+	case CaseModeInsensitive:
+		caseInsensitive = true
+	}
+
+	compile := func(expression string) (*regexp.Regexp, error) {
+		if o.WholeWord {
+			expression = wholeWord(expression)
+		}
+		if caseInsensitive {
+			expression = "(?i)" + expression
+		}
+		if o.Fold {
+			folded, err := foldPattern(expression)
+			if err != nil {
+				return nil, err
+			}
+			expression = folded
+		}
+		return regexp.Compile(expression)
+	}
+
+	if o.Regexp != RegexpModeOff {
+		pattern, err := compile(compileMe)
+		if err == nil {
+			// Search string is a regexp
+			return pattern
+		}
+
+		if o.Regexp == RegexpModeOn {
+			return nil
+		}
+	}
+
+	pattern, err := compile(regexp.QuoteMeta(compileMe))
+	if err == nil {
+		// Pattern matching the string exactly
+		return pattern
+	}
+
+	// Unable to create a match-string-verbatim pattern
+	panic(err)
+}
+
+// toPattern compiles a search string into a pattern using the default search
+// options.
+//
+// If the string contains only lower-case letter the pattern will be case insensitive.
+//
+// If the string is empty the pattern will be nil.
+//
+// If the string does not compile into a regexp the pattern will match the string verbatim
+func toPattern(compileMe string) *regexp.Regexp {
+	return SearchOptions{}.toPattern(compileMe)
+}
+
+// Toggle one of the options based on a key press. Returns true if the key was
+// handled.
+func (o *SearchOptions) onKey(key twin.KeyCode) bool {
+	switch key {
+	case twin.KeyAltR:
+		o.Regexp = o.Regexp.next()
+	case twin.KeyAltC:
+		o.Case = o.Case.next()
+	case twin.KeyAltW:
+		o.WholeWord = !o.WholeWord
+	case twin.KeyAltD:
+		o.Fold = !o.Fold
+	default:
+		return false
+	}
+
+	return true
+}
+
+// Build a prompt like "Search [auto, smart case]: "
+func (o SearchOptions) prompt(label string, searchString string) string {
+	details := o.String()
+	if searchString != "" && o.toPattern(searchString) == nil {
+		details += ", invalid"
+	}
+	return label + " [" + details + "]: "
+}
+
+// Make an expression match whole words only.
+//
+// A word boundary is only required on the sides where the expression can
+// start or end with a word character. Otherwise "[ERROR]" would never match
+// in "x [ERROR] y", since there are no word boundaries around the brackets.
+func wholeWord(expression string) string {
+	parsed, err := syntax.Parse(expression, syntax.Perl)
+	if err != nil {
+		// Compiling will fail anyway, let that report the problem
+		return expression
+	}
+
+	expression = `(?:` + expression + `)`
+	if mayBeWordCharAtEdge(parsed, true) {
+		expression = `\b` + expression
+	}
+	if mayBeWordCharAtEdge(parsed, false) {
+		expression += `\b`
+	}
+	return expression
+}
+
+// Could the first (or last) character matched by this expression be a word
+// character? When unsure, the answer is yes.
+func mayBeWordCharAtEdge(re *syntax.Regexp, first bool) bool {
+	switch re.Op {
+	case syntax.OpLiteral:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		if first {
+			return isWordChar(re.Rune[0])
+		}
+		return isWordChar(re.Rune[len(re.Rune)-1])
+
+	case syntax.OpCharClass:
+		for i := 0; i+1 < len(re.Rune); i += 2 {
+			for _, word := range [][2]rune{{'0', '9'}, {'A', 'Z'}, {'_', '_'}, {'a', 'z'}} {
+				if re.Rune[i] <= word[1] && re.Rune[i+1] >= word[0] {
+					return true
+				}
+			}
+		}
+		return false
+
+	case syntax.OpCapture:
+		return mayBeWordCharAtEdge(re.Sub[0], first)
+
+	case syntax.OpConcat:
+		if len(re.Sub) == 0 {
+			return false
+		}
+		if first {
+			return mayBeWordCharAtEdge(re.Sub[0], first)
+		}
+		return mayBeWordCharAtEdge(re.Sub[len(re.Sub)-1], first)
+
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if mayBeWordCharAtEdge(sub, first) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Same definition as \b in Go regexps
+func isWordChar(char rune) bool {
+	return char == '_' ||
+		('0' <= char && char <= '9') ||
+		('a' <= char && char <= 'z') ||
+		('A' <= char && char <= 'Z')
+}
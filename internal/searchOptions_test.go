@@ -0,0 +1,116 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestSearchOptionsLiteral(t *testing.T) {
+	options := SearchOptions{Regexp: RegexpModeOff}
+
+	assert.Assert(t, options.toPattern("a.c").MatchString("a.c"))
+	assert.Assert(t, !options.toPattern("a.c").MatchString("abc"))
+}
+
+func TestSearchOptionsRegexp(t *testing.T) {
+	options := SearchOptions{Regexp: RegexpModeOn}
+
+	assert.Assert(t, options.toPattern("a.c").MatchString("abc"))
+
+	// Invalid regexps should match nothing rather than falling back to
+	// literal matching
+	assert.Assert(t, options.toPattern(")g") == nil)
+}
+
+func TestSearchOptionsCase(t *testing.T) {
+	sensitive := SearchOptions{Case: CaseModeSensitive}
+	assert.Assert(t, sensitive.toPattern("abc").MatchString("abc"))
+	assert.Assert(t, !sensitive.toPattern("abc").MatchString("ABC"))
+
+	insensitive := SearchOptions{Case: CaseModeInsensitive}
+	assert.Assert(t, insensitive.toPattern("ABC").MatchString("abc"))
+	assert.Assert(t, insensitive.toPattern("abc").MatchString("ABC"))
+}
+
+func TestSearchOptionsWholeWord(t *testing.T) {
+	options := SearchOptions{WholeWord: true}
+
+	assert.Assert(t, options.toPattern("cat").MatchString("a cat sat"))
+	assert.Assert(t, !options.toPattern("cat").MatchString("concatenate"))
+
+	// Alternatives should all be bounded, not just the first and the last one
+	assert.Assert(t, !options.toPattern("dog|cat").MatchString("dogs"))
+
+	// Whole word literal matching
+	options.Regexp = RegexpModeOff
+	assert.Assert(t, options.toPattern("c.t").MatchString("a c.t sat"))
+	assert.Assert(t, !options.toPattern("c.t").MatchString("a cat sat"))
+
+	// No word boundaries are needed next to non-word characters
+	assert.Assert(t, options.toPattern("[ERROR]").MatchString("x [ERROR] y"))
+	assert.Assert(t, options.toPattern("[ERROR]").MatchString("x[ERROR]y"))
+	assert.Assert(t, options.toPattern("[ERROR").MatchString("x [ERROR] y"))
+	assert.Assert(t, !options.toPattern("[ERR").MatchString("x [ERROR] y"))
+
+	options.Regexp = RegexpModeOn
+	assert.Assert(t, options.toPattern(`\[ERROR\]`).MatchString("x [ERROR] y"))
+	assert.Assert(t, !options.toPattern(`\[ERR`).MatchString("x [ERROR] y"))
+	assert.Assert(t, !options.toPattern(`ERR[A-Z]`).MatchString("x [ERRORS] y"))
+}
+
+func TestSearchOptionsOnKey(t *testing.T) {
+	options := SearchOptions{}
+
+	assert.Assert(t, options.onKey(twin.KeyAltR))
+	assert.Equal(t, options.Regexp, RegexpModeOn)
+	assert.Assert(t, options.onKey(twin.KeyAltR))
+	assert.Equal(t, options.Regexp, RegexpModeOff)
+	assert.Assert(t, options.onKey(twin.KeyAltR))
+	assert.Equal(t, options.Regexp, RegexpModeAuto)
+
+	assert.Assert(t, options.onKey(twin.KeyAltC))
+	assert.Equal(t, options.Case, CaseModeSensitive)
+
+	assert.Assert(t, options.onKey(twin.KeyAltW))
+	assert.Assert(t, options.WholeWord)
+
+	assert.Assert(t, !options.onKey(twin.KeyUp))
+}
+
+func TestSearchOptionsPrompt(t *testing.T) {
+	assert.Equal(t, SearchOptions{}.prompt("Search", "x"), "Search [auto, smart case]: ")
+
+	options := SearchOptions{Regexp: RegexpModeOn, WholeWord: true}
+	assert.Equal(t, options.prompt("Filter", "("), "Filter [regexp, smart case, word, invalid]: ")
+}
+
+func TestSearchOptionsFold(t *testing.T) {
+	options := SearchOptions{Fold: true}
+
+	assert.Assert(t, options.toPattern("resume").MatchString("résumé"))
+	assert.Assert(t, options.toPattern("résumé").MatchString("resume"))
+	assert.Assert(t, options.toPattern("abc").MatchString("ＡＢＣ"))
+	assert.Assert(t, !options.toPattern("resume").MatchString("rasume"))
+
+	// Decomposed form, e followed by a combining acute accent
+	assert.Assert(t, options.toPattern("resume").MatchString("résumé"))
+
+	// Smart case should still apply
+	assert.Assert(t, options.toPattern("Resume").MatchString("Résumé"))
+	assert.Assert(t, !options.toPattern("Resume").MatchString("résumé"))
+
+	// Character classes should be folded as well
+	options.Regexp = RegexpModeOn
+	assert.Assert(t, options.toPattern("r[e]sum.").MatchString("résumé"))
+}
+
+func TestSearchOptionsFoldMatchPositions(t *testing.T) {
+	options := SearchOptions{Fold: true}
+
+	// The match should cover the original accented text, so that highlighting
+	// ends up on the right cells
+	text := "my résumé"
+	assert.DeepEqual(t, options.toPattern("resume").FindStringIndex(text), []int{3, len(text)})
+}
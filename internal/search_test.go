@@ -1,6 +1,7 @@
 package internal
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/walles/moor/internal/reader"
@@ -126,3 +127,61 @@ func Test152(t *testing.T) {
 	assert.Equal(t, "Search", modeName(pager))
 	assert.Equal(t, 2, pager.lineIndex().Index())
 }
+
+// Create a pager with one long line with hits far off to the right
+func createLongLinePager(t *testing.T) *Pager {
+	line := strings.Repeat(".", 100) + "hit" + strings.Repeat(".", 100) + "hit" + strings.Repeat(".", 100)
+	reader := reader.NewFromTextForTesting("", "first\n"+line+"\nlast\n")
+	assert.NilError(t, reader.Wait())
+
+	pager := NewPager(reader)
+	pager.ShowLineNumbers = false
+	pager.screen = twin.NewFakeScreen(20, 5)
+
+	pager.searchString = "hit"
+	pager.searchPattern = toPattern(pager.searchString)
+
+	return pager
+}
+
+func TestScrollToSearchHitsHorizontally(t *testing.T) {
+	pager := createLongLinePager(t)
+
+	pager.scrollToSearchHits()
+	assert.Assert(t, pager.lineIndex().IsZero(), "Line is visible, no need to scroll vertically")
+	assert.Equal(t, pager.currentSearchHit.runeRange, [2]int{100, 103})
+
+	// The hit should be visible, with its end just left of the scroll right
+	// hint
+	assert.Equal(t, pager.leftColumnZeroBased, 103-19)
+}
+
+func TestScrollToNextSearchHitWithinLine(t *testing.T) {
+	pager := createLongLinePager(t)
+	pager.scrollToSearchHits()
+
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "Viewing", modeName(pager))
+	assert.Equal(t, pager.currentSearchHit.runeRange, [2]int{203, 206})
+	assert.Equal(t, pager.leftColumnZeroBased, 206-19)
+
+	// Going back should take us back to the first hit, starting just right of
+	// the scroll left hint
+	pager.scrollToPreviousSearchHit()
+	assert.Equal(t, pager.currentSearchHit.runeRange, [2]int{100, 103})
+	assert.Equal(t, pager.leftColumnZeroBased, 99)
+
+	// No more hits on this line, and the rest of the input is already visible
+	pager.scrollToNextSearchHit()
+	pager.scrollToNextSearchHit()
+	assert.Equal(t, "NotFound", modeName(pager))
+}
+
+func TestScrollToSearchHitsWrapping(t *testing.T) {
+	pager := createLongLinePager(t)
+	pager.WrapLongLines = true
+
+	pager.scrollToSearchHits()
+	assert.Assert(t, pager.currentSearchHit == nil)
+	assert.Equal(t, pager.leftColumnZeroBased, 0)
+}
@@ -0,0 +1,209 @@
+package internal
+
+import (
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/twin"
+)
+
+// A range of input lines selected for copying. The anchor is where the
+// selection started, and the cursor is the end the user moves around. The
+// cursor can be before the anchor.
+type lineSelection struct {
+	anchor linemetadata.Index
+	cursor linemetadata.Index
+}
+
+// First and last selected line, in document order
+func (s lineSelection) bounds() (first linemetadata.Index, last linemetadata.Index) {
+	if s.cursor.IsBefore(s.anchor) {
+		return s.cursor, s.anchor
+	}
+	return s.anchor, s.cursor
+}
+
+func (s lineSelection) contains(lineIndex linemetadata.Index) bool {
+	first, last := s.bounds()
+	return !lineIndex.IsBefore(first) && !lineIndex.IsAfter(last)
+}
+
+func (s lineSelection) lineCount() int {
+	first, last := s.bounds()
+	return first.CountLinesTo(last)
+}
+
+// Start selecting lines using the keyboard, beginning with the top line on
+// screen
+func (p *Pager) startSelection() {
+	lineIndex := p.lineIndex()
+	if lineIndex == nil {
+		// Empty input, nothing to select
+		return
+	}
+
+	p.selection = &lineSelection{anchor: *lineIndex, cursor: *lineIndex}
+	p.mode = PagerModeSelect{pager: p}
+}
+
+// Move the selection cursor, and scroll to keep it visible
+func (p *Pager) moveSelectionCursor(delta int) {
+	if p.selection == nil {
+		return
+	}
+
+	lastIndex := linemetadata.IndexFromLength(p.Reader().GetLineCount())
+	if lastIndex == nil {
+		return
+	}
+
+	cursor := p.selection.cursor.NonWrappingAdd(delta)
+	if cursor.IsBefore(p.firstScrollableIndex()) {
+		cursor = p.firstScrollableIndex()
+	}
+	if cursor.IsAfter(*lastIndex) {
+		cursor = *lastIndex
+	}
+	p.selection.cursor = cursor
+
+	if p.isLineVisible(cursor) {
+		return
+	}
+
+	p.setTargetLine(nil)
+	p.scrollPosition = NewScrollPositionFromIndex(cursor, "selection")
+	if delta > 0 {
+		// Put the cursor at the bottom of the screen
+		p.scrollPosition = p.scrollPosition.PreviousLine(p.visibleHeight() - 1)
+	}
+}
+
+// Copy the selected lines to the clipboard and stop selecting
+func (p *Pager) copySelection() {
+	if p.selection == nil {
+		return
+	}
+
+	first, last := p.selection.bounds()
+	lines := make([]string, 0, p.selection.lineCount())
+	for lineIndex := first; !lineIndex.IsAfter(last); lineIndex = lineIndex.NonWrappingAdd(1) {
+		line := p.Reader().GetLine(lineIndex)
+		if line == nil {
+			break
+		}
+		lines = append(lines, line.Plain())
+	}
+
+	if p.setClipboard(strings.Join(lines, "\n")) {
+		log.Debug("Copied ", len(lines), " lines to the clipboard")
+	}
+
+	p.cancelSelection()
+}
+
+// Put some text on the clipboard. Returns false and shows an error message if
+// the screen can't do that.
+func (p *Pager) setClipboard(text string) bool {
+	clipboardScreen, canCopy := p.terminalScreen().(twin.ClipboardScreen)
+	if !canCopy {
+		p.showWarning("Copying to the clipboard is not supported")
+		return false
+	}
+
+	clipboardScreen.SetClipboard(text)
+	return true
+}
+
+func (p *Pager) cancelSelection() {
+	p.selection = nil
+	if _, isSelecting := p.mode.(PagerModeSelect); isSelecting {
+		p.mode = PagerModeViewing{pager: p}
+	}
+}
+
+// Copy the current search hit to the clipboard. If there is no current hit,
+// the first hit on screen is copied.
+func (p *Pager) copySearchHit() {
+	if p.searchPattern == nil {
+		return
+	}
+
+	hit := p.currentSearchHit
+	if hit == nil || !p.isLineVisible(hit.lineIndex) {
+		hit = p.firstSearchHitOnScreen()
+	}
+	if hit == nil {
+		log.Debug("No search hit on screen to copy")
+		return
+	}
+
+	line := p.Reader().GetLine(hit.lineIndex)
+	if line == nil {
+		return
+	}
+
+	plain := []rune(line.Plain())
+	p.setClipboard(string(plain[hit.runeRange[0]:hit.runeRange[1]]))
+}
+
+func (p *Pager) firstSearchHitOnScreen() *searchHit {
+	renderedLines, _ := p.renderLines()
+	for _, renderedLine := range renderedLines {
+		line := p.Reader().GetLine(renderedLine.inputLineIndex)
+		if line == nil {
+			continue
+		}
+
+		ranges := line.MatchRanges(p.searchPattern)
+		if len(ranges) > 0 {
+			return &searchHit{lineIndex: renderedLine.inputLineIndex, runeRange: ranges[0]}
+		}
+	}
+
+	return nil
+}
+
+// When dragging past the top or bottom edge of the lines on screen, scroll one
+// line in that direction. This makes dragging past the edges of the screen
+// extend the selection. Row zero is the first row below any header lines.
+func (p *Pager) scrollTowardsRow(row int) {
+	if row < 0 {
+		p.scrollPosition = p.scrollPosition.PreviousLine(1)
+		p.handleScrolledUp()
+	} else if row >= p.visibleHeight() {
+		p.scrollPosition = p.scrollPosition.NextLine(1)
+		p.handleScrolledDown()
+	}
+}
+
+// Which input line is shown on this screen row? Row zero is the first row
+// below any header lines.
+//
+// Rows above or below the lines on screen give the first or last line on
+// screen. Returns nil if there are no lines on screen.
+func (p *Pager) lineIndexAtRow(row int) *linemetadata.Index {
+	row = max(row, 0)
+	renderedLines, _ := p.renderLines()
+	if len(renderedLines) == 0 {
+		return nil
+	}
+	if row >= len(renderedLines) {
+		row = len(renderedLines) - 1
+	}
+
+	return &renderedLines[row].inputLineIndex
+}
+
+// Highlight a selected screen line all the way to the right edge
+func (p *Pager) highlightSelectedLine(cells []twin.StyledRune) []twin.StyledRune {
+	for len(cells) < p.contentWidth() {
+		cells = append(cells, twin.NewStyledRune(' ', twin.StyleDefault))
+	}
+
+	highlighted := make([]twin.StyledRune, 0, len(cells))
+	for _, cell := range cells {
+		highlighted = append(highlighted, twin.NewStyledRune(cell.Rune, cell.Style.WithAttr(twin.AttrReverse)))
+	}
+	return highlighted
+}
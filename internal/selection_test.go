@@ -0,0 +1,120 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func newSelectionTestPager(t *testing.T, text string) (*Pager, *twin.FakeScreen) {
+	return newTestPager(t, reader.NewFromTextForTesting("TestSelection", text), 20, 4)
+}
+
+func TestSelectAndCopyLines(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond\nthird\nfourth\nfifth")
+
+	pager.mode.onRune('V')
+	assert.Equal(t, pager.selection.lineCount(), 1)
+
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onRune('j')
+	pager.mode.onRune('j')
+	assert.Equal(t, pager.selection.lineCount(), 4)
+
+	// The selection cursor should stay on screen
+	assert.Assert(t, pager.isLineVisible(pager.selection.cursor))
+
+	pager.mode.onRune('k')
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, screen.GetClipboard(), "first\nsecond\nthird")
+	assert.Assert(t, pager.selection == nil)
+	assert.Equal(t, pager.mode, PagerMode(PagerModeViewing{pager: pager}))
+}
+
+func TestCancelSelection(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond\nthird")
+
+	pager.mode.onRune('V')
+	pager.mode.onKey(twin.KeyDown)
+	pager.mode.onKey(twin.KeyEscape)
+
+	assert.Assert(t, pager.selection == nil)
+	assert.Equal(t, screen.GetClipboard(), "")
+}
+
+func TestSelectedLinesAreHighlighted(t *testing.T) {
+	pager, _ := newSelectionTestPager(t, "first\nsecond\nthird")
+
+	pager.mode.onRune('V')
+	pager.mode.onKey(twin.KeyDown)
+
+	lines, _ := pager.renderScreenLines()
+	assert.Equal(t, len(lines[0]), pager.contentWidth())
+	assert.Equal(t, lines[0][0].Style, twin.StyleDefault.WithAttr(twin.AttrReverse))
+	assert.Equal(t, lines[1][0].Style, twin.StyleDefault.WithAttr(twin.AttrReverse))
+	assert.Equal(t, lines[2][0].Style, twin.StyleDefault)
+}
+
+func TestMouseDragSelectsAndCopies(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond\nthird")
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MousePress, 0, 2, 1))
+	assert.Assert(t, pager.selection == nil)
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseDrag, 0, 3, 2))
+	assert.Equal(t, pager.selection.lineCount(), 2)
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseRelease, 0, 3, 2))
+	assert.Equal(t, screen.GetClipboard(), "second\nthird")
+	assert.Assert(t, pager.selection == nil)
+}
+
+func TestMouseClickDoesNotCopy(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond\nthird")
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MousePress, 0, 2, 1))
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MouseRelease, 0, 2, 1))
+	assert.Equal(t, screen.GetClipboard(), "")
+}
+
+func TestCopySearchHit(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond 1234 line\nthird")
+
+	// No search, nothing to copy
+	pager.mode.onRune('c')
+	assert.Equal(t, screen.GetClipboard(), "")
+
+	pager.searchPattern = toPattern("[0-9]+")
+	pager.mode.onRune('c')
+	assert.Equal(t, screen.GetClipboard(), "1234")
+}
+
+func TestLineIndexAtRowDoesNotScroll(t *testing.T) {
+	pager, _ := newSelectionTestPager(t, "first\nsecond\nthird\nfourth\nfifth")
+
+	assert.Equal(t, pager.lineIndexAtRow(-1).Index(), 0)
+	assert.Equal(t, pager.lineIndexAtRow(10).Index(), 2)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+
+	// Scrolling is done separately
+	pager.scrollTowardsRow(10)
+	assert.Equal(t, pager.lineIndex().Index(), 1)
+	assert.Equal(t, pager.lineIndexAtRow(10).Index(), 3)
+}
+
+// A screen without clipboard support
+type noClipboardScreen struct {
+	twin.Screen
+}
+
+func TestCopyWithoutClipboardSupport(t *testing.T) {
+	pager, screen := newSelectionTestPager(t, "first\nsecond\nthird")
+	pager.screen = noClipboardScreen{Screen: screen}
+
+	pager.mode.onRune('V')
+	pager.mode.onKey(twin.KeyEnter)
+	assert.Equal(t, statusText(pager), "Copying to the clipboard is not supported")
+	assert.Equal(t, screen.GetClipboard(), "")
+}
@@ -0,0 +1,298 @@
+package internal
+
+import (
+	"regexp"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/twin"
+)
+
+// The state of the pane that doesn't have focus while the screen is split. The
+// focused pane's state lives in the Pager itself, just like for buffers.
+type pane struct {
+	bufferIndex         int
+	scrollPosition      scrollPosition
+	leftColumnZeroBased int
+	targetLine          *linemetadata.Index
+
+	// Each pane highlights its own search
+	searchPattern *regexp.Regexp
+
+	scrollbarTicks        *scrollbarTicks
+	scrollbarTicksPending *scrollbarTicksKey
+
+	// For drawing this pane, kept between frames so that the filtering cache
+	// survives. nil until first drawn.
+	filteringReader *FilteringReader
+}
+
+// Part of the screen, showing one pane. Rows are relative to the top of the
+// pane, and everything not overridden here goes to the whole screen.
+type paneScreen struct {
+	twin.Screen
+
+	firstRow int
+	height   int
+}
+
+func (s paneScreen) Size() (width int, height int) {
+	width, _ = s.Screen.Size()
+	return width, s.height
+}
+
+func (s paneScreen) SetCell(column int, row int, styledRune twin.StyledRune) int {
+	if row < 0 || row >= s.height {
+		return styledRune.Width()
+	}
+	return s.Screen.SetCell(column, s.firstRow+row, styledRune)
+}
+
+func (s paneScreen) ShowCursorAt(column int, row int) {
+	s.Screen.ShowCursorAt(column, s.firstRow+row)
+}
+
+// Clears only this pane
+func (s paneScreen) Clear() {
+	width, _ := s.Size()
+	for row := 0; row < s.height; row++ {
+		for column := 0; column < width; column++ {
+			s.SetCell(column, row, twin.StyledRune{Rune: ' '})
+		}
+	}
+}
+
+// Each pane needs room for at least one line plus its status line
+const minPaneHeight = 2
+
+func (p *Pager) isSplit() bool {
+	return p.otherPane != nil
+}
+
+// The top and bottom parts of the whole screen
+func (p *Pager) paneScreens() (top paneScreen, bottom paneScreen) {
+	_, height := p.wholeScreen.Size()
+
+	topHeight := p.topPaneHeight
+	if topHeight > height-minPaneHeight {
+		topHeight = height - minPaneHeight
+	}
+	if topHeight < minPaneHeight {
+		topHeight = minPaneHeight
+	}
+
+	top = paneScreen{Screen: p.wholeScreen, firstRow: 0, height: topHeight}
+	bottom = paneScreen{Screen: p.wholeScreen, firstRow: topHeight, height: height - topHeight}
+	return
+}
+
+// The screen we're drawing on. While split, this is the whole screen rather
+// than just the focused pane's part of it.
+func (p *Pager) terminalScreen() twin.Screen {
+	if p.isSplit() {
+		return p.wholeScreen
+	}
+	return p.screen
+}
+
+// While split, p.screen is the focused pane's part of the screen. Call this
+// before using p.screen, since the screen size may have changed.
+func (p *Pager) updatePaneScreens() {
+	if !p.isSplit() {
+		return
+	}
+
+	if p.isShowingHelp {
+		// Help is shown on the whole screen
+		p.screen = p.wholeScreen
+		return
+	}
+
+	p.screen, _ = p.focusedAndOtherPaneScreens()
+}
+
+func (p *Pager) focusedAndOtherPaneScreens() (focused paneScreen, other paneScreen) {
+	top, bottom := p.paneScreens()
+	if p.isBottomPaneFocused {
+		return bottom, top
+	}
+	return top, bottom
+}
+
+// Split the screen in two panes, or join them back if already split. Both
+// panes start out showing the same thing, and the bottom one gets focus.
+func (p *Pager) toggleSplit() {
+	if p.isShowingHelp {
+		return
+	}
+
+	if p.isSplit() {
+		p.otherPane = nil
+		p.screen = p.wholeScreen
+		return
+	}
+
+	p.otherPane = &pane{
+		bufferIndex:         p.currentBufferIndex,
+		scrollPosition:      p.scrollPosition,
+		leftColumnZeroBased: p.leftColumnZeroBased,
+		targetLine:          p.TargetLine,
+		searchPattern:       p.searchPattern,
+	}
+	p.wholeScreen = p.screen
+	_, height := p.wholeScreen.Size()
+	p.topPaneHeight = height / 2
+	p.isBottomPaneFocused = true
+	p.updatePaneScreens()
+}
+
+// Move the focused pane's state into p.otherPane, and the other pane's state
+// into the pager.
+func (p *Pager) swapPanes() {
+	other := *p.otherPane
+	p.otherPane = &pane{
+		bufferIndex:           p.currentBufferIndex,
+		scrollPosition:        p.scrollPosition,
+		leftColumnZeroBased:   p.leftColumnZeroBased,
+		targetLine:            p.TargetLine,
+		searchPattern:         p.searchPattern,
+		scrollbarTicks:        p.scrollbarTicks,
+		scrollbarTicksPending: p.scrollbarTicksPending,
+	}
+
+	p.switchToBuffer(other.bufferIndex)
+	p.scrollPosition = other.scrollPosition
+	p.leftColumnZeroBased = other.leftColumnZeroBased
+	p.searchPattern = other.searchPattern
+	p.currentSearchHit = nil
+	p.focusedHyperlink = nil
+	p.scrollbarTicks = other.scrollbarTicks
+	p.scrollbarTicksPending = other.scrollbarTicksPending
+	p.setTargetLine(other.targetLine)
+
+	// Lines may have arrived while this pane didn't have focus
+	p.scrollTowardsTargetLine()
+}
+
+func (p *Pager) switchPaneFocus() {
+	if !p.isSplit() || p.isShowingHelp {
+		return
+	}
+
+	p.isBottomPaneFocused = !p.isBottomPaneFocused
+	p.updatePaneScreens()
+	p.swapPanes()
+}
+
+// Positive deltas make the focused pane taller
+func (p *Pager) resizeFocusedPane(delta int) {
+	if !p.isSplit() || p.isShowingHelp {
+		return
+	}
+
+	top, _ := p.paneScreens()
+	if p.isBottomPaneFocused {
+		delta = -delta
+	}
+
+	_, height := p.wholeScreen.Size()
+	p.topPaneHeight = top.height + delta
+	if p.topPaneHeight < minPaneHeight {
+		p.topPaneHeight = minPaneHeight
+	}
+	if p.topPaneHeight > height-minPaneHeight {
+		p.topPaneHeight = height - minPaneHeight
+	}
+	p.updatePaneScreens()
+}
+
+// Draw both panes. The focused pane gets the mode's footer, the other one a
+// plain status line.
+func (p *Pager) redrawSplit(spinner string) {
+	p.wholeScreen.Clear()
+
+	focused, other := p.focusedAndOtherPaneScreens()
+
+	view := p.otherPaneView(other)
+	statusText := view.drawPane(spinner)
+	view.setFooterWithStyle(view.bufferStatusPrefix()+statusText, unfocusedStatusbarStyle())
+
+	// Keep what drawing found out, like the canonical scroll position and any
+	// scrollbar ticks computation that was started
+	p.otherPane.scrollPosition = view.scrollPosition
+	p.otherPane.scrollbarTicks = view.scrollbarTicks
+	p.otherPane.scrollbarTicksPending = view.scrollbarTicksPending
+
+	p.screen = focused
+	statusText = p.drawPane(spinner)
+	p.mode.drawFooter(p.bufferStatusPrefix()+statusText, spinner)
+
+	p.wholeScreen.Show()
+}
+
+// Lines have arrived, scroll the pane without focus towards its target line if
+// it has one
+func (p *Pager) scrollOtherPaneTowardsTargetLine() {
+	if !p.isSplit() || p.otherPane.targetLine == nil {
+		return
+	}
+
+	_, other := p.focusedAndOtherPaneScreens()
+	view := p.otherPaneView(other)
+	view.scrollTowardsTargetLine()
+	p.otherPane.scrollPosition = view.scrollPosition
+	p.otherPane.targetLine = view.TargetLine
+
+	// The panes may share a reader, make sure it reads what the focused pane
+	// needs
+	p.setTargetLine(p.TargetLine)
+}
+
+// A copy of the pager, showing the pane without focus on the given screen.
+// Drawing the pane using this copy leaves the pager itself untouched.
+func (p *Pager) otherPaneView(screen twin.Screen) *Pager {
+	other := p.otherPane
+	b := p.buffers[other.bufferIndex]
+
+	view := *p
+	view.screen = screen
+	view.currentBufferIndex = other.bufferIndex
+	if other.bufferIndex != p.currentBufferIndex {
+		view.reader = b.reader
+		view.marks = b.marks
+	}
+
+	if other.filteringReader == nil || other.filteringReader.BackingReader != view.reader {
+		// The filter is shared between the panes
+		other.filteringReader = p.newFilteringReader(view.reader)
+	}
+	view.filteringReader = other.filteringReader
+
+	view.scrollPosition = other.scrollPosition
+	view.leftColumnZeroBased = other.leftColumnZeroBased
+	view.TargetLine = other.targetLine
+	view.searchPattern = other.searchPattern
+	view.scrollbarTicks = other.scrollbarTicks
+	view.scrollbarTicksPending = other.scrollbarTicksPending
+
+	// These belong to the focused pane
+	view.currentSearchHit = nil
+	view.focusedHyperlink = nil
+	view.selection = nil
+
+	return &view
+}
+
+// The status line of the pane without focus
+func unfocusedStatusbarStyle() twin.Style {
+	return statusbarStyle.WithAttr(twin.AttrDim)
+}
+
+// Which pane is hit by a mouse event at this whole-screen row, and what row in
+// that pane is it?
+func (p *Pager) paneAt(row int) (isBottomPane bool, paneRow int) {
+	top, _ := p.paneScreens()
+	if row < top.height {
+		return false, row
+	}
+	return true, row - top.height
+}
@@ -0,0 +1,131 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// 100 numbered lines on a 20x10 screen
+func newSplitTestPager(t *testing.T) (*Pager, *twin.FakeScreen) {
+	lines := make([]string, 0, 100)
+	for i := range 100 {
+		lines = append(lines, "line "+linemetadata.IndexFromZeroBased(i).Format())
+	}
+	return newTestPager(t, reader.NewFromTextForTesting("TestSplit", strings.Join(lines, "\n")), 20, 10)
+}
+
+func TestSplitPanesScrollIndependently(t *testing.T) {
+	pager, screen := newSplitTestPager(t)
+
+	pager.toggleSplit()
+	assert.Assert(t, pager.isSplit())
+	assert.Assert(t, pager.isBottomPaneFocused)
+	assert.Equal(t, pager.visibleHeight(), 4)
+
+	// Scroll the bottom pane
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(50), "test")
+
+	pager.redraw("")
+	assert.Equal(t, rowToString(screen.GetRow(0)), "line 1")
+	assert.Equal(t, rowToString(screen.GetRow(5)), "line 51")
+
+	// Move to the top pane and scroll that
+	pager.switchPaneFocus()
+	assert.Assert(t, !pager.isBottomPaneFocused)
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(10), "test")
+
+	pager.redraw("")
+	assert.Equal(t, rowToString(screen.GetRow(0)), "line 11")
+	assert.Equal(t, rowToString(screen.GetRow(5)), "line 51")
+
+	// Joining keeps the focused pane
+	pager.toggleSplit()
+	assert.Assert(t, !pager.isSplit())
+	assert.Equal(t, pager.visibleHeight(), 9)
+	assert.Equal(t, pager.lineIndex().Index(), 10)
+}
+
+func TestSplitPanesHaveTheirOwnSearch(t *testing.T) {
+	pager, _ := newSplitTestPager(t)
+	pager.toggleSplit()
+
+	pattern := toPattern("line 5")
+	pager.searchPattern = pattern
+	pager.switchPaneFocus()
+	assert.Assert(t, pager.searchPattern == nil)
+
+	pager.switchPaneFocus()
+	assert.Equal(t, pager.searchPattern, pattern)
+}
+
+func TestResizePanes(t *testing.T) {
+	pager, _ := newSplitTestPager(t)
+	pager.toggleSplit()
+
+	top, bottom := pager.paneScreens()
+	assert.Equal(t, top.height, 5)
+	assert.Equal(t, bottom.height, 5)
+
+	// The bottom pane has focus
+	pager.resizeFocusedPane(2)
+	top, bottom = pager.paneScreens()
+	assert.Equal(t, top.height, 3)
+	assert.Equal(t, bottom.height, 7)
+
+	// Both panes need room for at least one line plus a status line
+	pager.resizeFocusedPane(100)
+	top, bottom = pager.paneScreens()
+	assert.Equal(t, top.height, 2)
+	assert.Equal(t, bottom.height, 8)
+}
+
+func TestClickingOtherPaneFocusesIt(t *testing.T) {
+	pager, _ := newSplitTestPager(t)
+	pager.toggleSplit()
+	assert.Assert(t, pager.isBottomPaneFocused)
+
+	pager.onMouse(twin.NewEventMouse(twin.MouseButtonLeft, twin.MousePress, 0, 3, 1))
+	assert.Assert(t, !pager.isBottomPaneFocused)
+}
+
+// Drawing the pane without focus used to reset the focused pane's current
+// search hit, so stepping between hits on the same line didn't work
+func TestSplitStepThroughHitsOnOneLine(t *testing.T) {
+	lines := []string{"hit and hit"}
+	for range 48 {
+		lines = append(lines, "nothing")
+	}
+	lines = append(lines, "hit again")
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("TestSplit", strings.Join(lines, "\n")), 20, 10)
+
+	pager.toggleSplit()
+	pager.searchPattern = toPattern("hit")
+	pager.scrollToSearchHits()
+	assert.Equal(t, pager.currentSearchHit.runeRange, [2]int{0, 3})
+	pager.redraw("")
+
+	typeRunes(pager, "n")
+	assert.Equal(t, pager.currentSearchHit.runeRange, [2]int{8, 11})
+	assert.Equal(t, pager.lineIndex().Index(), 0)
+	pager.redraw("")
+
+	typeRunes(pager, "n")
+	assert.Equal(t, pager.currentSearchHit.lineIndex.Index(), 49)
+}
+
+// Drawing must not change what the focused pane is doing
+func TestSplitRedrawKeepsTargetLine(t *testing.T) {
+	pager, _ := newSplitTestPager(t)
+	pager.toggleSplit()
+
+	target := linemetadata.IndexFromZeroBased(99)
+	pager.setTargetLine(&target)
+	pager.redraw("")
+	assert.Equal(t, *pager.TargetLine, target)
+}
@@ -0,0 +1,468 @@
+package internal
+
+import (
+	"fmt"
+	"math"
+	"path/filepath"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/util"
+	"github.com/walles/moor/twin"
+)
+
+// Used when the user hasn't configured any status bar format. Renders the same
+// status bar we had before formats were configurable.
+const defaultStatusFormatString = "{status}[  {diff-file}][  {spinner}]  {hints}"
+
+// Everything after this is right aligned
+const statusFormatRightAlign = "{>}"
+
+// What the status bar fields expand to, in the order they are documented
+var statusFormatFields = []struct {
+	name        string
+	description string
+}{
+	{"status", `The default status text, like "file.txt: 123 lines  45%"`},
+	{"buffer", `Which input we're looking at when there are multiple, like "2/3"`},
+	{"name", "The name of the input"},
+	{"first", "Line number of the first line on screen"},
+	{"last", "Line number of the last line on screen"},
+	{"total", "Number of input lines"},
+	{"percent", `How far down the last line on screen is, like "45%"`},
+	{"column", "The leftmost visible column, counting from 1"},
+	{"offset", "Where in the input the first line on screen starts, in bytes"},
+	{"encoding", "How the input is decoded, always UTF-8 for now"},
+	{"filter", "The filter string"},
+	{"filtered", "Number of lines matching the filter"},
+	{"search", "The search string"},
+	{"matches", "Number of lines matching the search"},
+	{"follow", `"Following" when following the end of the input`},
+	{"diff-file", "In diffs, the file being changed at the top of the screen"},
+	{"clock", "The current time of day"},
+	{"spinner", "Progress indicator while reading or highlighting"},
+	{"hints", "Key binding hints, or the count being typed"},
+}
+
+// StatusFormat is a template for the status bar, see ParseStatusFormat()
+type StatusFormat struct {
+	left  []statusFormatPart
+	right []statusFormatPart
+
+	// Whether this template shows the time of day. Such templates need to be
+	// redrawn every minute.
+	hasClock bool
+}
+
+// A literal string, a field, or an optional group of other parts
+type statusFormatPart struct {
+	literal string
+	field   string
+
+	// If any field in the group expands to an empty string, the whole group
+	// is left out
+	group []statusFormatPart
+}
+
+// ParseStatusFormat parses a status bar template.
+//
+// Fields are written like {name}, and optional parts go in [brackets]. An
+// optional part is left out if any field inside of it is empty, so
+// "[{name}: ]" renders to nothing for unnamed input. Everything after {>} is
+// right aligned. Use a backslash to show any of {}[]\ literally.
+func ParseStatusFormat(format string) (*StatusFormat, error) {
+	leftString := format
+	rightString := ""
+	if before, after, found := strings.Cut(format, statusFormatRightAlign); found {
+		leftString = before
+		rightString = after
+	}
+
+	left, err := parseStatusFormatParts([]rune(leftString), false)
+	if err != nil {
+		return nil, err
+	}
+	right, err := parseStatusFormatParts([]rune(rightString), false)
+	if err != nil {
+		return nil, err
+	}
+
+	parsed := StatusFormat{left: left, right: right}
+	parsed.hasClock = partsHaveField(left, "clock") || partsHaveField(right, "clock")
+	return &parsed, nil
+}
+
+func parseStatusFormatParts(format []rune, inGroup bool) ([]statusFormatPart, error) {
+	parts, rest, err := parseStatusFormatUntil(format, inGroup)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) > 0 {
+		return nil, fmt.Errorf("Unexpected ']'")
+	}
+	return parts, nil
+}
+
+// Parse parts until the end of the format, or until the end of the current
+// group. Returns the parts and whatever comes after them.
+func parseStatusFormatUntil(format []rune, inGroup bool) ([]statusFormatPart, []rune, error) {
+	parts := []statusFormatPart{}
+	literal := strings.Builder{}
+	flushLiteral := func() {
+		if literal.Len() > 0 {
+			parts = append(parts, statusFormatPart{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for len(format) > 0 {
+		char := format[0]
+		format = format[1:]
+
+		switch char {
+		case '\\':
+			if len(format) == 0 {
+				return nil, nil, fmt.Errorf("Nothing to escape after the final '\\'")
+			}
+			literal.WriteRune(format[0])
+			format = format[1:]
+
+		case '{':
+			end := strings.IndexRune(string(format), '}')
+			if end < 0 {
+				return nil, nil, fmt.Errorf("Missing '}'")
+			}
+			name := string(format)[:end]
+			if !isStatusFormatField(name) {
+				return nil, nil, fmt.Errorf("Unknown field {%s}, valid ones are: %s", name, statusFormatFieldNames())
+			}
+			flushLiteral()
+			parts = append(parts, statusFormatPart{field: name})
+			format = []rune(string(format)[end+1:])
+
+		case '}':
+			return nil, nil, fmt.Errorf("Unexpected '}'")
+
+		case '[':
+			group, rest, err := parseStatusFormatUntil(format, true)
+			if err != nil {
+				return nil, nil, err
+			}
+			if len(rest) == 0 || rest[0] != ']' {
+				return nil, nil, fmt.Errorf("Missing ']'")
+			}
+			flushLiteral()
+			parts = append(parts, statusFormatPart{group: group})
+			format = rest[1:]
+
+		case ']':
+			if !inGroup {
+				return nil, nil, fmt.Errorf("Unexpected ']'")
+			}
+			flushLiteral()
+			return parts, append([]rune{char}, format...), nil
+
+		default:
+			literal.WriteRune(char)
+		}
+	}
+
+	flushLiteral()
+	return parts, nil, nil
+}
+
+func isStatusFormatField(name string) bool {
+	for _, field := range statusFormatFields {
+		if field.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func statusFormatFieldNames() string {
+	names := []string{}
+	for _, field := range statusFormatFields {
+		names = append(names, field.name)
+	}
+	return strings.Join(names, ", ")
+}
+
+func partsHaveField(parts []statusFormatPart, name string) bool {
+	for _, part := range parts {
+		if part.field == name || partsHaveField(part.group, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Field values for one status bar rendering. Values are computed when first
+// asked for, since some of them are expensive.
+type statusFormatValues struct {
+	pager      *Pager
+	statusText string
+	spinner    string
+	hints      string
+
+	computed map[string]string
+}
+
+func (v *statusFormatValues) get(name string) string {
+	if value, found := v.computed[name]; found {
+		return value
+	}
+
+	value := v.compute(name)
+	v.computed[name] = value
+	return value
+}
+
+func (v *statusFormatValues) compute(name string) string {
+	p := v.pager
+
+	switch name {
+	case "status":
+		return v.statusText
+	case "spinner":
+		return v.spinner
+	case "hints":
+		return v.hints
+
+	case "buffer":
+		if len(p.buffers) < 2 || p.isShowingHelp {
+			return ""
+		}
+		return strconv.Itoa(p.currentBufferIndex+1) + "/" + strconv.Itoa(len(p.buffers))
+
+	case "name":
+		r := p.reader
+		if p.isShowingHelp {
+			r = p.helpReader
+		}
+		if r.Name == nil || *r.Name == "" {
+			return ""
+		}
+		return filepath.Base(*r.Name)
+
+	case "first":
+		first, _ := p.visibleLineNumbers()
+		if first == nil {
+			return ""
+		}
+		return first.Format()
+
+	case "last":
+		_, last := p.visibleLineNumbers()
+		if last == nil {
+			return ""
+		}
+		return last.Format()
+
+	case "total":
+		r := p.reader
+		if p.isShowingHelp {
+			r = p.helpReader
+		}
+		if !r.ShouldShowLineCount() {
+			return ""
+		}
+		return util.FormatInt(r.GetLineCount())
+
+	case "percent":
+		lineCount := p.Reader().GetLineCount()
+		renderedLines, _ := p.renderLines()
+		if lineCount == 0 || len(renderedLines) == 0 {
+			return ""
+		}
+		lastIndex := renderedLines[len(renderedLines)-1].inputLineIndex
+		return fmt.Sprintf("%.0f%%", math.Floor(100*float64(lastIndex.Index()+1)/float64(lineCount)))
+
+	case "column":
+		return strconv.Itoa(p.leftColumnZeroBased + 1)
+
+	case "offset":
+		first, _ := p.visibleLineNumbers()
+		if first == nil || p.isShowingHelp {
+			return ""
+		}
+		offset := p.reader.ByteOffset(linemetadata.IndexFromZeroBased(first.AsZeroBased()))
+		return strconv.FormatInt(offset, 10)
+
+	case "encoding":
+		// We decode all input as UTF-8, see the man page
+		return "UTF-8"
+
+	case "filter":
+		if p.filterPattern == nil {
+			return ""
+		}
+
+		// While filtering, the search string is the filter string
+		return p.searchString
+
+	case "filtered":
+		if !p.isFiltering() {
+			return ""
+		}
+		return util.FormatInt(p.filteringReader.GetLineCount())
+
+	case "search":
+		return p.searchString
+
+	case "matches":
+		hitCount := p.searchHitCount()
+		if hitCount == nil {
+			return ""
+		}
+		return util.FormatInt(*hitCount)
+
+	case "follow":
+		if p.isFollowing() {
+			return "Following"
+		}
+		return ""
+
+	case "diff-file":
+		return p.currentDiffFileName()
+
+	case "clock":
+		return time.Now().Format("15:04")
+	}
+
+	panic(fmt.Errorf("Unknown status format field: %s", name))
+}
+
+// Renders the parts, or returns false if this is a group with an empty field
+func (v *statusFormatValues) render(parts []statusFormatPart, isGroup bool) (string, bool) {
+	rendered := strings.Builder{}
+	for _, part := range parts {
+		switch {
+		case part.field != "":
+			value := v.get(part.field)
+			if value == "" && isGroup {
+				return "", false
+			}
+			rendered.WriteString(value)
+
+		case part.group != nil:
+			group, ok := v.render(part.group, true)
+			if ok {
+				rendered.WriteString(group)
+			}
+
+		default:
+			rendered.WriteString(part.literal)
+		}
+	}
+
+	return rendered.String(), true
+}
+
+// Render this status bar template to fit on a screen of the given width
+func (format *StatusFormat) render(values *statusFormatValues, width int) string {
+	values.computed = map[string]string{}
+	left, _ := values.render(format.left, false)
+	if len(format.right) == 0 {
+		return left
+	}
+
+	right, _ := values.render(format.right, false)
+	padding := width - stringWidth(left) - stringWidth(right)
+	if padding < 1 {
+		// Not enough room, put at least one space between left and right
+		padding = 1
+	}
+	return left + strings.Repeat(" ", padding) + right
+}
+
+// How many screen columns this string needs
+func stringWidth(s string) int {
+	width := 0
+	for _, char := range s {
+		width += twin.NewStyledRune(char, twin.StyleDefault).Width()
+	}
+	return width
+}
+
+// Which status bar template to use right now
+func (p *Pager) currentStatusFormat() *StatusFormat {
+	format := p.StatusFormat
+	if p.isFiltering() && p.StatusFormatFiltering != nil {
+		format = p.StatusFormatFiltering
+	} else if p.isFollowing() && p.StatusFormatFollowing != nil {
+		format = p.StatusFormatFollowing
+	}
+
+	if format == nil {
+		var err error
+		format, err = ParseStatusFormat(defaultStatusFormatString)
+		if err != nil {
+			panic(err)
+		}
+	}
+
+	return format
+}
+
+// Render the current status bar template
+func (p *Pager) formatStatus(statusText string, spinner string, hints string) string {
+	width, _ := p.screen.Size()
+	values := statusFormatValues{
+		pager:      p,
+		statusText: statusText,
+		spinner:    spinner,
+		hints:      hints,
+	}
+	return p.currentStatusFormat().render(&values, width)
+}
+
+// Whether any of our status bar templates shows the time of day
+func (p *Pager) statusFormatHasClock() bool {
+	for _, format := range []*StatusFormat{p.StatusFormat, p.StatusFormatFiltering, p.StatusFormatFollowing} {
+		if format != nil && format.hasClock {
+			return true
+		}
+	}
+	return false
+}
+
+// True if we're tailing the input
+func (p *Pager) isFollowing() bool {
+	return p.TargetLine != nil && *p.TargetLine == linemetadata.IndexMax()
+}
+
+// Line numbers of the first and last lines on screen, nil if there are none
+func (p *Pager) visibleLineNumbers() (*linemetadata.Number, *linemetadata.Number) {
+	renderedLines, _ := p.renderLines()
+	if len(renderedLines) == 0 {
+		return nil, nil
+	}
+
+	first := p.Reader().GetLine(renderedLines[0].inputLineIndex)
+	last := p.Reader().GetLine(renderedLines[len(renderedLines)-1].inputLineIndex)
+	if first == nil || last == nil {
+		return nil, nil
+	}
+
+	return &first.Number, &last.Number
+}
+
+// The minute changed, time to update the status bar clock
+type eventClockTick struct{}
+
+// Sends an eventClockTick at the start of every minute
+func tickClock(events chan<- twin.Event) {
+	defer func() {
+		PanicHandler("tickClock()", recover(), debug.Stack())
+	}()
+
+	for {
+		now := time.Now()
+		time.Sleep(now.Truncate(time.Minute).Add(time.Minute).Sub(now))
+		events <- eventClockTick{}
+	}
+}
@@ -0,0 +1,105 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/walles/moor/internal/linemetadata"
+	"github.com/walles/moor/internal/reader"
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// 100 lines, "1" to "100", 9 lines visible
+func newStatusFormatTestPager(t *testing.T) *Pager {
+	pager, _ := newTestPager(t, reader.NewFromTextForTesting("numbers.txt", numberLines(100)), 40, 10)
+	return pager
+}
+
+func statusBarWithFormat(t *testing.T, pager *Pager, format string) string {
+	parsed, err := ParseStatusFormat(format)
+	assert.NilError(t, err)
+	pager.StatusFormat = parsed
+
+	pager.redraw("")
+	_, height := pager.screen.Size()
+	return rowToString(pager.screen.(*twin.FakeScreen).GetRow(height - 1))
+}
+
+func TestStatusFormatFields(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+	pager.scrollPosition = NewScrollPositionFromIndex(linemetadata.IndexFromZeroBased(10), "test")
+
+	assert.Equal(t,
+		statusBarWithFormat(t, pager, "{name} {first}-{last}/{total} {percent}"),
+		"numbers.txt 11-19/100 19%")
+	assert.Equal(t, statusBarWithFormat(t, pager, "{column} {encoding}"), "1 UTF-8")
+
+	// "1\n" to "9\n" is 18 bytes, "10\n" is 3 bytes
+	assert.Equal(t, statusBarWithFormat(t, pager, "Offset {offset}"), "Offset 21")
+}
+
+func TestStatusFormatDefault(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+	pager.screen = twin.NewFakeScreen(100, 10)
+	pager.redraw("")
+
+	assert.Equal(t,
+		rowToString(pager.screen.(*twin.FakeScreen).GetRow(9)),
+		"numbers.txt: 100 lines  9%  "+footerHints(pager.Keymap, false))
+}
+
+func TestStatusFormatOptionalParts(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+
+	assert.Equal(t, statusBarWithFormat(t, pager, "[Search: {search} ]{total}"), "100")
+
+	pager.searchString = "5"
+	pager.searchPattern = toPattern("5")
+	assert.Equal(t,
+		statusBarWithFormat(t, pager, "[Search: {search} ][({matches} hits)]"),
+		"Search: 5 (19 hits)")
+}
+
+func TestStatusFormatRightAligned(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+
+	statusBar := statusBarWithFormat(t, pager, "{name}{>}{first}-{last}")
+	assert.Equal(t, statusBar, "numbers.txt"+strings.Repeat(" ", 40-11-3)+"1-9")
+}
+
+func TestStatusFormatFiltering(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+	filtering, err := ParseStatusFormat("Filter: {filter} {filtered}/{total}")
+	assert.NilError(t, err)
+	pager.StatusFormatFiltering = filtering
+
+	assert.Equal(t, statusBarWithFormat(t, pager, "{name}"), "numbers.txt")
+
+	pager.searchString = "5"
+	pager.filterPattern = toPattern("5")
+	assert.Equal(t, statusBarWithFormat(t, pager, "{name}"), "Filter: 5 19/100")
+}
+
+func TestStatusFormatFollowing(t *testing.T) {
+	pager := newStatusFormatTestPager(t)
+	following, err := ParseStatusFormat("[{follow}]")
+	assert.NilError(t, err)
+	pager.StatusFormatFollowing = following
+
+	reallyHigh := linemetadata.IndexMax()
+	pager.setTargetLine(&reallyHigh)
+	assert.Equal(t, statusBarWithFormat(t, pager, "{name}"), "Following")
+}
+
+func TestParseStatusFormatErrors(t *testing.T) {
+	for _, format := range []string{"{nope}", "{name", "name}", "[{name}", "{name}]", "\\"} {
+		_, err := ParseStatusFormat(format)
+		assert.Assert(t, err != nil, "Format should have failed to parse: %s", format)
+	}
+
+	parsed, err := ParseStatusFormat("\\{name\\} \\[x\\] \\\\")
+	assert.NilError(t, err)
+	assert.Equal(t, len(parsed.left), 1)
+	assert.Equal(t, parsed.left[0].literal, "{name} [x] \\")
+}
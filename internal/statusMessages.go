@@ -0,0 +1,108 @@
+package internal
+
+import (
+	"time"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/walles/moor/twin"
+)
+
+// How long status messages are shown in the footer
+const statusMessageDuration = 4 * time.Second
+
+type statusMessageLevel int
+
+const (
+	statusMessageInfo statusMessageLevel = iota
+	statusMessageWarning
+	statusMessageError
+)
+
+// A message shown in the footer for a few seconds. It stays even if the user
+// keeps typing.
+type statusMessage struct {
+	level   statusMessageLevel
+	text    string
+	expires time.Time
+}
+
+// Show a status message from some other goroutine than the main loop
+type eventStatusMessage struct {
+	level statusMessageLevel
+	text  string
+}
+
+// A status message has expired, time to redraw without it
+type eventStatusMessageExpired struct{}
+
+func (m statusMessage) style() twin.Style {
+	switch m.level {
+	case statusMessageError:
+		return errorStyle
+	case statusMessageWarning:
+		return warningStyle
+	default:
+		return statusbarStyle
+	}
+}
+
+func (p *Pager) showWarning(text string) {
+	log.Warn(text)
+	p.showStatusMessage(statusMessageWarning, text)
+}
+
+func (p *Pager) showError(text string) {
+	log.Error(text)
+	p.showStatusMessage(statusMessageError, text)
+}
+
+// Must be called from the main loop. Other goroutines should send an
+// eventStatusMessage instead.
+func (p *Pager) showStatusMessage(level statusMessageLevel, text string) {
+	p.statusMessage = &statusMessage{
+		level:   level,
+		text:    text,
+		expires: time.Now().Add(statusMessageDuration),
+	}
+
+	if p.screen == nil {
+		return
+	}
+	events := p.screen.Events()
+	if events == nil {
+		// No main loop to wake up, this happens in tests
+		return
+	}
+	p.stopStatusMessageTimer()
+	p.statusMessageTimer = time.AfterFunc(statusMessageDuration, func() {
+		select {
+		case events <- eventStatusMessageExpired{}:
+		default:
+			// The event queue is full. The main loop will redraw without the
+			// expired message once it has caught up anyway.
+		}
+	})
+}
+
+func (p *Pager) stopStatusMessageTimer() {
+	if p.statusMessageTimer == nil {
+		return
+	}
+
+	p.statusMessageTimer.Stop()
+	p.statusMessageTimer = nil
+}
+
+// The status message to show, or nil if there is none or if it has expired
+func (p *Pager) currentStatusMessage() *statusMessage {
+	if p.statusMessage == nil {
+		return nil
+	}
+
+	if time.Now().After(p.statusMessage.expires) {
+		p.statusMessage = nil
+		return nil
+	}
+
+	return p.statusMessage
+}
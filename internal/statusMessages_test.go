@@ -0,0 +1,117 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+// The text of the current status message, or an empty string if there is none
+func statusText(pager *Pager) string {
+	message := pager.currentStatusMessage()
+	if message == nil {
+		return ""
+	}
+	return message.text
+}
+
+func footerOf(pager *Pager) []twin.StyledRune {
+	screen := pager.screen.(*twin.FakeScreen)
+	_, height := screen.Size()
+	return screen.GetRow(height - 1)
+}
+
+func TestStatusMessage(t *testing.T) {
+	pager := newCountTestPager(t)
+	pager.screen = twin.NewFakeScreen(60, 10)
+
+	pager.showWarning("Something is fishy")
+	pager.redraw("")
+	assert.Equal(t, rowToString(footerOf(pager)), "Something is fishy")
+	assert.Equal(t, footerOf(pager)[0].Style, warningStyle)
+
+	// Status messages stay while the user keeps typing
+	typeRunes(pager, "j")
+	pager.redraw("")
+	assert.Equal(t, rowToString(footerOf(pager)), "Something is fishy")
+
+	// ... until they expire
+	pager.statusMessage.expires = time.Now().Add(-time.Second)
+	pager.redraw("")
+	assert.Assert(t, !strings.Contains(rowToString(footerOf(pager)), "fishy"))
+	assert.Assert(t, pager.statusMessage == nil)
+}
+
+func TestStatusMessageTimerStopsWhenPagingEnds(t *testing.T) {
+	pager := newCountTestPager(t)
+	screen := eventsFakeScreen{FakeScreen: twin.NewFakeScreen(60, 10), events: make(chan twin.Event, 10)}
+	pager.screen = screen
+
+	pager.showWarning("Something is fishy")
+	timer := pager.statusMessageTimer
+	assert.Assert(t, timer != nil)
+
+	pager.Quit()
+	pager.StartPaging(screen, nil, nil)
+	assert.Assert(t, pager.statusMessageTimer == nil)
+	assert.Assert(t, !timer.Stop(), "Timer should have been stopped already")
+}
+
+func TestLatestStatusMessageWins(t *testing.T) {
+	pager := newCountTestPager(t)
+	pager.screen = twin.NewFakeScreen(60, 10)
+
+	pager.showWarning("Just so you know")
+	pager.showError("Oh no")
+	pager.redraw("")
+	assert.Equal(t, rowToString(footerOf(pager)), "Oh no")
+	assert.Equal(t, footerOf(pager)[0].Style, errorStyle)
+}
+
+func TestUnboundKeyMessage(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "Z")
+	assert.Equal(t, pager.statusMessage.text, "'Z' does nothing, press 'h' for help")
+	assert.Equal(t, pager.statusMessage.level, statusMessageInfo)
+}
+
+func TestEditorNotFoundMessage(t *testing.T) {
+	t.Setenv("VISUAL", "")
+	t.Setenv("EDITOR", "this-editor-does-not-exist")
+
+	_, err := findEditor()
+	assert.ErrorContains(t, err, "Editor this-editor-does-not-exist from $EDITOR not found")
+}
+
+func TestShowLog(t *testing.T) {
+	pager := newCountTestPager(t)
+
+	typeRunes(pager, "I")
+	assert.Equal(t, statusText(pager), "Nothing has been logged")
+
+	pager.Logs = &LogWriter{}
+	_, err := pager.Logs.Write([]byte("first\nsecond\n"))
+	assert.NilError(t, err)
+
+	typeRunes(pager, "I")
+	assert.Equal(t, len(pager.buffers), 2)
+	assert.Equal(t, pager.currentBufferIndex, 1)
+	assert.NilError(t, pager.reader.Wait())
+	assert.Equal(t, pager.Reader().GetLineCount(), 2)
+
+	// Showing the log again refreshes the same buffer
+	typeRunes(pager, "]")
+	assert.Equal(t, pager.currentBufferIndex, 0)
+	_, err = pager.Logs.Write([]byte("third\n"))
+	assert.NilError(t, err)
+
+	typeRunes(pager, "I")
+	assert.Equal(t, len(pager.buffers), 2)
+	assert.Equal(t, pager.currentBufferIndex, 1)
+	assert.NilError(t, pager.reader.Wait())
+	assert.Equal(t, pager.Reader().GetLineCount(), 3)
+}
@@ -21,6 +21,12 @@ var statusbarStyle = twin.StyleDefault.WithAttr(twin.AttrReverse)
 
 var plainTextStyle = twin.StyleDefault
 
+// For command errors in the status bar
+var errorStyle = twin.StyleDefault.WithForeground(twin.NewColor16(1)).WithAttr(twin.AttrReverse)
+
+// For warnings in the status bar
+var warningStyle = twin.StyleDefault.WithForeground(twin.NewColor16(3)).WithAttr(twin.AttrReverse)
+
 func setStyle(updateMe *twin.Style, envVarName string, fallback *twin.Style) {
 	envValue := os.Getenv(envVarName)
 	if envValue == "" {
@@ -185,6 +185,11 @@ func StyledRunesFromString(plainTextStyle twin.Style, s string, lineIndex *linem
 		}
 	})
 
+	if strings.Contains(s, "://") && !strings.Contains(s, "\x1b]8;") {
+		// Lines with OSC 8 hyperlinks already say what should be linked
+		autoLinkURLs(cells)
+	}
+
 	return StyledRunesWithTrailer{
 		StyledRunes: cells,
 		Trailer:     trailer,
@@ -0,0 +1,122 @@
+package textstyles
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/walles/moor/twin"
+)
+
+// Plain text URLs, trailing punctuation is trimmed off by trimURL()
+var urlRegexp = regexp.MustCompile(`\b(https?|ftp|file)://[^\s<>"'` + "`" + `]+`)
+
+// References like "path/to/file.go:123" or "main.rs:12:5" from compiler output
+// and stack traces. The file name must have an extension, otherwise we would
+// match all sorts of timestamps and "host:port" strings.
+//
+// Submatches are the file name, the line number and the optional column.
+var fileReferenceRegexp = regexp.MustCompile(`(?:^|[\s("'\[<])(/?(?:[\w.@~+-]+/)*[\w@+-][\w.@+-]*\.[A-Za-z]\w*):(\d+)(?::(\d+))?`)
+
+// A FileReference is a "file.go:123" style reference to a line in some file.
+// See FileReferences().
+type FileReference struct {
+	Path string
+
+	// One based
+	Line int
+
+	// One based, zero if the reference has no column
+	Column int
+
+	// Rune indices into the plain text string, end exclusive
+	RuneRange [2]int
+}
+
+// Turn plain text URLs into hyperlinks, unless they already are. The cells are
+// updated in place.
+func autoLinkURLs(cells []twin.StyledRune) {
+	runes := make([]rune, 0, len(cells))
+	for _, cell := range cells {
+		runes = append(runes, cell.Rune)
+	}
+	plain := string(runes)
+
+	for _, match := range urlRegexp.FindAllStringIndex(plain, -1) {
+		url := trimURL(plain[match[0]:match[1]])
+		start := utf8.RuneCountInString(plain[:match[0]])
+		end := start + utf8.RuneCountInString(url)
+
+		alreadyLinked := false
+		for _, cell := range cells[start:end] {
+			if cell.Style.HyperlinkURL() != nil {
+				alreadyLinked = true
+				break
+			}
+		}
+		if alreadyLinked {
+			continue
+		}
+
+		for i := start; i < end; i++ {
+			cells[i].Style = cells[i].Style.WithHyperlink(&url)
+		}
+	}
+}
+
+// Drop trailing punctuation that is more likely part of the surrounding text
+// than of the URL, like in "(see https://example.com/)."
+func trimURL(url string) string {
+	for len(url) > 0 {
+		last := url[len(url)-1]
+		switch {
+		case strings.ContainsRune(".,;:!?", rune(last)):
+			url = url[:len(url)-1]
+		case last == ')' && strings.Count(url, "(") < strings.Count(url, ")"):
+			url = url[:len(url)-1]
+		case last == ']' && strings.Count(url, "[") < strings.Count(url, "]"):
+			url = url[:len(url)-1]
+		default:
+			return url
+		}
+	}
+	return url
+}
+
+// FileReferences finds "file.go:123" and "file.go:123:45" style references in
+// a plain text string, like a line of compiler output or a stack trace.
+func FileReferences(plain string) []FileReference {
+	if !strings.Contains(plain, ":") {
+		// Fast path, no references possible
+		return nil
+	}
+
+	var references []FileReference
+	for _, match := range fileReferenceRegexp.FindAllStringSubmatchIndex(plain, -1) {
+		line, err := strconv.Atoi(plain[match[4]:match[5]])
+		if err != nil || line == 0 {
+			continue
+		}
+
+		column := 0
+		end := match[5]
+		if match[6] >= 0 {
+			column, err = strconv.Atoi(plain[match[6]:match[7]])
+			if err != nil {
+				column = 0
+			}
+			end = match[7]
+		}
+
+		start := utf8.RuneCountInString(plain[:match[2]])
+		references = append(references, FileReference{
+			Path:      plain[match[2]:match[3]],
+			Line:      line,
+			Column:    column,
+			RuneRange: [2]int{start, start + utf8.RuneCountInString(plain[match[2]:end])},
+		})
+	}
+
+	return references
+}
@@ -0,0 +1,56 @@
+package textstyles
+
+import (
+	"testing"
+
+	"github.com/walles/moor/twin"
+	"gotest.tools/v3/assert"
+)
+
+func TestAutoLinkURLs(t *testing.T) {
+	cells := StyledRunesFromString(twin.StyleDefault, "See https://example.com/a_(b)).", nil).StyledRunes
+
+	assert.Assert(t, cells[3].Style.HyperlinkURL() == nil)
+	assert.Equal(t, *cells[4].Style.HyperlinkURL(), "https://example.com/a_(b)")
+	assert.Equal(t, *cells[28].Style.HyperlinkURL(), "https://example.com/a_(b)")
+	assert.Assert(t, cells[29].Style.HyperlinkURL() == nil)
+}
+
+func TestAutoLinkURLsKeepsOsc8Links(t *testing.T) {
+	cells := StyledRunesFromString(twin.StyleDefault,
+		"\x1b]8;;https://moor.dev\x1b\\https://example.com\x1b]8;;\x1b\\", nil).StyledRunes
+
+	assert.Equal(t, *cells[0].Style.HyperlinkURL(), "https://moor.dev")
+}
+
+func TestTrimURL(t *testing.T) {
+	assert.Equal(t, trimURL("https://example.com/."), "https://example.com/")
+	assert.Equal(t, trimURL("https://example.com/)."), "https://example.com/")
+	assert.Equal(t, trimURL("https://example.com/(x)"), "https://example.com/(x)")
+	assert.Equal(t, trimURL("https://example.com/?q=1"), "https://example.com/?q=1")
+}
+
+func TestFileReferences(t *testing.T) {
+	assert.DeepEqual(t, FileReferences("internal/pager.go:123: oops"), []FileReference{
+		{Path: "internal/pager.go", Line: 123, RuneRange: [2]int{0, 21}},
+	})
+
+	// Go stack trace
+	assert.DeepEqual(t, FileReferences("\t/usr/lib/go/src/runtime/panic.go:770 +0x132"), []FileReference{
+		{Path: "/usr/lib/go/src/runtime/panic.go", Line: 770, RuneRange: [2]int{1, 37}},
+	})
+
+	// Rust compiler output
+	assert.DeepEqual(t, FileReferences("  --> src/main.rs:2:5"), []FileReference{
+		{Path: "src/main.rs", Line: 2, Column: 5, RuneRange: [2]int{6, 21}},
+	})
+
+	// Two on the same line
+	assert.Equal(t, len(FileReferences("a.c:1 b.c:2")), 2)
+
+	// Not file references
+	assert.Equal(t, len(FileReferences("12:34:56")), 0)
+	assert.Equal(t, len(FileReferences("localhost:8080")), 0)
+	assert.Equal(t, len(FileReferences("https://example.com:8080/")), 0)
+	assert.Equal(t, len(FileReferences("file.go:0")), 0)
+}
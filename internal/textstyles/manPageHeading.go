@@ -27,6 +27,12 @@ func manPageHeadingFromString(s string) *StyledRunesWithTrailer {
 	}
 }
 
+// IsManPageHeading returns true if the (formatted) string is a man page section
+// heading, like "DESCRIPTION"
+func IsManPageHeading(s string) bool {
+	return parseManPageHeading(s, func(_ twin.StyledRune) {})
+}
+
 // Reports back one cell at a time. Returns true if the entire string was a man
 // page heading.
 //
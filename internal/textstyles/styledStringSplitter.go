@@ -243,12 +243,39 @@ func (s *styledStringSplitter) consumeOsc() error {
 	}
 }
 
+const promptMarkerPrefix = "133;"
+
+// PromptMarkers returns the letters of the OSC 133 semantic prompt markers in
+// a (formatted) string, in order. "A" marks the start of a prompt, "B" the
+// start of the command line, "C" the start of the command output and "D" the
+// end of the command.
+//
+// Ref:
+// https://gitlab.freedesktop.org/Per_Bothner/specifications/blob/master/proposals/semantic-prompts.md
+func PromptMarkers(s string) string {
+	const marker = "\x1b]" + promptMarkerPrefix
+
+	markers := ""
+	for {
+		index := strings.Index(s, marker)
+		if index < 0 {
+			return markers
+		}
+
+		s = s[index+len(marker):]
+		if len(s) > 0 {
+			markers += s[0:1]
+		}
+	}
+}
+
 // Expects an OSC sequence as argument. The terminator is not included, what we
 // get here is just the payload.
 func (s *styledStringSplitter) handleOsc(sequence string) error {
-	if strings.HasPrefix(sequence, "133;") && len(sequence) == len("133;A") {
-		// Got ESC]133;X, where "X" could be anything. These are prompt hints,
-		// and rendering those makes no sense. We should just ignore them:
+	if strings.HasPrefix(sequence, promptMarkerPrefix) && len(sequence) >= len("133;A") {
+		// Got ESC]133;X, where "X" could be anything, possibly followed by
+		// parameters. These are prompt hints, and rendering those makes no
+		// sense. They are picked up by PromptMarkers() instead:
 		// https://gitlab.freedesktop.org/Per_Bothner/specifications/blob/master/proposals/semantic-prompts.md
 		return nil
 	}
@@ -54,6 +54,16 @@ func TestIgnorePromptHints(t *testing.T) {
 	assert.Equal(t, 1, len(styledStrings))
 	assert.Equal(t, "assertion", styledStrings[0].String)
 	assert.Equal(t, twin.StyleDefault, styledStrings[0].Style)
+
+	// With an exit code parameter
+	styledStrings, _ = collectStyledStrings("\x1b]133;D;1\x07done")
+	assert.Equal(t, 1, len(styledStrings))
+	assert.Equal(t, "done", styledStrings[0].String)
+}
+
+func TestPromptMarkers(t *testing.T) {
+	assert.Equal(t, PromptMarkers("plain"), "")
+	assert.Equal(t, PromptMarkers("\x1b]133;D;0\x07\x1b]133;A\x07$ \x1b]133;B\x07ls"), "DAB")
 }
 
 // We should ignore OSC queries. They are any sequence ending with a question
@@ -0,0 +1,48 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// Returns the directory where we should store state between runs. Follows the
+// XDG Base Directory Specification, with $HOME/.local/state as the fallback.
+//
+// Ref: https://specifications.freedesktop.org/basedir-spec/latest/
+func xdgStateHome() (string, error) {
+	return xdgDir("XDG_STATE_HOME", filepath.Join(".local", "state"))
+}
+
+// Returns the directory where we should look for user configuration. Follows
+// the XDG Base Directory Specification, with $HOME/.config as the fallback.
+//
+// Ref: https://specifications.freedesktop.org/basedir-spec/latest/
+func xdgConfigHome() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// ConfigDir returns the directory where moor's configuration files live, like
+// ~/.config/moor
+func ConfigDir() (string, error) {
+	configHome, err := xdgConfigHome()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(configHome, "moor"), nil
+}
+
+func xdgDir(envVarName string, fallbackInHome string) (string, error) {
+	dir := os.Getenv(envVarName)
+	if filepath.IsAbs(dir) {
+		// The spec says relative paths should be ignored
+		return dir, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, fallbackInHome), nil
+}
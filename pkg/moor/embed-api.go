@@ -56,7 +56,7 @@ func PageFromStream(reader io.Reader, options Options) error {
 		return err
 	}
 
-	return pageFromReader(pagerReader, options)
+	return pageFromReader(pagerReader, options, logs)
 }
 
 // If stdout is not a terminal, the file contents will just be printed to
@@ -87,7 +87,7 @@ func PageFromFile(name string, options Options) error {
 		pagerReader.Name = &options.Title
 	}
 
-	return pageFromReader(pagerReader, options)
+	return pageFromReader(pagerReader, options, logs)
 }
 
 // If stdout is not a terminal, the string contents will just be printed to
@@ -139,8 +139,9 @@ func getColorFormatter() chroma.Formatter {
 	return formatters.TTY16m
 }
 
-func pageFromReader(reader *internalReader.ReaderImpl, options Options) error {
+func pageFromReader(reader *internalReader.ReaderImpl, options Options, logs *internal.LogWriter) error {
 	pager := internal.NewPager(reader)
+	pager.Logs = logs
 	pager.WrapLongLines = options.WrapLongLines
 
 	screen, e := twin.NewScreen()
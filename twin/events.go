@@ -24,10 +24,41 @@ const (
 	MouseWheelDown
 	MouseWheelLeft
 	MouseWheelRight
+
+	MouseButtonLeft
+	MouseButtonMiddle
+	MouseButtonRight
+)
+
+// What happened to the button(s) of a mouse event. Wheel events are always
+// presses.
+type MouseAction int
+
+const (
+	MousePress MouseAction = iota
+	MouseRelease
+
+	// The mouse moved while the button was held down
+	MouseDrag
+)
+
+// Keyboard modifiers held down during a mouse event
+type ModifierMask uint8
+
+const (
+	ModifierShift ModifierMask = 1 << iota
+	ModifierAlt
+	ModifierCtrl
 )
 
 type EventMouse struct {
-	buttons MouseButtonMask
+	buttons   MouseButtonMask
+	action    MouseAction
+	modifiers ModifierMask
+
+	// Zero based screen position
+	column int
+	row    int
 }
 
 // After you get this, query Screen.Size() to get the new size
@@ -51,6 +82,30 @@ func (eventKeyCode *EventKeyCode) KeyCode() KeyCode {
 	return eventKeyCode.keyCode
 }
 
+// Column and row are zero based
+func NewEventMouse(buttons MouseButtonMask, action MouseAction, modifiers ModifierMask, column int, row int) EventMouse {
+	return EventMouse{
+		buttons:   buttons,
+		action:    action,
+		modifiers: modifiers,
+		column:    column,
+		row:       row,
+	}
+}
+
 func (eventMouse *EventMouse) Buttons() MouseButtonMask {
 	return eventMouse.buttons
 }
+
+func (eventMouse *EventMouse) Action() MouseAction {
+	return eventMouse.action
+}
+
+func (eventMouse *EventMouse) Modifiers() ModifierMask {
+	return eventMouse.modifiers
+}
+
+// Zero based screen position of the mouse pointer
+func (eventMouse *EventMouse) Position() (column int, row int) {
+	return eventMouse.column, eventMouse.row
+}
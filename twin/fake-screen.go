@@ -8,6 +8,8 @@ type FakeScreen struct {
 	width  int
 	height int
 	cells  [][]StyledRune
+
+	clipboard string
 }
 
 func NewFakeScreen(width int, height int) *FakeScreen {
@@ -81,10 +83,27 @@ func (screen *FakeScreen) Size() (width int, height int) {
 	return screen.width, screen.height
 }
 
+func (screen *FakeScreen) Suspend() {
+	// This method intentionally left blank
+}
+
+func (screen *FakeScreen) Resume() error {
+	return nil
+}
+
 func (screen *FakeScreen) RequestTerminalBackgroundColor() {
 	// This method intentionally left blank
 }
 
+func (screen *FakeScreen) SetClipboard(text string) {
+	screen.clipboard = text
+}
+
+// What was last put on the clipboard using SetClipboard()
+func (screen *FakeScreen) GetClipboard() string {
+	return screen.clipboard
+}
+
 func (screen *FakeScreen) ShowCursorAt(_ int, _ int) {
 	// This method intentionally left blank
 }
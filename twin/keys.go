@@ -23,6 +23,11 @@ const (
 	KeyEnd
 	KeyPgUp
 	KeyPgDown
+
+	KeyAltC
+	KeyAltR
+	KeyAltW
+	KeyAltD
 )
 
 // Map incoming escape keystrokes to keycodes, used in consumeEncodedEvent() in
@@ -68,4 +73,10 @@ var escapeSequenceToKeyCode = map[string]KeyCode{
 	"\x1b[4~": KeyEnd,
 	"\x1b[5~": KeyPgUp,
 	"\x1b[6~": KeyPgDown,
+
+	// Alt + letter, as sent by terminals with "Meta sends Escape" enabled
+	"\x1bc": KeyAltC,
+	"\x1br": KeyAltR,
+	"\x1bw": KeyAltW,
+	"\x1bd": KeyAltD,
 }
@@ -155,6 +155,16 @@ func (screen *UnixScreen) setupTtyInTtyOut() error {
 	return nil
 }
 
+// Set up the TTY again after restoreTtyInTtyOut()
+func (screen *UnixScreen) resumeTtyInTtyOut() error {
+	err := screen.ttyIn.Close()
+	if err != nil {
+		log.Debug("Failed to close CONIN$ before reopening it: ", err)
+	}
+
+	return screen.setupTtyInTtyOut()
+}
+
 func (screen *UnixScreen) restoreTtyInTtyOut() error {
 	errors := []error{}
 
@@ -178,6 +178,13 @@ func (screen *UnixScreen) setupTtyInTtyOut() error {
 	return nil
 }
 
+// Set up the TTY again after restoreTtyInTtyOut()
+func (screen *UnixScreen) resumeTtyInTtyOut() error {
+	var err error
+	screen.oldTerminalState, err = term.MakeRaw(int(screen.ttyIn.Fd()))
+	return err
+}
+
 func (screen *UnixScreen) restoreTtyInTtyOut() error {
 	return term.Restore(int(screen.ttyIn.Fd()), screen.oldTerminalState)
 }
@@ -2,12 +2,14 @@
 package twin
 
 import (
+	"encoding/base64"
 	"fmt"
 	"os"
 	"regexp"
 	"runtime/debug"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"unicode/utf8"
 
 	log "github.com/sirupsen/logrus"
@@ -76,6 +78,31 @@ type Screen interface {
 	Events() chan Event
 }
 
+// A Screen that can put text on the system clipboard. Use a type assertion to
+// find out whether a Screen can do this.
+type ClipboardScreen interface {
+	Screen
+
+	// SetClipboard() asks the terminal to put some text on the system
+	// clipboard, using the OSC 52 escape sequence. This works over SSH, but
+	// whether or not it actually works is up to the terminal.
+	SetClipboard(text string)
+}
+
+// A Screen that can give the terminal to some other program for a while. Use a
+// type assertion to find out whether a Screen can do this.
+type SuspendableScreen interface {
+	Screen
+
+	// Suspend() gives the terminal back, so that some other program, like an
+	// editor, can run in it. Call Resume() to take the terminal back.
+	Suspend()
+
+	// Resume() takes the terminal back after Suspend(). An EventResize is
+	// posted to have the screen redrawn.
+	Resume() error
+}
+
 type interruptableReader interface {
 	Read(p []byte) (n int, err error)
 
@@ -96,6 +123,14 @@ type UnixScreen struct {
 
 	ttyInReader interruptableReader
 
+	// Closed when mainLoop() returns
+	mainLoopDone chan struct{}
+
+	// Makes mainLoop() return quietly when its reader is interrupted
+	suspended atomic.Bool
+
+	mouseTracking bool
+
 	ttyIn            *os.File
 	oldTerminalState *term.State //nolint Not used on Windows
 	oldTtyInMode     uint32      //nolint Windows only
@@ -110,11 +145,13 @@ type UnixScreen struct {
 //
 // Where:
 //   - "\x1b[<" says this is a mouse event
-//   - "65" says this is Wheel Up. "64" would be Wheel Down.
+//   - "65" says this is Wheel Down. "64" would be Wheel Up. See
+//     parseMouseEvent() for details.
 //   - "127" is the column number on screen, "1" is the first column.
 //   - "41" is the row number on screen, "1" is the first row.
-//   - "M" marks the end of the mouse event.
-var mouseEventRegex = regexp.MustCompile("^\x1b\\[<([0-9]+);([0-9]+);([0-9]+)M")
+//   - "M" marks the end of the mouse event. "m" would mean that a button was
+//     released.
+var mouseEventRegex = regexp.MustCompile("^\x1b\\[<([0-9]+);([0-9]+);([0-9]+)([Mm])")
 
 // NewScreen() requires Close() to be called after you are done with your new
 // screen, most likely somewhere in your shutdown code.
@@ -170,26 +207,74 @@ func NewScreenWithMouseModeAndColorCount(mouseMode MouseMode, terminalColorCount
 	screen.setAlternateScreenMode(true)
 
 	if mouseMode == MouseModeAuto {
-		screen.enableMouseTracking(!terminalHasArrowKeysEmulation())
+		screen.mouseTracking = !terminalHasArrowKeysEmulation()
 	} else if mouseMode == MouseModeSelect {
-		screen.enableMouseTracking(false)
+		screen.mouseTracking = false
 	} else if mouseMode == MouseModeScroll {
-		screen.enableMouseTracking(true)
+		screen.mouseTracking = true
 	} else {
 		panic(fmt.Errorf("unknown mouse mode: %d", mouseMode))
 	}
+	screen.enableMouseTracking(screen.mouseTracking)
 
 	screen.hideCursor(true)
 
+	screen.startMainLoop(true)
+
+	return &screen, nil
+}
+
+func (screen *UnixScreen) startMainLoop(expectingTerminalBackgroundColor bool) {
+	screen.mainLoopDone = make(chan struct{})
 	go func() {
 		defer func() {
-			panicHandler("NewScreenWithMouseModeAndColorCount()/mainLoop()", recover(), debug.Stack())
+			panicHandler("UnixScreen.startMainLoop()/mainLoop()", recover(), debug.Stack())
 		}()
+		defer close(screen.mainLoopDone)
 
-		screen.mainLoop()
+		screen.mainLoop(expectingTerminalBackgroundColor)
 	}()
+}
 
-	return &screen, nil
+// Suspend() gives the terminal back, so that some other program can run in it.
+func (screen *UnixScreen) Suspend() {
+	screen.suspended.Store(true)
+	screen.ttyInReader.Interrupt()
+	<-screen.mainLoopDone
+
+	screen.hideCursor(false)
+	screen.enableMouseTracking(false)
+	screen.setAlternateScreenMode(false)
+
+	err := screen.restoreTtyInTtyOut()
+	if err != nil {
+		log.Info("Problem restoring TTY state when suspending: ", err)
+	}
+}
+
+// Resume() takes the terminal back after Suspend()
+func (screen *UnixScreen) Resume() error {
+	err := screen.resumeTtyInTtyOut()
+	if err != nil {
+		return fmt.Errorf("problem setting up TTY: %w", err)
+	}
+	screen.ttyInReader, err = newInterruptableReader(screen.ttyIn)
+	if err != nil {
+		return fmt.Errorf("problem setting up TTY reader: %w", err)
+	}
+
+	screen.setAlternateScreenMode(true)
+	screen.enableMouseTracking(screen.mouseTracking)
+	screen.hideCursor(true)
+
+	screen.suspended.Store(false)
+	screen.startMainLoop(false)
+
+	// The window may have been resized while we were away, and either way
+	// the screen needs redrawing
+	screen.onWindowResized()
+
+	return nil
 }
 
 // Close() restores terminal to normal state, must be called after you are done
@@ -378,9 +463,10 @@ func terminalHasArrowKeysEmulation() bool {
 
 func (screen *UnixScreen) enableMouseTracking(enable bool) {
 	if enable {
-		screen.write("\x1b[?1006;1000h")
+		// 1002 makes us get drag events, as long as a button is held down
+		screen.write("\x1b[?1006;1000;1002h")
 	} else {
-		screen.write("\x1b[?1006;1000l")
+		screen.write("\x1b[?1006;1000;1002l")
 	}
 }
 
@@ -413,7 +499,7 @@ func (screen *UnixScreen) ShowCursorAt(column int, row int) {
 	screen.hideCursor(false)
 }
 
-func (screen *UnixScreen) mainLoop() {
+func (screen *UnixScreen) mainLoop(expectingTerminalBackgroundColor bool) {
 	// "1400" comes from me trying fling scroll operations on my MacBook
 	// trackpad and looking at the high watermark (logged below).
 	//
@@ -424,10 +510,13 @@ func (screen *UnixScreen) mainLoop() {
 	log.Info("Entering Twin main loop...")
 
 	maxBytesRead := 0
-	expectingTerminalBackgroundColor := true
 	var incompleteResponse []byte // To store incomplete terminal background color responses
 	for {
 		count, err := screen.ttyInReader.Read(buffer)
+		if err != nil && screen.suspended.Load() {
+			log.Info("ttyin read interrupted, twin suspending")
+			return
+		}
 		if err != nil {
 			// Ref:
 			// * https://github.com/walles/moor/issues/145
@@ -528,20 +617,19 @@ func consumeEncodedEvent(encodedEventSequences string) (*Event, string) {
 
 	mouseMatch := mouseEventRegex.FindStringSubmatch(encodedEventSequences)
 	if mouseMatch != nil {
-		if mouseMatch[1] == "64" {
-			var event Event = EventMouse{buttons: MouseWheelUp}
-			return &event, strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
-		}
-		if mouseMatch[1] == "65" {
-			var event Event = EventMouse{buttons: MouseWheelDown}
-			return &event, strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
+		remainder := strings.TrimPrefix(encodedEventSequences, mouseMatch[0])
+
+		mouseEvent := parseMouseEvent(mouseMatch[1], mouseMatch[2], mouseMatch[3], mouseMatch[4] == "m")
+		if mouseEvent == nil {
+			log.Debug(
+				"Unhandled mouse escape sequence: {",
+				humanizeLowASCII(mouseMatch[0]),
+				"}")
+			return nil, remainder
 		}
 
-		log.Debug(
-			"Unhandled multi character mouse escape sequence(s): {",
-			humanizeLowASCII(encodedEventSequences),
-			"}")
-		return nil, ""
+		var event Event = *mouseEvent
+		return &event, remainder
 	}
 
 	// No escape sequence prefix matched
@@ -633,6 +721,24 @@ func (screen *UnixScreen) RequestTerminalBackgroundColor() {
 	fmt.Println("\x1b]11;?\x07")
 }
 
+func (screen *UnixScreen) SetClipboard(text string) {
+	screen.write(osc52(text, os.Getenv("TMUX") != ""))
+}
+
+// Ref: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h3-Operating-System-Commands
+func osc52(text string, inTmux bool) string {
+	sequence := "\x1b]52;c;" + base64.StdEncoding.EncodeToString([]byte(text)) + "\x07"
+	if !inTmux {
+		return sequence
+	}
+
+	// Pass the sequence through tmux to the outer terminal. This requires
+	// "set -g allow-passthrough on" in tmux.conf.
+	//
+	// Ref: https://github.com/tmux/tmux/wiki/FAQ#what-is-the-passthrough-escape-sequence-and-how-do-i-use-it
+	return "\x1bPtmux;" + strings.ReplaceAll(sequence, "\x1b", "\x1b\x1b") + "\x1b\\"
+}
+
 func parseTerminalBgColorResponse(responseBytes []byte) (*Color, bool) {
 	prefix := "\x1b]11;rgb:"
 	suffix1 := "\x07"
@@ -867,3 +973,68 @@ func (screen *UnixScreen) showNLines(width int, height int, clearFirst bool) {
 	// Write out what we have
 	screen.write(builder.String())
 }
+
+// Parse the numbers of an SGR mouse event. Returns nil if the event is of a kind
+// we don't handle.
+//
+// The button code bits are:
+//   - 0-1: 0 = left, 1 = middle, 2 = right, 3 = none
+//   - 2: Shift
+//   - 3: Alt / Meta
+//   - 4: Ctrl
+//   - 5: Motion, set when dragging
+//   - 6: Wheel, then bits 0-1 mean 0 = up, 1 = down, 2 = left, 3 = right
+//
+// Ref: https://invisible-island.net/xterm/ctlseqs/ctlseqs.html#h2-Mouse-Tracking
+func parseMouseEvent(buttonCode string, column string, row string, isRelease bool) *EventMouse {
+	code, err := strconv.Atoi(buttonCode)
+	if err != nil {
+		return nil
+	}
+	oneBasedColumn, err := strconv.Atoi(column)
+	if err != nil || oneBasedColumn < 1 {
+		return nil
+	}
+	oneBasedRow, err := strconv.Atoi(row)
+	if err != nil || oneBasedRow < 1 {
+		return nil
+	}
+
+	var modifiers ModifierMask
+	if code&4 != 0 {
+		modifiers |= ModifierShift
+	}
+	if code&8 != 0 {
+		modifiers |= ModifierAlt
+	}
+	if code&16 != 0 {
+		modifiers |= ModifierCtrl
+	}
+
+	action := MousePress
+	if isRelease {
+		action = MouseRelease
+	} else if code&32 != 0 {
+		action = MouseDrag
+	}
+
+	var buttons MouseButtonMask
+	if code&64 != 0 {
+		buttons = [...]MouseButtonMask{MouseWheelUp, MouseWheelDown, MouseWheelLeft, MouseWheelRight}[code&3]
+	} else {
+		switch code & 3 {
+		case 0:
+			buttons = MouseButtonLeft
+		case 1:
+			buttons = MouseButtonMiddle
+		case 2:
+			buttons = MouseButtonRight
+		default:
+			// Movement without any button held down, we don't ask for those
+			return nil
+		}
+	}
+
+	event := NewEventMouse(buttons, action, modifiers, oneBasedColumn-1, oneBasedRow-1)
+	return &event
+}
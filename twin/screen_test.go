@@ -32,8 +32,10 @@ func TestConsumeEncodedEvent(t *testing.T) {
 	// Implicitly test having a remaining rune at the end
 	assertEncode(t, "\x1b[Ax", EventKeyCode{keyCode: KeyUp}, "x")
 
-	assertEncode(t, "\x1b[<64;127;41M", EventMouse{buttons: MouseWheelUp}, "")
-	assertEncode(t, "\x1b[<65;127;41M", EventMouse{buttons: MouseWheelDown}, "")
+	assertEncode(t, "\x1br", EventKeyCode{keyCode: KeyAltR}, "")
+
+	assertEncode(t, "\x1b[<64;127;41M", EventMouse{buttons: MouseWheelUp, column: 126, row: 40}, "")
+	assertEncode(t, "\x1b[<65;127;41M", EventMouse{buttons: MouseWheelDown, column: 126, row: 40}, "")
 
 	// This happens when users paste.
 	//
@@ -41,6 +43,31 @@ func TestConsumeEncodedEvent(t *testing.T) {
 	assertEncode(t, "1234", EventRune{rune: '1'}, "234")
 }
 
+func TestConsumeEncodedMouseEvents(t *testing.T) {
+	assertEncode(t, "\x1b[<66;1;1M", EventMouse{buttons: MouseWheelLeft}, "")
+	assertEncode(t, "\x1b[<67;1;1M", EventMouse{buttons: MouseWheelRight}, "")
+
+	assertEncode(t, "\x1b[<0;5;7M", NewEventMouse(MouseButtonLeft, MousePress, 0, 4, 6), "")
+	assertEncode(t, "\x1b[<0;5;7m", NewEventMouse(MouseButtonLeft, MouseRelease, 0, 4, 6), "")
+	assertEncode(t, "\x1b[<32;5;8M", NewEventMouse(MouseButtonLeft, MouseDrag, 0, 4, 7), "")
+	assertEncode(t, "\x1b[<1;2;3M", NewEventMouse(MouseButtonMiddle, MousePress, 0, 1, 2), "")
+	assertEncode(t, "\x1b[<2;2;3M", NewEventMouse(MouseButtonRight, MousePress, 0, 1, 2), "")
+
+	// Shift and Ctrl held down
+	assertEncode(t, "\x1b[<20;2;3M", NewEventMouse(MouseButtonLeft, MousePress, ModifierShift|ModifierCtrl, 1, 2), "")
+	assertEncode(t, "\x1b[<8;2;3M", NewEventMouse(MouseButtonLeft, MousePress, ModifierAlt, 1, 2), "")
+
+	// Two events in one go
+	assertEncode(t, "\x1b[<0;1;1M\x1b[<0;1;1m", NewEventMouse(MouseButtonLeft, MousePress, 0, 0, 0), "\x1b[<0;1;1m")
+}
+
+func TestConsumeEncodedUnhandledMouseEvent(t *testing.T) {
+	// Motion without any button held down
+	event, remainder := consumeEncodedEvent("\x1b[<35;1;1Mx")
+	assert.Assert(t, event == nil)
+	assert.Equal(t, remainder, "x")
+}
+
 func TestConsumeEncodedEventWithUnsupportedEscapeCode(t *testing.T) {
 	event, remainder := consumeEncodedEvent("\x1bXXXXX")
 	assert.Assert(t, event == nil)
@@ -384,3 +411,8 @@ func TestInterruptableReader_justRead(t *testing.T) {
 	assert.Equal(t, buffer[0], byte(42))
 	assert.Equal(t, len(buffer), 7)
 }
+
+func TestOsc52(t *testing.T) {
+	assert.Equal(t, osc52("hej", false), "\x1b]52;c;aGVq\x07")
+	assert.Equal(t, osc52("hej", true), "\x1bPtmux;\x1b\x1b]52;c;aGVq\x07\x1b\\")
+}